@@ -11,7 +11,7 @@ go mod tidy
 
 # Build the application
 echo "Building the application..."
-go build -o messaging-service cmd/server/main.go
+go build -o messaging-service ./cmd/server
 
 # Start the application
 echo "Starting messaging service..."
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"messaging-service/internal/app"
+	"messaging-service/internal/config"
+)
+
+// runBackfillRoles handles the backfill-roles subcommand: backfill-roles [--dry-run]
+func runBackfillRoles(cfg *config.Config, args []string) error {
+	dryRun := false
+	for _, arg := range args {
+		switch arg {
+		case "--dry-run":
+			dryRun = true
+		default:
+			return fmt.Errorf("usage: backfill-roles [--dry-run]")
+		}
+	}
+
+	return app.NewApp(cfg).BackfillConversationRoles(context.Background(), dryRun)
+}
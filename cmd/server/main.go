@@ -52,6 +52,22 @@ func main() {
 		log.Fatal("Failed to load configuration", zap.Error(err))
 	}
 
+	// Run schema migrations only
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(cfg, os.Args[2:]); err != nil {
+			log.Fatal("Failed to run migrations", zap.Error(err))
+		}
+		return
+	}
+
+	// Correct the contact roles of existing conversations only
+	if len(os.Args) > 1 && os.Args[1] == "backfill-roles" {
+		if err := runBackfillRoles(cfg, os.Args[2:]); err != nil {
+			log.Fatal("Failed to backfill conversation roles", zap.Error(err))
+		}
+		return
+	}
+
 	// Create and initialize the application
 	application := app.NewApp(cfg)
 	if err := application.Initialize(); err != nil {
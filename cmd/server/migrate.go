@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"messaging-service/internal/app"
+	"messaging-service/internal/config"
+)
+
+// runMigrate handles the migrate subcommand: migrate up | down [N] | status
+func runMigrate(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate up | down [N] | status")
+	}
+
+	command := args[0]
+	steps := 1
+	switch command {
+	case "up", "status":
+		if len(args) > 1 {
+			return fmt.Errorf("migrate %s takes no arguments", command)
+		}
+	case "down":
+		if len(args) > 2 {
+			return fmt.Errorf("migrate down takes at most one argument")
+		}
+		if len(args) == 2 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("invalid number of migrations to revert: %s", args[1])
+			}
+			steps = n
+		}
+	default:
+		return fmt.Errorf("unknown migrate command: %s", command)
+	}
+
+	return app.NewApp(cfg).Migrate(context.Background(), command, steps)
+}
@@ -9,6 +9,10 @@ import (
 	"messaging-service/internal/config"
 	"messaging-service/internal/container"
 	"messaging-service/internal/logger"
+	"messaging-service/internal/middleware"
+	"messaging-service/internal/migrate"
+	"messaging-service/internal/repository/postgres"
+	"messaging-service/internal/repository/sqlite"
 	"messaging-service/internal/router"
 	"messaging-service/internal/telemetry"
 
@@ -47,8 +51,20 @@ func (a *App) Initialize() error {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	// Bring the schema up to date before anything uses it
+	if a.config.Database.MigrateOnStartup {
+		if err := a.migrateUp(context.Background(), db); err != nil {
+			db.Close()
+			return err
+		}
+	}
+
 	// Initialize dependency container
-	a.container = container.NewContainer(a.config, db)
+	a.container, err = container.NewContainer(a.config, db)
+	if err != nil {
+		db.Close()
+		return fmt.Errorf("failed to initialize container: %w", err)
+	}
 
 	// Setup router
 	router := a.setupRouter()
@@ -69,6 +85,14 @@ func (a *App) Initialize() error {
 // Start starts the application server
 func (a *App) Start() error {
 	a.logger.Info("Starting server", zap.String("port", a.config.Server.Port))
+
+	// Deliver queued outbound messages in the background
+	a.container.OutboxDispatcher.Start(context.Background())
+	// Queue scheduled messages once their send time arrives
+	a.container.Scheduler.Start(context.Background())
+	// Delete idempotency keys once their responses are no longer replayed
+	a.container.IdempotencyCleaner.Start(context.Background())
+
 	return a.server.ListenAndServe()
 }
 
@@ -81,6 +105,20 @@ func (a *App) Shutdown(ctx context.Context) error {
 		a.logger.Error("Failed to shutdown telemetry", zap.Error(err))
 	}
 
+	// Shutdown server
+	if a.server != nil {
+		if err := a.server.Shutdown(ctx); err != nil {
+			return fmt.Errorf("failed to shutdown server: %w", err)
+		}
+	}
+
+	// Stop background workers before closing the database
+	if a.container != nil {
+		a.container.IdempotencyCleaner.Stop()
+		a.container.Scheduler.Stop()
+		a.container.OutboxDispatcher.Stop()
+	}
+
 	// Close container resources
 	if a.container != nil {
 		if err := a.container.Close(); err != nil {
@@ -88,20 +126,117 @@ func (a *App) Shutdown(ctx context.Context) error {
 		}
 	}
 
-	// Shutdown server
-	if a.server != nil {
-		if err := a.server.Shutdown(ctx); err != nil {
-			return fmt.Errorf("failed to shutdown server: %w", err)
+	a.logger.Info("Application shutdown complete")
+	return nil
+}
+
+// Migrate runs a migration command (up, down or status) against the configured database.
+// For down, steps is the number of migrations to revert.
+func (a *App) Migrate(ctx context.Context, command string, steps int) error {
+	db, err := a.connectDatabase()
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	if command == "up" {
+		return a.migrateUp(ctx, db)
+	}
+
+	migrator, err := newMigrator(db, &a.config.Database, a.logger)
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "down":
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return fmt.Errorf("failed to revert migrations: %w", err)
+		}
+		a.logger.Info("Reverted migrations", zap.Int("count", reverted))
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get migration status: %w", err)
+		}
+		for _, status := range statuses {
+			fields := []zap.Field{zap.Int("version", status.Version), zap.String("name", status.Name)}
+			if status.AppliedAt != nil {
+				fields = append(fields, zap.Time("applied_at", *status.AppliedAt))
+			}
+			a.logger.Info("Migration", append(fields, zap.Bool("applied", status.AppliedAt != nil))...)
 		}
+	default:
+		return fmt.Errorf("unknown migrate command: %s", command)
 	}
 
-	a.logger.Info("Application shutdown complete")
 	return nil
 }
 
+// BackfillConversationRoles corrects the customer and business contacts of existing
+// conversations using the configured business identities. With dryRun set, nothing is changed.
+func (a *App) BackfillConversationRoles(ctx context.Context, dryRun bool) error {
+	db, err := a.connectDatabase()
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	c, err := container.NewContainer(a.config, db)
+	if err != nil {
+		db.Close()
+		return fmt.Errorf("failed to initialize container: %w", err)
+	}
+	defer c.Close()
+
+	result, err := c.RoleBackfill.Run(ctx, dryRun)
+	if err != nil {
+		return fmt.Errorf("failed to backfill conversation roles: %w", err)
+	}
+
+	a.logger.Info("Backfilled conversation roles",
+		zap.Int("scanned", result.Scanned),
+		zap.Int("swapped", result.Swapped),
+		zap.Int("merged", result.Merged),
+		zap.Int("unresolved", result.Unresolved),
+		zap.Bool("dry_run", dryRun),
+	)
+	return nil
+}
+
+// migrateUp applies all pending schema migrations
+func (a *App) migrateUp(ctx context.Context, db *sql.DB) error {
+	migrator, err := newMigrator(db, &a.config.Database, a.logger)
+	if err != nil {
+		return err
+	}
+
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to apply migrations: %w", err)
+	}
+
+	a.logger.Info("Database schema is up to date", zap.Int("applied", applied))
+	return nil
+}
+
+// newMigrator creates a migrator for the embedded migrations of the configured database driver
+func newMigrator(db *sql.DB, cfg *config.DatabaseConfig, logger *zap.Logger) (*migrate.Migrator, error) {
+	loadMigrations, dialect := postgres.Migrations, migrate.Postgres
+	if cfg.IsSQLite() {
+		loadMigrations, dialect = sqlite.Migrations, migrate.SQLite
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	return migrate.NewMigrator(db, dialect, migrations, logger), nil
+}
+
 // connectDatabase establishes database connection
 func (a *App) connectDatabase() (*sql.DB, error) {
-	db, err := sql.Open("postgres", a.config.Database.GetDSN())
+	db, err := sql.Open(a.config.Database.DriverName(), a.config.Database.GetDSN())
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database connection: %w", err)
 	}
@@ -126,7 +261,8 @@ func (a *App) setupRouter() http.Handler {
 	router := router.NewRouter()
 
 	// Setup routes with handlers from container
-	router.SetupRoutes(a.container.MessagingHandler, a.logger)
+	idempotency := middleware.IdempotencyMiddleware(a.container.IdempotencyRepo, a.config.Messaging.IdempotencyTTL, a.config.Messaging.IdempotencyLease)
+	router.SetupRoutes(a.container.MessagingHandler, a.container.ContactHandler, a.container.HealthHandler, idempotency, a.logger)
 
 	return router.GetEngine()
 }
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
+
+	"messaging-service/internal/contact"
 )
 
 // Config holds all configuration for the application
@@ -12,6 +15,10 @@ type Config struct {
 	Server    ServerConfig
 	Database  DatabaseConfig
 	Providers ProvidersConfig
+	Outbox    OutboxConfig
+	Scheduler SchedulerConfig
+	Messaging MessagingConfig
+	Retry     RetryConfig
 }
 
 // ServerConfig holds server-related configuration
@@ -22,8 +29,20 @@ type ServerConfig struct {
 	IdleTimeout  time.Duration
 }
 
+// Supported database drivers
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
 // DatabaseConfig holds database-related configuration
 type DatabaseConfig struct {
+	// Driver selects the storage backend, DriverPostgres or DriverSQLite; empty means PostgreSQL
+	Driver string
+
+	// Path is the SQLite database file; the connection settings below are for PostgreSQL
+	Path string
+
 	Host     string
 	Port     string
 	Name     string
@@ -35,12 +54,142 @@ type DatabaseConfig struct {
 	MaxOpenConns    int
 	MaxIdleConns    int
 	ConnMaxLifetime time.Duration
+
+	// MigrateOnStartup applies pending schema migrations when the server starts
+	MigrateOnStartup bool
 }
 
 // ProvidersConfig holds provider-related configuration
 type ProvidersConfig struct {
+	SMSProviderType     string
+	SMSProviderConfig   map[string]string
 	EmailProviderType   string
 	EmailProviderConfig map[string]string
+
+	// SMSProviders and EmailProviders list providers in failover order; when empty the single provider type is used
+	SMSProviders      []string
+	SMSRoutingRules   []RoutingRule
+	EmailProviders    []string
+	EmailRoutingRules []RoutingRule
+
+	CircuitBreaker CircuitBreakerConfig
+	RateLimit      RateLimitConfig
+}
+
+// RateLimitConfig holds outbound throughput limits in messages per second; a zero rate disables the limit
+type RateLimitConfig struct {
+	Enabled      bool
+	LongCodeMPS  float64
+	TollFreeMPS  float64
+	ShortCodeMPS float64
+	// EmailPerSecond limits each email provider account
+	EmailPerSecond float64
+}
+
+// CircuitBreakerConfig holds the per-provider circuit breaker settings
+type CircuitBreakerConfig struct {
+	Enabled bool
+	// Window is the period over which the failure rate is measured
+	Window time.Duration
+	// MinRequests is the number of calls in the window before the breaker may open
+	MinRequests int
+	// FailureRate is the fraction of failed calls that opens the breaker
+	FailureRate float64
+	// CoolDown is how long an open breaker waits before letting trial calls through
+	CoolDown time.Duration
+	// HalfOpenRequests is the number of trial calls allowed while half-open
+	HalfOpenRequests int
+}
+
+// RoutingRule prefers a provider for messages whose "to" or "from" address starts with Prefix
+type RoutingRule struct {
+	Field    string
+	Prefix   string
+	Provider string
+}
+
+// SMSProviderNames returns the SMS providers in failover order
+func (p ProvidersConfig) SMSProviderNames() []string {
+	if len(p.SMSProviders) > 0 {
+		return p.SMSProviders
+	}
+	if p.SMSProviderType == "" {
+		return nil
+	}
+	return []string{p.SMSProviderType}
+}
+
+// EmailProviderNames returns the email providers in failover order
+func (p ProvidersConfig) EmailProviderNames() []string {
+	if len(p.EmailProviders) > 0 {
+		return p.EmailProviders
+	}
+	if p.EmailProviderType == "" {
+		return nil
+	}
+	return []string{p.EmailProviderType}
+}
+
+// OutboxConfig holds outbound delivery dispatcher configuration
+type OutboxConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	RetryDelay   time.Duration
+}
+
+// SchedulerConfig holds scheduled message configuration
+type SchedulerConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+}
+
+// RetryConfig holds the retry policy for provider sends
+type RetryConfig struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+	Multiplier float64
+	// Jitter is none, full or decorrelated
+	Jitter string
+	// Budget bounds the total time spent retrying one delivery
+	Budget time.Duration
+	// StatusClasses overrides the retry class (permanent, transient or rate_limited) of provider status codes
+	StatusClasses map[int]string
+}
+
+// MessagingConfig holds messaging API configuration
+type MessagingConfig struct {
+	// AsyncSend queues outbound messages and returns 202 Accepted instead of waiting for the provider
+	AsyncSend bool
+	// IdempotencyTTL is how long responses to requests with an Idempotency-Key are replayed
+	IdempotencyTTL time.Duration
+	// IdempotencyLease is how long a request may hold its Idempotency-Key before a retry can take it over
+	IdempotencyLease time.Duration
+	// IdempotencyCleanupInterval is how often expired idempotency keys are deleted
+	IdempotencyCleanupInterval time.Duration
+	// PhoneDefaultRegion is the region of phone numbers given without a country code
+	PhoneDefaultRegion string
+	// EmailCanonicalize folds provider-specific aliases of a mailbox, such as plus-addressed Gmail addresses
+	EmailCanonicalize bool
+	// BusinessPhoneNumbers, BusinessEmailDomains and BusinessEmailAddresses are the identities
+	// the business owns, used to correct the contact roles of existing conversations
+	BusinessPhoneNumbers   []string
+	BusinessEmailDomains   []string
+	BusinessEmailAddresses []string
+}
+
+// ContactNormalizer returns the normalizer for the configured phone region and email canonicalization
+func (c *MessagingConfig) ContactNormalizer() *contact.Normalizer {
+	return contact.NewNormalizerWithConfig(contact.Config{
+		DefaultRegion:     c.PhoneDefaultRegion,
+		CanonicalizeEmail: c.EmailCanonicalize,
+	})
+}
+
+// BusinessRegistry returns the registry of the configured business identities
+func (c *MessagingConfig) BusinessRegistry() (*contact.BusinessRegistry, error) {
+	return contact.NewBusinessRegistry(c.ContactNormalizer(), c.BusinessPhoneNumbers, c.BusinessEmailDomains, c.BusinessEmailAddresses)
 }
 
 // Load reads configuration from environment variables
@@ -53,6 +202,8 @@ func Load() (*Config, error) {
 			IdleTimeout:  getEnvAsDuration("SERVER_IDLE_TIMEOUT", 60*time.Second),
 		},
 		Database: DatabaseConfig{
+			Driver:          getEnv("DB_DRIVER", DriverPostgres),
+			Path:            getEnv("DB_PATH", "messaging.db"),
 			Host:            getEnv("DB_HOST", "localhost"),
 			Port:            getEnv("DB_PORT", "5432"),
 			Name:            getEnv("DB_NAME", "messaging_service"),
@@ -62,13 +213,90 @@ func Load() (*Config, error) {
 			MaxOpenConns:    getEnvAsInt("DB_MAX_OPEN_CONNS", 25),
 			MaxIdleConns:    getEnvAsInt("DB_MAX_IDLE_CONNS", 25),
 			ConnMaxLifetime: getEnvAsDuration("DB_CONN_MAX_LIFETIME", 5*time.Minute),
+
+			MigrateOnStartup: getEnvAsBool("DB_MIGRATE_ON_STARTUP", true),
 		},
 		Providers: ProvidersConfig{
+			SMSProviderType: getEnv("SMS_PROVIDER_TYPE", "mock"),
+			SMSProviderConfig: map[string]string{
+				"account_sid": getEnv("TWILIO_ACCOUNT_SID", ""),
+				"auth_token":  getEnv("TWILIO_AUTH_TOKEN", ""),
+				"base_url":    getEnv("TWILIO_BASE_URL", "https://api.twilio.com"),
+			},
 			EmailProviderType: getEnv("EMAIL_PROVIDER_TYPE", "mock"),
 			EmailProviderConfig: map[string]string{
-				"api_key": getEnv("SENDGRID_API_KEY", ""),
+				"api_key":  getEnv("SENDGRID_API_KEY", ""),
+				"base_url": getEnv("SENDGRID_BASE_URL", "https://api.sendgrid.com"),
+				"subject":  getEnv("EMAIL_SUBJECT", "New message"),
+				"host":     getEnv("SMTP_HOST", ""),
+				"port":     getEnv("SMTP_PORT", "587"),
+				"username": getEnv("SMTP_USERNAME", ""),
+				"password": getEnv("SMTP_PASSWORD", ""),
+				"tls_mode": getEnv("SMTP_TLS_MODE", "starttls"),
+				"auth":     getEnv("SMTP_AUTH", "plain"),
 			},
+			SMSProviders:   getEnvAsList("SMS_PROVIDERS"),
+			EmailProviders: getEnvAsList("EMAIL_PROVIDERS"),
+			CircuitBreaker: CircuitBreakerConfig{
+				Enabled:          getEnvAsBool("CIRCUIT_BREAKER_ENABLED", true),
+				Window:           getEnvAsDuration("CIRCUIT_BREAKER_WINDOW", 30*time.Second),
+				MinRequests:      getEnvAsInt("CIRCUIT_BREAKER_MIN_REQUESTS", 10),
+				FailureRate:      getEnvAsFloat("CIRCUIT_BREAKER_FAILURE_RATE", 0.5),
+				CoolDown:         getEnvAsDuration("CIRCUIT_BREAKER_COOL_DOWN", 30*time.Second),
+				HalfOpenRequests: getEnvAsInt("CIRCUIT_BREAKER_HALF_OPEN_REQUESTS", 1),
+			},
+			RateLimit: RateLimitConfig{
+				Enabled:        getEnvAsBool("RATE_LIMIT_ENABLED", false),
+				LongCodeMPS:    getEnvAsFloat("SMS_LONG_CODE_MPS", 1),
+				TollFreeMPS:    getEnvAsFloat("SMS_TOLL_FREE_MPS", 3),
+				ShortCodeMPS:   getEnvAsFloat("SMS_SHORT_CODE_MPS", 100),
+				EmailPerSecond: getEnvAsFloat("EMAIL_RATE_LIMIT_PER_SECOND", 100),
+			},
+		},
+		Outbox: OutboxConfig{
+			PollInterval: getEnvAsDuration("OUTBOX_POLL_INTERVAL", time.Second),
+			BatchSize:    getEnvAsInt("OUTBOX_BATCH_SIZE", 10),
+			MaxAttempts:  getEnvAsInt("OUTBOX_MAX_ATTEMPTS", 5),
+			RetryDelay:   getEnvAsDuration("OUTBOX_RETRY_DELAY", 30*time.Second),
 		},
+		Scheduler: SchedulerConfig{
+			PollInterval: getEnvAsDuration("SCHEDULER_POLL_INTERVAL", time.Second),
+			BatchSize:    getEnvAsInt("SCHEDULER_BATCH_SIZE", 100),
+		},
+		Messaging: MessagingConfig{
+			AsyncSend:                  getEnvAsBool("ASYNC_SEND", false),
+			IdempotencyTTL:             getEnvAsDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+			IdempotencyLease:           getEnvAsDuration("IDEMPOTENCY_LEASE", 5*time.Minute),
+			IdempotencyCleanupInterval: getEnvAsDuration("IDEMPOTENCY_CLEANUP_INTERVAL", 10*time.Minute),
+
+			PhoneDefaultRegion: getEnv("PHONE_DEFAULT_REGION", contact.DefaultRegion),
+			EmailCanonicalize:  getEnvAsBool("EMAIL_CANONICALIZE", false),
+
+			BusinessPhoneNumbers:   getEnvAsList("BUSINESS_PHONE_NUMBERS"),
+			BusinessEmailDomains:   getEnvAsList("BUSINESS_EMAIL_DOMAINS"),
+			BusinessEmailAddresses: getEnvAsList("BUSINESS_EMAIL_ADDRESSES"),
+		},
+		Retry: RetryConfig{
+			MaxRetries: getEnvAsInt("RETRY_MAX_RETRIES", 3),
+			BaseDelay:  getEnvAsDuration("RETRY_BASE_DELAY", time.Second),
+			MaxDelay:   getEnvAsDuration("RETRY_MAX_DELAY", time.Minute),
+			Multiplier: getEnvAsFloat("RETRY_MULTIPLIER", 2.0),
+			Jitter:     getEnv("RETRY_JITTER", "full"),
+			Budget:     getEnvAsDuration("RETRY_BUDGET", 2*time.Minute),
+		},
+	}
+
+	// Parse provider routing rules
+	var err error
+	if config.Providers.SMSRoutingRules, err = parseRoutingRules(getEnv("SMS_ROUTING_RULES", "")); err != nil {
+		return nil, fmt.Errorf("invalid SMS_ROUTING_RULES: %w", err)
+	}
+	if config.Providers.EmailRoutingRules, err = parseRoutingRules(getEnv("EMAIL_ROUTING_RULES", "")); err != nil {
+		return nil, fmt.Errorf("invalid EMAIL_ROUTING_RULES: %w", err)
+	}
+
+	if config.Retry.StatusClasses, err = parseStatusClasses(getEnv("RETRY_STATUS_CLASSES", "")); err != nil {
+		return nil, fmt.Errorf("invalid RETRY_STATUS_CLASSES: %w", err)
 	}
 
 	// Validate configuration
@@ -87,14 +315,23 @@ func (c *Config) validate() error {
 	}
 
 	// Validate database configuration
-	if c.Database.Host == "" {
-		return fmt.Errorf("database host cannot be empty")
-	}
-	if c.Database.Name == "" {
-		return fmt.Errorf("database name cannot be empty")
-	}
-	if c.Database.User == "" {
-		return fmt.Errorf("database user cannot be empty")
+	switch c.Database.Driver {
+	case "", DriverPostgres:
+		if c.Database.Host == "" {
+			return fmt.Errorf("database host cannot be empty")
+		}
+		if c.Database.Name == "" {
+			return fmt.Errorf("database name cannot be empty")
+		}
+		if c.Database.User == "" {
+			return fmt.Errorf("database user cannot be empty")
+		}
+	case DriverSQLite:
+		if c.Database.Path == "" {
+			return fmt.Errorf("database path cannot be empty")
+		}
+	default:
+		return fmt.Errorf("unsupported database driver: %s", c.Database.Driver)
 	}
 
 	// Validate timeouts
@@ -119,15 +356,249 @@ func (c *Config) validate() error {
 		return fmt.Errorf("database connection max lifetime must be positive")
 	}
 
+	// Validate outbox settings
+	if c.Outbox.PollInterval <= 0 {
+		return fmt.Errorf("outbox poll interval must be positive")
+	}
+	if c.Outbox.BatchSize <= 0 {
+		return fmt.Errorf("outbox batch size must be positive")
+	}
+	if c.Outbox.MaxAttempts <= 0 {
+		return fmt.Errorf("outbox max attempts must be positive")
+	}
+	if c.Outbox.RetryDelay <= 0 {
+		return fmt.Errorf("outbox retry delay must be positive")
+	}
+
+	// Validate messaging settings
+	if c.Messaging.IdempotencyTTL <= 0 {
+		return fmt.Errorf("idempotency TTL must be positive")
+	}
+	if c.Messaging.IdempotencyLease <= 0 {
+		return fmt.Errorf("idempotency lease must be positive")
+	}
+	// A send still retrying when its lease runs out would be sent again by a retried request
+	if c.Retry.Budget > 0 && c.Messaging.IdempotencyLease < c.Retry.Budget {
+		return fmt.Errorf("idempotency lease (%s) must be at least the retry budget (%s)", c.Messaging.IdempotencyLease, c.Retry.Budget)
+	}
+	if c.Messaging.IdempotencyCleanupInterval <= 0 {
+		return fmt.Errorf("idempotency cleanup interval must be positive")
+	}
+	if !contact.IsSupportedRegion(c.Messaging.PhoneDefaultRegion) {
+		return fmt.Errorf("unsupported phone default region: %s", c.Messaging.PhoneDefaultRegion)
+	}
+	if _, err := c.Messaging.BusinessRegistry(); err != nil {
+		return err
+	}
+
+	// Validate scheduler settings
+	if c.Scheduler.PollInterval <= 0 {
+		return fmt.Errorf("scheduler poll interval must be positive")
+	}
+	if c.Scheduler.BatchSize <= 0 {
+		return fmt.Errorf("scheduler batch size must be positive")
+	}
+
+	// Validate retry settings
+	if c.Retry.MaxRetries < 0 {
+		return fmt.Errorf("retry max retries cannot be negative")
+	}
+	if c.Retry.BaseDelay < 0 || c.Retry.MaxDelay < 0 || c.Retry.Budget < 0 {
+		return fmt.Errorf("retry delays cannot be negative")
+	}
+	if c.Retry.Multiplier < 1 && c.Retry.Multiplier != 0 {
+		return fmt.Errorf("retry multiplier must be at least 1")
+	}
+	switch c.Retry.Jitter {
+	case "", "none", "full", "decorrelated":
+	default:
+		return fmt.Errorf("invalid retry jitter: %s", c.Retry.Jitter)
+	}
+
+	// Validate provider settings
+	smsProviders := c.Providers.SMSProviderNames()
+	for _, name := range smsProviders {
+		switch name {
+		case "mock":
+		case "twilio":
+			if c.Providers.SMSProviderConfig["account_sid"] == "" || c.Providers.SMSProviderConfig["auth_token"] == "" {
+				return fmt.Errorf("Twilio account SID and auth token are required when the twilio SMS provider is used")
+			}
+		default:
+			return fmt.Errorf("unknown SMS provider: %s", name)
+		}
+	}
+	if err := validateRoutingRules(c.Providers.SMSRoutingRules, smsProviders); err != nil {
+		return fmt.Errorf("invalid SMS routing rules: %w", err)
+	}
+
+	emailProviders := c.Providers.EmailProviderNames()
+	for _, name := range emailProviders {
+		switch name {
+		case "mock":
+		case "sendgrid":
+			if c.Providers.EmailProviderConfig["api_key"] == "" {
+				return fmt.Errorf("SendGrid API key is required when the sendgrid email provider is used")
+			}
+		case "smtp":
+			if err := validateSMTPConfig(c.Providers.EmailProviderConfig); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown email provider: %s", name)
+		}
+	}
+	if err := validateRoutingRules(c.Providers.EmailRoutingRules, emailProviders); err != nil {
+		return fmt.Errorf("invalid email routing rules: %w", err)
+	}
+
+	// Validate circuit breaker settings
+	if breaker := c.Providers.CircuitBreaker; breaker.Enabled {
+		if breaker.Window <= 0 {
+			return fmt.Errorf("circuit breaker window must be positive")
+		}
+		if breaker.MinRequests <= 0 {
+			return fmt.Errorf("circuit breaker min requests must be positive")
+		}
+		if breaker.FailureRate <= 0 || breaker.FailureRate > 1 {
+			return fmt.Errorf("circuit breaker failure rate must be between 0 and 1")
+		}
+		if breaker.CoolDown <= 0 {
+			return fmt.Errorf("circuit breaker cool-down must be positive")
+		}
+		if breaker.HalfOpenRequests <= 0 {
+			return fmt.Errorf("circuit breaker half-open requests must be positive")
+		}
+	}
+
+	// Validate rate limits
+	if limits := c.Providers.RateLimit; limits.LongCodeMPS < 0 || limits.TollFreeMPS < 0 ||
+		limits.ShortCodeMPS < 0 || limits.EmailPerSecond < 0 {
+		return fmt.Errorf("rate limits cannot be negative")
+	}
+
 	return nil
 }
 
-// GetDSN returns the database connection string
+// IsSQLite reports whether the SQLite backend is selected
+func (c *DatabaseConfig) IsSQLite() bool {
+	return c.Driver == DriverSQLite
+}
+
+// DriverName returns the name the database/sql driver is registered under
+func (c *DatabaseConfig) DriverName() string {
+	if c.IsSQLite() {
+		return "sqlite3"
+	}
+	return "postgres"
+}
+
+// GetDSN returns the database connection string for the selected driver
 func (c *DatabaseConfig) GetDSN() string {
+	if c.IsSQLite() {
+		// Writers wait for each other instead of failing, and transactions take the write lock
+		// up front so that they cannot deadlock when upgrading from a read
+		return fmt.Sprintf("file:%s?_foreign_keys=on&_busy_timeout=5000&_journal_mode=WAL&_txlock=immediate", c.Path)
+	}
 	return fmt.Sprintf("host=%s port=%s dbname=%s user=%s password=%s sslmode=%s",
 		c.Host, c.Port, c.Name, c.User, c.Password, c.SSLMode)
 }
 
+// validateSMTPConfig validates the SMTP provider settings
+func validateSMTPConfig(smtpConfig map[string]string) error {
+	if smtpConfig["host"] == "" {
+		return fmt.Errorf("SMTP host is required when the smtp email provider is used")
+	}
+	switch smtpConfig["tls_mode"] {
+	case "", "none", "starttls", "tls":
+	default:
+		return fmt.Errorf("invalid SMTP TLS mode: %s", smtpConfig["tls_mode"])
+	}
+	switch smtpConfig["auth"] {
+	case "", "none", "plain", "login":
+	default:
+		return fmt.Errorf("invalid SMTP auth mechanism: %s", smtpConfig["auth"])
+	}
+	return nil
+}
+
+// validateRoutingRules checks that every rule targets a configured provider
+func validateRoutingRules(rules []RoutingRule, providers []string) error {
+	for _, rule := range rules {
+		if rule.Field != "to" && rule.Field != "from" {
+			return fmt.Errorf("invalid field %q, expected to or from", rule.Field)
+		}
+		found := false
+		for _, name := range providers {
+			if name == rule.Provider {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("rule for %s:%s references provider %s which is not configured", rule.Field, rule.Prefix, rule.Provider)
+		}
+	}
+	return nil
+}
+
+// parseRoutingRules parses a comma-separated list of rules in the form field:prefix=provider,
+// for example "to:+44=twilio,from:+18005550000=mock"
+func parseRoutingRules(spec string) ([]RoutingRule, error) {
+	var rules []RoutingRule
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		match, providerName, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("rule %q is missing =provider", item)
+		}
+		field, prefix, ok := strings.Cut(match, ":")
+		if !ok || prefix == "" {
+			return nil, fmt.Errorf("rule %q must match field:prefix", item)
+		}
+
+		rules = append(rules, RoutingRule{
+			Field:    strings.ToLower(strings.TrimSpace(field)),
+			Prefix:   strings.TrimSpace(prefix),
+			Provider: strings.TrimSpace(providerName),
+		})
+	}
+	return rules, nil
+}
+
+// parseStatusClasses parses a comma-separated list of status=class overrides,
+// for example "500=permanent,409=transient"
+func parseStatusClasses(spec string) (map[int]string, error) {
+	classes := make(map[int]string)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		status, class, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("entry %q must match status=class", item)
+		}
+		code, err := strconv.Atoi(strings.TrimSpace(status))
+		if err != nil || code < 100 || code > 599 {
+			return nil, fmt.Errorf("invalid status code in %q", item)
+		}
+		class = strings.ToLower(strings.TrimSpace(class))
+		switch class {
+		case "permanent", "transient", "rate_limited":
+		default:
+			return nil, fmt.Errorf("invalid retry class %q, expected permanent, transient or rate_limited", class)
+		}
+		classes[code] = class
+	}
+	return classes, nil
+}
+
 // getEnv reads an environment variable with a default value
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -146,6 +617,37 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvAsFloat reads an environment variable as a float with a default value
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsBool reads an environment variable as a boolean with a default value
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsList reads an environment variable as a comma-separated list
+func getEnvAsList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 // getEnvAsDuration reads an environment variable as a duration with a default value
 func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
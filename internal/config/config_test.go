@@ -23,6 +23,8 @@ func TestLoad_DefaultValues(t *testing.T) {
 	assert.Equal(t, 60*time.Second, config.Server.IdleTimeout)
 
 	// Test database defaults
+	assert.Equal(t, DriverPostgres, config.Database.Driver)
+	assert.Equal(t, "messaging.db", config.Database.Path)
 	assert.Equal(t, "localhost", config.Database.Host)
 	assert.Equal(t, "5432", config.Database.Port)
 	assert.Equal(t, "messaging_service", config.Database.Name)
@@ -32,6 +34,42 @@ func TestLoad_DefaultValues(t *testing.T) {
 	assert.Equal(t, 25, config.Database.MaxOpenConns)
 	assert.Equal(t, 25, config.Database.MaxIdleConns)
 	assert.Equal(t, 5*time.Minute, config.Database.ConnMaxLifetime)
+	assert.True(t, config.Database.MigrateOnStartup)
+
+	// Test outbox defaults
+	assert.Equal(t, time.Second, config.Outbox.PollInterval)
+	assert.Equal(t, 10, config.Outbox.BatchSize)
+	assert.Equal(t, 5, config.Outbox.MaxAttempts)
+	assert.Equal(t, 30*time.Second, config.Outbox.RetryDelay)
+
+	// Test scheduler defaults
+	assert.Equal(t, time.Second, config.Scheduler.PollInterval)
+	assert.Equal(t, 100, config.Scheduler.BatchSize)
+
+	// Test messaging defaults
+	assert.False(t, config.Messaging.AsyncSend)
+	assert.Equal(t, 24*time.Hour, config.Messaging.IdempotencyTTL)
+	assert.Equal(t, 5*time.Minute, config.Messaging.IdempotencyLease)
+	assert.Equal(t, 10*time.Minute, config.Messaging.IdempotencyCleanupInterval)
+	assert.Equal(t, "US", config.Messaging.PhoneDefaultRegion)
+	assert.False(t, config.Messaging.EmailCanonicalize)
+
+	// Test circuit breaker defaults
+	assert.True(t, config.Providers.CircuitBreaker.Enabled)
+	assert.Equal(t, 30*time.Second, config.Providers.CircuitBreaker.Window)
+	assert.Equal(t, 10, config.Providers.CircuitBreaker.MinRequests)
+	assert.Equal(t, 0.5, config.Providers.CircuitBreaker.FailureRate)
+	assert.Equal(t, 30*time.Second, config.Providers.CircuitBreaker.CoolDown)
+	assert.Equal(t, 1, config.Providers.CircuitBreaker.HalfOpenRequests)
+
+	// Test rate limit defaults
+	assert.Equal(t, RateLimitConfig{
+		Enabled:        false,
+		LongCodeMPS:    1,
+		TollFreeMPS:    3,
+		ShortCodeMPS:   100,
+		EmailPerSecond: 100,
+	}, config.Providers.RateLimit)
 }
 
 func TestLoad_CustomValues(t *testing.T) {
@@ -41,6 +79,9 @@ func TestLoad_CustomValues(t *testing.T) {
 	os.Setenv("DB_PASSWORD", "custom-password")
 	os.Setenv("SERVER_READ_TIMEOUT", "60s")
 	os.Setenv("DB_MAX_OPEN_CONNS", "50")
+	os.Setenv("ASYNC_SEND", "true")
+	os.Setenv("BUSINESS_PHONE_NUMBERS", "+12016661234, (804) 555-1234")
+	os.Setenv("BUSINESS_EMAIL_DOMAINS", "usehatchapp.com")
 
 	config, err := Load()
 	require.NoError(t, err)
@@ -51,11 +92,120 @@ func TestLoad_CustomValues(t *testing.T) {
 	assert.Equal(t, "custom-password", config.Database.Password)
 	assert.Equal(t, 60*time.Second, config.Server.ReadTimeout)
 	assert.Equal(t, 50, config.Database.MaxOpenConns)
+	assert.True(t, config.Messaging.AsyncSend)
+	assert.Equal(t, []string{"+12016661234", "(804) 555-1234"}, config.Messaging.BusinessPhoneNumbers)
+	assert.Equal(t, []string{"usehatchapp.com"}, config.Messaging.BusinessEmailDomains)
 
 	// Clean up
 	os.Clearenv()
 }
 
+func TestLoad_ProviderRouting(t *testing.T) {
+	os.Setenv("SMS_PROVIDERS", "twilio, mock")
+	os.Setenv("SMS_ROUTING_RULES", "to:+44=twilio, from:+18005550000=mock")
+	os.Setenv("TWILIO_ACCOUNT_SID", "AC123")
+	os.Setenv("TWILIO_AUTH_TOKEN", "secret")
+	defer os.Clearenv()
+
+	config, err := Load()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"twilio", "mock"}, config.Providers.SMSProviderNames())
+	assert.Equal(t, []RoutingRule{
+		{Field: "to", Prefix: "+44", Provider: "twilio"},
+		{Field: "from", Prefix: "+18005550000", Provider: "mock"},
+	}, config.Providers.SMSRoutingRules)
+	assert.Equal(t, []string{"mock"}, config.Providers.EmailProviderNames())
+
+	// Rules must reference a configured provider
+	os.Setenv("SMS_ROUTING_RULES", "to:+44=sendgrid")
+	_, err = Load()
+	assert.Error(t, err)
+
+	// Rules must be well formed
+	os.Setenv("SMS_ROUTING_RULES", "to+44=twilio")
+	_, err = Load()
+	assert.Error(t, err)
+}
+
+func TestLoad_CircuitBreaker(t *testing.T) {
+	os.Setenv("CIRCUIT_BREAKER_WINDOW", "1m")
+	os.Setenv("CIRCUIT_BREAKER_MIN_REQUESTS", "20")
+	os.Setenv("CIRCUIT_BREAKER_FAILURE_RATE", "0.25")
+	os.Setenv("CIRCUIT_BREAKER_COOL_DOWN", "10s")
+	os.Setenv("CIRCUIT_BREAKER_HALF_OPEN_REQUESTS", "3")
+	defer os.Clearenv()
+
+	config, err := Load()
+	require.NoError(t, err)
+
+	assert.Equal(t, CircuitBreakerConfig{
+		Enabled:          true,
+		Window:           time.Minute,
+		MinRequests:      20,
+		FailureRate:      0.25,
+		CoolDown:         10 * time.Second,
+		HalfOpenRequests: 3,
+	}, config.Providers.CircuitBreaker)
+
+	// The failure rate is a fraction
+	os.Setenv("CIRCUIT_BREAKER_FAILURE_RATE", "50")
+	_, err = Load()
+	assert.Error(t, err)
+
+	// Settings are not validated when the breaker is disabled
+	os.Setenv("CIRCUIT_BREAKER_ENABLED", "false")
+	_, err = Load()
+	assert.NoError(t, err)
+}
+
+func TestLoad_RateLimit(t *testing.T) {
+	os.Setenv("RATE_LIMIT_ENABLED", "true")
+	os.Setenv("SMS_LONG_CODE_MPS", "0.5")
+	os.Setenv("SMS_SHORT_CODE_MPS", "0")
+	defer os.Clearenv()
+
+	config, err := Load()
+	require.NoError(t, err)
+	assert.True(t, config.Providers.RateLimit.Enabled)
+	assert.Equal(t, 0.5, config.Providers.RateLimit.LongCodeMPS)
+	assert.Equal(t, 0.0, config.Providers.RateLimit.ShortCodeMPS)
+
+	os.Setenv("EMAIL_RATE_LIMIT_PER_SECOND", "-1")
+	_, err = Load()
+	assert.Error(t, err)
+}
+
+func TestLoad_Retry(t *testing.T) {
+	os.Setenv("RETRY_MAX_RETRIES", "5")
+	os.Setenv("RETRY_MULTIPLIER", "3")
+	os.Setenv("RETRY_JITTER", "decorrelated")
+	os.Setenv("RETRY_BUDGET", "90s")
+	os.Setenv("RETRY_STATUS_CLASSES", "500=permanent, 409=transient")
+	defer os.Clearenv()
+
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, RetryConfig{
+		MaxRetries:    5,
+		BaseDelay:     time.Second,
+		MaxDelay:      time.Minute,
+		Multiplier:    3,
+		Jitter:        "decorrelated",
+		Budget:        90 * time.Second,
+		StatusClasses: map[int]string{500: "permanent", 409: "transient"},
+	}, config.Retry)
+
+	os.Setenv("RETRY_STATUS_CLASSES", "500=sometimes")
+	_, err = Load()
+	assert.Error(t, err)
+
+	os.Setenv("RETRY_STATUS_CLASSES", "")
+	os.Setenv("RETRY_JITTER", "random")
+	_, err = Load()
+	assert.Error(t, err)
+}
+
 func TestLoad_InvalidValues(t *testing.T) {
 	// Test invalid duration - should fall back to default
 	os.Setenv("SERVER_READ_TIMEOUT", "invalid")
@@ -87,6 +237,32 @@ func TestDatabaseConfig_GetDSN(t *testing.T) {
 	dsn := dbConfig.GetDSN()
 	expected := "host=test-host port=5433 dbname=test-db user=test-user password=test-pass sslmode=require"
 	assert.Equal(t, expected, dsn)
+	assert.Equal(t, "postgres", dbConfig.DriverName())
+}
+
+func TestDatabaseConfig_GetDSN_SQLite(t *testing.T) {
+	dbConfig := DatabaseConfig{
+		Driver: DriverSQLite,
+		Path:   "/var/lib/messaging/messaging.db",
+		Host:   "ignored",
+	}
+
+	dsn := dbConfig.GetDSN()
+	expected := "file:/var/lib/messaging/messaging.db?_foreign_keys=on&_busy_timeout=5000&_journal_mode=WAL&_txlock=immediate"
+	assert.Equal(t, expected, dsn)
+	assert.Equal(t, "sqlite3", dbConfig.DriverName())
+}
+
+func TestLoad_SQLite(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("DB_DRIVER", "sqlite")
+	os.Setenv("DB_PATH", "/tmp/messaging.db")
+	defer os.Clearenv()
+
+	config, err := Load()
+	require.NoError(t, err)
+	assert.True(t, config.Database.IsSQLite())
+	assert.Equal(t, "/tmp/messaging.db", config.Database.Path)
 }
 
 func TestConfig_Validate(t *testing.T) {
@@ -108,10 +284,78 @@ func TestConfig_Validate(t *testing.T) {
 			MaxIdleConns:    25,
 			ConnMaxLifetime: 5 * time.Minute,
 		},
+		Outbox: OutboxConfig{
+			PollInterval: time.Second,
+			BatchSize:    10,
+			MaxAttempts:  5,
+			RetryDelay:   30 * time.Second,
+		},
+		Scheduler: SchedulerConfig{
+			PollInterval: time.Second,
+			BatchSize:    100,
+		},
+		Messaging: MessagingConfig{
+			IdempotencyTTL:             24 * time.Hour,
+			IdempotencyLease:           5 * time.Minute,
+			IdempotencyCleanupInterval: 10 * time.Minute,
+			PhoneDefaultRegion:         "US",
+		},
 	}
 
 	err := config.validate()
 	assert.NoError(t, err)
+
+	// SendGrid requires an API key
+	config.Providers = ProvidersConfig{
+		EmailProviderType:   "sendgrid",
+		EmailProviderConfig: map[string]string{"api_key": ""},
+	}
+	assert.Error(t, config.validate())
+
+	config.Providers.EmailProviderConfig["api_key"] = "test-key"
+	assert.NoError(t, config.validate())
+
+	// SMTP requires a host and known TLS and auth settings
+	config.Providers.EmailProviderType = "smtp"
+	config.Providers.EmailProviderConfig = map[string]string{"host": "", "tls_mode": "starttls", "auth": "plain"}
+	assert.Error(t, config.validate())
+
+	config.Providers.EmailProviderConfig["host"] = "smtp.example.com"
+	assert.NoError(t, config.validate())
+
+	config.Providers.EmailProviderConfig["tls_mode"] = "ssl"
+	assert.Error(t, config.validate())
+
+	config.Providers.EmailProviderConfig["tls_mode"] = "tls"
+	config.Providers.EmailProviderConfig["auth"] = "cram-md5"
+	assert.Error(t, config.validate())
+
+	config.Providers.EmailProviderConfig["auth"] = "login"
+	assert.NoError(t, config.validate())
+
+	// Twilio requires credentials
+	config.Providers.SMSProviderType = "twilio"
+	config.Providers.SMSProviderConfig = map[string]string{"account_sid": "AC123", "auth_token": ""}
+	assert.Error(t, config.validate())
+
+	config.Providers.SMSProviderConfig["auth_token"] = "token"
+	assert.NoError(t, config.validate())
+
+	// Phone numbers without a country code need a known region
+	config.Messaging.PhoneDefaultRegion = "XX"
+	assert.Error(t, config.validate())
+
+	// Idempotency keys must stay locked for as long as a send may retry
+	config.Messaging.PhoneDefaultRegion = "US"
+	config.Retry.Budget = 10 * time.Minute
+	assert.Error(t, config.validate())
+
+	config.Retry.Budget = 5 * time.Minute
+	assert.NoError(t, config.validate())
+
+	// Business identities must be valid addresses
+	config.Messaging.BusinessPhoneNumbers = []string{"555-1234"}
+	assert.Error(t, config.validate())
 }
 
 func TestConfig_Validate_Errors(t *testing.T) {
@@ -163,6 +407,30 @@ func TestConfig_Validate_Errors(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "unsupported database driver",
+			config: &Config{
+				Server: ServerConfig{Port: "8080"},
+				Database: DatabaseConfig{
+					Driver: "mysql",
+					Host:   "localhost",
+					Name:   "test",
+					User:   "user",
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "empty sqlite path",
+			config: &Config{
+				Server: ServerConfig{Port: "8080"},
+				Database: DatabaseConfig{
+					Driver: DriverSQLite,
+					Path:   "",
+				},
+			},
+			expectError: true,
+		},
 		{
 			name: "invalid read timeout",
 			config: &Config{
@@ -0,0 +1,92 @@
+package contact
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+// ErrInvalidEmailAddress is returned for email addresses that cannot be delivered to
+var ErrInvalidEmailAddress = errors.New("invalid email address")
+
+// EmailAddress is a parsed email address
+type EmailAddress struct {
+	// Name is the display name, empty if none was given
+	Name string
+	// Address is the address to deliver to without the display name, such as "Jane@example.com"
+	Address string
+	// Canonical is the lowercased form of Address that conversations, contacts and the business
+	// registry are keyed by, such as "jane@example.com"
+	Canonical string
+}
+
+// mailProvider describes how a mail provider delivers aliases of a mailbox
+type mailProvider struct {
+	// domain is the canonical domain of the provider's mailboxes
+	domain string
+	// ignoresDots is set when dots in the local part are insignificant
+	ignoresDots bool
+}
+
+// mailProviders lists the providers that deliver "user+tag@domain" to "user@domain"
+var mailProviders = map[string]mailProvider{
+	"gmail.com":      {domain: "gmail.com", ignoresDots: true},
+	"googlemail.com": {domain: "gmail.com", ignoresDots: true},
+	"outlook.com":    {domain: "outlook.com"},
+	"hotmail.com":    {domain: "hotmail.com"},
+	"live.com":       {domain: "live.com"},
+	"icloud.com":     {domain: "icloud.com"},
+	"me.com":         {domain: "me.com"},
+	"fastmail.com":   {domain: "fastmail.com"},
+	"protonmail.com": {domain: "protonmail.com"},
+	"proton.me":      {domain: "proton.me"},
+}
+
+// ParseEmail parses an RFC 5322 address such as "Jane Doe <Jane@Example.com>" and returns its
+// display name, address and canonical address. The address keeps the local part as given, since
+// RFC 5321 lets the receiving server treat it case-sensitively, with only the domain lowercased.
+// The canonical address is lowercased entirely, so "JANE@Example.com" and "jane@example.com" share
+// a key. With CanonicalizeEmail set, it also folds the aliases known providers deliver to the same
+// mailbox, so "J.ane+news@googlemail.com" is keyed as "jane@gmail.com" but still delivered to as given.
+func (n *Normalizer) ParseEmail(raw string) (EmailAddress, error) {
+	parsed, err := mail.ParseAddress(strings.TrimSpace(raw))
+	if err != nil {
+		return EmailAddress{}, fmt.Errorf("%w %q: %v", ErrInvalidEmailAddress, raw, err)
+	}
+
+	at := strings.LastIndex(parsed.Address, "@")
+	local, domain := parsed.Address[:at], strings.ToLower(parsed.Address[at+1:])
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return EmailAddress{}, fmt.Errorf("%w %q: domain must be fully qualified", ErrInvalidEmailAddress, raw)
+	}
+
+	address := local + "@" + domain
+	canonicalLocal, canonicalDomain := strings.ToLower(local), domain
+	if n.canonicalizeEmail {
+		canonicalLocal, canonicalDomain = canonicalizeMailbox(canonicalLocal, canonicalDomain)
+	}
+	canonical := canonicalLocal + "@" + canonicalDomain
+
+	return EmailAddress{
+		Name:      strings.TrimSpace(parsed.Name),
+		Address:   address,
+		Canonical: canonical,
+	}, nil
+}
+
+// canonicalizeMailbox strips the aliasing a known provider ignores from a lowercased mailbox
+func canonicalizeMailbox(local, domain string) (string, string) {
+	provider, ok := mailProviders[domain]
+	if !ok || strings.HasPrefix(local, `"`) {
+		return local, domain
+	}
+
+	if tag := strings.Index(local, "+"); tag > 0 {
+		local = local[:tag]
+	}
+	if provider.ignoresDots {
+		local = strings.ReplaceAll(local, ".", "")
+	}
+	return local, provider.domain
+}
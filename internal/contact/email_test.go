@@ -0,0 +1,86 @@
+package contact
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizer_ParseEmail(t *testing.T) {
+	normalizer := NewNormalizer(DefaultRegion)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected EmailAddress
+	}{
+		{"bare address", "jane@example.com", EmailAddress{Address: "jane@example.com", Canonical: "jane@example.com"}},
+		{"display name", "Jane Doe <JANE@Example.com>", EmailAddress{Name: "Jane Doe", Address: "JANE@example.com", Canonical: "jane@example.com"}},
+		{"quoted display name", `"Doe, Jane" <jane@example.com>`, EmailAddress{Name: "Doe, Jane", Address: "jane@example.com", Canonical: "jane@example.com"}},
+		{"angle brackets only", "<jane@example.com>", EmailAddress{Address: "jane@example.com", Canonical: "jane@example.com"}},
+		{"surrounding whitespace", "  jane@example.com ", EmailAddress{Address: "jane@example.com", Canonical: "jane@example.com"}},
+		{"plus addressing kept", "jane+news@gmail.com", EmailAddress{Address: "jane+news@gmail.com", Canonical: "jane+news@gmail.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := normalizer.ParseEmail(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, parsed)
+		})
+	}
+}
+
+func TestNormalizer_ParseEmail_CaseVariantsShareKey(t *testing.T) {
+	normalizer := NewNormalizer(DefaultRegion)
+
+	upper, err := normalizer.ParseEmail("Jane Doe <JANE@Example.com>")
+	require.NoError(t, err)
+	lower, err := normalizer.ParseEmail("jane@example.com")
+	require.NoError(t, err)
+
+	// Both are keyed alike, while each is delivered to as given
+	assert.Equal(t, lower.Canonical, upper.Canonical)
+	assert.Equal(t, "JANE@example.com", upper.Address)
+}
+
+func TestNormalizer_ParseEmail_Invalid(t *testing.T) {
+	normalizer := NewNormalizer(DefaultRegion)
+
+	for _, input := range []string{"", "not an email", "jane@", "@example.com", "jane@localhost", "jane@example.", "a@example.com, b@example.com", "+12016661234"} {
+		t.Run(input, func(t *testing.T) {
+			_, err := normalizer.ParseEmail(input)
+			assert.True(t, errors.Is(err, ErrInvalidEmailAddress))
+		})
+	}
+}
+
+func TestNormalizer_ParseEmail_Canonicalize(t *testing.T) {
+	normalizer := NewNormalizerWithConfig(Config{DefaultRegion: DefaultRegion, CanonicalizeEmail: true})
+
+	tests := []struct {
+		input     string
+		address   string
+		canonical string
+	}{
+		{"Jane.Doe+news@Gmail.com", "Jane.Doe+news@gmail.com", "janedoe@gmail.com"},
+		{"jane.doe@googlemail.com", "jane.doe@googlemail.com", "janedoe@gmail.com"},
+		{"jane.doe+receipts@outlook.com", "jane.doe+receipts@outlook.com", "jane.doe@outlook.com"},
+		{"jane+work@icloud.com", "jane+work@icloud.com", "jane@icloud.com"},
+		{"jane.doe+news@example.com", "jane.doe+news@example.com", "jane.doe+news@example.com"},
+		{"Jane.Doe@Example.com", "Jane.Doe@example.com", "jane.doe@example.com"},
+		{"+tag@gmail.com", "+tag@gmail.com", "+tag@gmail.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			parsed, err := normalizer.ParseEmail(tt.input)
+			require.NoError(t, err)
+			// Aliases are folded for keying only, the address is delivered to as given
+			assert.Equal(t, tt.address, parsed.Address)
+			assert.Equal(t, tt.canonical, parsed.Canonical)
+		})
+	}
+}
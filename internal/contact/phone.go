@@ -0,0 +1,85 @@
+// Package contact parses and canonicalizes the addresses messages are exchanged between,
+// so that the same customer always maps to the same conversation.
+package contact
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nyaruka/phonenumbers"
+)
+
+// DefaultRegion is the region assumed for phone numbers written without a country code
+const DefaultRegion = "US"
+
+// ErrInvalidPhoneNumber is returned for phone numbers that cannot be dialed
+var ErrInvalidPhoneNumber = errors.New("invalid phone number")
+
+// Config holds the normalizer configuration
+type Config struct {
+	// DefaultRegion is the ISO 3166-1 alpha-2 region, such as "US", of numbers without a country code
+	DefaultRegion string
+	// CanonicalizeEmail folds provider-specific aliases of a mailbox, such as plus-addressed
+	// Gmail addresses, into the mailbox's address
+	CanonicalizeEmail bool
+}
+
+// Normalizer canonicalizes contact addresses
+type Normalizer struct {
+	defaultRegion     string
+	canonicalizeEmail bool
+}
+
+// NewNormalizer creates a normalizer that reads numbers without a country code as numbers of
+// defaultRegion, an ISO 3166-1 alpha-2 code such as "US"
+func NewNormalizer(defaultRegion string) *Normalizer {
+	return NewNormalizerWithConfig(Config{DefaultRegion: defaultRegion})
+}
+
+// NewNormalizerWithConfig creates a normalizer with custom configuration
+func NewNormalizerWithConfig(config Config) *Normalizer {
+	return &Normalizer{
+		defaultRegion:     strings.ToUpper(config.DefaultRegion),
+		canonicalizeEmail: config.CanonicalizeEmail,
+	}
+}
+
+// IsSupportedRegion reports whether region is a known ISO 3166-1 alpha-2 region code
+func IsSupportedRegion(region string) bool {
+	_, ok := phonenumbers.GetSupportedRegions()[strings.ToUpper(region)]
+	return ok
+}
+
+// NormalizePhone returns the E.164 form of a phone number, so "+1 (201) 666-1234",
+// "201-666-1234" and "12016661234" all become "+12016661234". Short codes have no
+// E.164 form and are returned as digits.
+func (n *Normalizer) NormalizePhone(raw string) (string, error) {
+	number := strings.TrimSpace(raw)
+	if IsShortCode(number) {
+		return number, nil
+	}
+
+	parsed, err := phonenumbers.Parse(number, n.defaultRegion)
+	if err != nil {
+		return "", fmt.Errorf("%w %q: %v", ErrInvalidPhoneNumber, raw, err)
+	}
+	if !phonenumbers.IsValidNumber(parsed) {
+		return "", fmt.Errorf("%w %q", ErrInvalidPhoneNumber, raw)
+	}
+
+	return phonenumbers.Format(parsed, phonenumbers.E164), nil
+}
+
+// IsShortCode reports whether number is a 5-6 digit short code without a country code
+func IsShortCode(number string) bool {
+	if len(number) < 5 || len(number) > 6 {
+		return false
+	}
+	for _, r := range number {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+package contact
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizer_NormalizePhone(t *testing.T) {
+	normalizer := NewNormalizer(DefaultRegion)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"E.164", "+12016661234", "+12016661234"},
+		{"formatted with country code", "+1 (201) 666-1234", "+12016661234"},
+		{"national number", "2016661234", "+12016661234"},
+		{"national number with dashes", "201-666-1234", "+12016661234"},
+		{"national number with parentheses", "(201) 666-1234", "+12016661234"},
+		{"trunk prefix", "1 201 666 1234", "+12016661234"},
+		{"surrounding whitespace", "  +12016661234 ", "+12016661234"},
+		{"toll free", "1-800-555-0000", "+18005550000"},
+		{"other country", "+44 7911 123456", "+447911123456"},
+		{"short code", "12345", "12345"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalized, err := normalizer.NormalizePhone(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, normalized)
+		})
+	}
+}
+
+func TestNormalizer_NormalizePhone_Invalid(t *testing.T) {
+	normalizer := NewNormalizer(DefaultRegion)
+
+	for _, input := range []string{"", "not a number", "1234567", "+1 555 123 4567", "+0987654321", "user@example.com"} {
+		t.Run(input, func(t *testing.T) {
+			_, err := normalizer.NormalizePhone(input)
+			assert.True(t, errors.Is(err, ErrInvalidPhoneNumber))
+		})
+	}
+}
+
+func TestNormalizer_DefaultRegion(t *testing.T) {
+	normalized, err := NewNormalizer("gb").NormalizePhone("07911 123456")
+	require.NoError(t, err)
+	assert.Equal(t, "+447911123456", normalized)
+
+	// Numbers with a country code do not depend on the default region
+	normalized, err = NewNormalizer("GB").NormalizePhone("+1 201 666 1234")
+	require.NoError(t, err)
+	assert.Equal(t, "+12016661234", normalized)
+}
+
+func TestIsSupportedRegion(t *testing.T) {
+	assert.True(t, IsSupportedRegion("US"))
+	assert.True(t, IsSupportedRegion("gb"))
+	assert.False(t, IsSupportedRegion("XX"))
+	assert.False(t, IsSupportedRegion(""))
+}
@@ -0,0 +1,75 @@
+package contact
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BusinessRegistry holds the phone numbers, email domains and email addresses the business owns
+type BusinessRegistry struct {
+	normalizer     *Normalizer
+	phones         map[string]bool
+	emailDomains   map[string]bool
+	emailAddresses map[string]bool
+}
+
+// NewBusinessRegistry creates a registry of business identities, canonicalizing the phone
+// numbers and email addresses with normalizer
+func NewBusinessRegistry(normalizer *Normalizer, phones, emailDomains, emailAddresses []string) (*BusinessRegistry, error) {
+	registry := &BusinessRegistry{
+		normalizer:     normalizer,
+		phones:         make(map[string]bool),
+		emailDomains:   make(map[string]bool),
+		emailAddresses: make(map[string]bool),
+	}
+
+	for _, phone := range phones {
+		normalized, err := normalizer.NormalizePhone(phone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid business phone number: %w", err)
+		}
+		registry.phones[normalized] = true
+	}
+
+	for _, domain := range emailDomains {
+		domain = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(domain), "@"))
+		if _, err := normalizer.ParseEmail("postmaster@" + domain); err != nil {
+			return nil, fmt.Errorf("invalid business email domain %q: %w", domain, err)
+		}
+		registry.emailDomains[domain] = true
+	}
+
+	for _, address := range emailAddresses {
+		parsed, err := normalizer.ParseEmail(address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid business email address: %w", err)
+		}
+		registry.emailAddresses[parsed.Canonical] = true
+	}
+
+	return registry, nil
+}
+
+// IsEmpty reports whether no business identities are registered
+func (r *BusinessRegistry) IsEmpty() bool {
+	return len(r.phones) == 0 && len(r.emailDomains) == 0 && len(r.emailAddresses) == 0
+}
+
+// Owns reports whether address is one of the business's identities. Addresses are compared in
+// canonical form, and an email address is owned when either it or its domain is registered.
+func (r *BusinessRegistry) Owns(address string) bool {
+	if strings.Contains(address, "@") {
+		parsed, err := r.normalizer.ParseEmail(address)
+		if err != nil {
+			return false
+		}
+		domain := parsed.Canonical[strings.LastIndex(parsed.Canonical, "@")+1:]
+		return r.emailAddresses[parsed.Canonical] || r.emailDomains[domain]
+	}
+
+	normalized, err := r.normalizer.NormalizePhone(address)
+	if err != nil {
+		return false
+	}
+	return r.phones[normalized]
+}
@@ -0,0 +1,47 @@
+package contact
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBusinessRegistry_Owns(t *testing.T) {
+	registry, err := NewBusinessRegistry(
+		NewNormalizer(DefaultRegion),
+		[]string{"(804) 555-1234", "12345"},
+		[]string{"@UseHatchApp.com"},
+		[]string{"Support <support@gmail.com>"},
+	)
+	require.NoError(t, err)
+	assert.False(t, registry.IsEmpty())
+
+	owned := []string{"+18045551234", "804-555-1234", "12345", "user@usehatchapp.com", "Hatch <Sales@UseHatchApp.com>", "support@gmail.com"}
+	for _, address := range owned {
+		assert.True(t, registry.Owns(address), address)
+	}
+
+	notOwned := []string{"+12016661234", "54321", "contact@gmail.com", "user@hatchapp.com", "not an address", ""}
+	for _, address := range notOwned {
+		assert.False(t, registry.Owns(address), address)
+	}
+}
+
+func TestNewBusinessRegistry_Invalid(t *testing.T) {
+	normalizer := NewNormalizer(DefaultRegion)
+
+	_, err := NewBusinessRegistry(normalizer, []string{"555-1234"}, nil, nil)
+	assert.True(t, errors.Is(err, ErrInvalidPhoneNumber))
+
+	_, err = NewBusinessRegistry(normalizer, nil, []string{"localhost"}, nil)
+	assert.True(t, errors.Is(err, ErrInvalidEmailAddress))
+
+	_, err = NewBusinessRegistry(normalizer, nil, nil, []string{"support"})
+	assert.True(t, errors.Is(err, ErrInvalidEmailAddress))
+
+	registry, err := NewBusinessRegistry(normalizer, nil, nil, nil)
+	require.NoError(t, err)
+	assert.True(t, registry.IsEmpty())
+}
@@ -2,12 +2,16 @@ package container
 
 import (
 	"database/sql"
+	"fmt"
 
 	"messaging-service/internal/config"
 	"messaging-service/internal/domain"
 	"messaging-service/internal/handler"
+	"messaging-service/internal/logger"
 	"messaging-service/internal/provider"
 	"messaging-service/internal/repository/postgres"
+	"messaging-service/internal/repository/sqlite"
+	"messaging-service/internal/retry"
 	"messaging-service/internal/service"
 )
 
@@ -17,50 +21,254 @@ type Container struct {
 	DB                  *sql.DB
 	ConversationRepo    domain.ConversationRepository
 	MessageRepo         domain.MessageRepository
+	ContactRepo         domain.ContactRepository
+	OutboxRepo          domain.OutboxRepository
+	IdempotencyRepo     domain.IdempotencyRepository
+	Transactor          domain.Transactor
 	SMSProvider         domain.SMSProvider
 	EmailProvider       domain.EmailProvider
+	CircuitBreakers     *provider.CircuitBreakerRegistry
+	RateLimiter         *provider.RateLimiter
 	MessagingService    domain.MessagingService
 	ConversationService domain.ConversationService
+	ContactService      domain.ContactService
 	MessagingHandler    *handler.MessagingHandler
+	ContactHandler      *handler.ContactHandler
+	HealthHandler       *handler.HealthHandler
+	OutboxDispatcher    *service.OutboxDispatcher
+	Scheduler           *service.Scheduler
+	IdempotencyCleaner  *service.IdempotencyCleaner
+	RoleBackfill        *service.ConversationRoleBackfill
 }
 
 // NewContainer creates a new dependency injection container
-func NewContainer(cfg *config.Config, db *sql.DB) *Container {
+func NewContainer(cfg *config.Config, db *sql.DB) (*Container, error) {
 	container := &Container{
 		Config: cfg,
 		DB:     db,
 	}
 
-	// Initialize repositories
-	container.ConversationRepo = postgres.NewConversationRepository(db)
-	container.MessageRepo = postgres.NewMessageRepository(db)
+	// Initialize repositories for the configured database driver
+	if cfg.Database.IsSQLite() {
+		container.ConversationRepo = sqlite.NewConversationRepository(db)
+		container.MessageRepo = sqlite.NewMessageRepository(db)
+		container.ContactRepo = sqlite.NewContactRepository(db)
+		container.OutboxRepo = sqlite.NewOutboxRepository(db)
+		container.IdempotencyRepo = sqlite.NewIdempotencyRepository(db)
+		container.Transactor = sqlite.NewTransactor(db)
+	} else {
+		container.ConversationRepo = postgres.NewConversationRepository(db)
+		container.MessageRepo = postgres.NewMessageRepository(db)
+		container.ContactRepo = postgres.NewContactRepository(db)
+		container.OutboxRepo = postgres.NewOutboxRepository(db)
+		container.IdempotencyRepo = postgres.NewIdempotencyRepository(db)
+		container.Transactor = postgres.NewTransactor(db)
+	}
 
 	// Initialize providers
-	container.SMSProvider = provider.NewMockSMSProvider()
-	container.EmailProvider = provider.NewEmailProvider(
-		provider.EmailProviderType(container.Config.Providers.EmailProviderType),
-		container.Config.Providers.EmailProviderConfig,
-	)
+	if err := container.initProviders(); err != nil {
+		return nil, fmt.Errorf("failed to initialize providers: %w", err)
+	}
 
 	// Initialize services
-	container.MessagingService = service.NewMessagingService(
+	container.MessagingService = service.NewMessagingServiceWithConfig(
 		container.ConversationRepo,
 		container.MessageRepo,
+		container.OutboxRepo,
+		container.Transactor,
 		container.SMSProvider,
 		container.EmailProvider,
+		retryPolicy(cfg.Retry),
+		cfg.Messaging.ContactNormalizer(),
 	)
 	container.ConversationService = service.NewConversationService(
 		container.ConversationRepo,
 		container.MessageRepo,
 	)
+	container.ContactService = service.NewContactService(
+		container.ContactRepo,
+		container.ConversationRepo,
+		container.MessageRepo,
+		container.Transactor,
+		cfg.Messaging.ContactNormalizer(),
+	)
+
+	// Initialize background workers
+	container.OutboxDispatcher = service.NewOutboxDispatcher(
+		container.OutboxRepo,
+		container.MessageRepo,
+		container.MessagingService,
+		service.OutboxDispatcherConfig{
+			PollInterval: cfg.Outbox.PollInterval,
+			BatchSize:    cfg.Outbox.BatchSize,
+			MaxAttempts:  cfg.Outbox.MaxAttempts,
+			RetryDelay:   cfg.Outbox.RetryDelay,
+		},
+		logger.Get(),
+	)
+	container.Scheduler = service.NewScheduler(
+		container.OutboxRepo,
+		service.SchedulerConfig{
+			PollInterval: cfg.Scheduler.PollInterval,
+			BatchSize:    cfg.Scheduler.BatchSize,
+		},
+		logger.Get(),
+	)
+	cleanerConfig := service.DefaultIdempotencyCleanerConfig()
+	cleanerConfig.Interval = cfg.Messaging.IdempotencyCleanupInterval
+	container.IdempotencyCleaner = service.NewIdempotencyCleaner(container.IdempotencyRepo, cleanerConfig, logger.Get())
+
+	// Initialize maintenance tasks
+	registry, err := cfg.Messaging.BusinessRegistry()
+	if err != nil {
+		return nil, fmt.Errorf("invalid business identities: %w", err)
+	}
+	container.RoleBackfill = service.NewConversationRoleBackfill(
+		container.ConversationRepo,
+		container.MessageRepo,
+		container.Transactor,
+		registry,
+		logger.Get(),
+	)
 
 	// Initialize handlers
-	container.MessagingHandler = handler.NewMessagingHandler(
+	container.MessagingHandler = handler.NewMessagingHandlerWithConfig(
 		container.MessagingService,
 		container.ConversationService,
+		handler.HandlerConfig{AsyncSend: cfg.Messaging.AsyncSend},
 	)
+	container.ContactHandler = handler.NewContactHandler(container.ContactService)
+	// Pass a nil reporter rather than a nil registry when circuit breakers are disabled
+	if container.CircuitBreakers != nil {
+		container.HealthHandler = handler.NewHealthHandler(container.CircuitBreakers)
+	} else {
+		container.HealthHandler = handler.NewHealthHandler(nil)
+	}
+
+	return container, nil
+}
+
+// initProviders creates the SMS and email providers, throttling each with the rate limiter
+// and guarding it with a circuit breaker when enabled, and wrapping them in a failover provider when more than one provider or
+// any routing rule is configured
+func (c *Container) initProviders() error {
+	providersConfig := c.Config.Providers
+
+	if breakerConfig := providersConfig.CircuitBreaker; breakerConfig.Enabled {
+		c.CircuitBreakers = provider.NewCircuitBreakerRegistry(provider.CircuitBreakerConfig{
+			Window:           breakerConfig.Window,
+			MinRequests:      breakerConfig.MinRequests,
+			FailureRate:      breakerConfig.FailureRate,
+			CoolDown:         breakerConfig.CoolDown,
+			HalfOpenRequests: breakerConfig.HalfOpenRequests,
+		})
+	}
 
-	return container
+	if limits := providersConfig.RateLimit; limits.Enabled {
+		c.RateLimiter = provider.NewRateLimiter(provider.RateLimitConfig{
+			LongCodeMPS:    limits.LongCodeMPS,
+			TollFreeMPS:    limits.TollFreeMPS,
+			ShortCodeMPS:   limits.ShortCodeMPS,
+			EmailPerSecond: limits.EmailPerSecond,
+		})
+	}
+
+	smsNames := providersConfig.SMSProviderNames()
+	if len(smsNames) == 1 && len(providersConfig.SMSRoutingRules) == 0 {
+		c.SMSProvider = c.newSMSProvider(smsNames[0])
+	} else {
+		smsProviders := make([]provider.NamedSMSProvider, 0, len(smsNames))
+		for _, name := range smsNames {
+			smsProviders = append(smsProviders, provider.NamedSMSProvider{
+				Name:     name,
+				Provider: c.newSMSProvider(name),
+			})
+		}
+		failover, err := provider.NewFailoverSMSProvider(smsProviders, routingRules(providersConfig.SMSRoutingRules))
+		if err != nil {
+			return fmt.Errorf("invalid SMS provider routing: %w", err)
+		}
+		c.SMSProvider = failover
+	}
+
+	emailNames := providersConfig.EmailProviderNames()
+	if len(emailNames) == 1 && len(providersConfig.EmailRoutingRules) == 0 {
+		c.EmailProvider = c.newEmailProvider(emailNames[0])
+	} else {
+		emailProviders := make([]provider.NamedEmailProvider, 0, len(emailNames))
+		for _, name := range emailNames {
+			emailProviders = append(emailProviders, provider.NamedEmailProvider{
+				Name:     name,
+				Provider: c.newEmailProvider(name),
+			})
+		}
+		failover, err := provider.NewFailoverEmailProvider(emailProviders, routingRules(providersConfig.EmailRoutingRules))
+		if err != nil {
+			return fmt.Errorf("invalid email provider routing: %w", err)
+		}
+		c.EmailProvider = failover
+	}
+
+	return nil
+}
+
+// newSMSProvider creates the named SMS provider behind its rate limiter and circuit breaker.
+// The breaker is outermost so an open circuit fails fast without consuming a token.
+func (c *Container) newSMSProvider(name string) domain.SMSProvider {
+	smsProvider := provider.NewSMSProvider(provider.SMSProviderType(name), c.Config.Providers.SMSProviderConfig)
+	if c.RateLimiter != nil {
+		smsProvider = provider.NewRateLimitedSMSProvider(smsProvider, name, c.RateLimiter)
+	}
+	if c.CircuitBreakers == nil {
+		return smsProvider
+	}
+	return provider.NewCircuitBreakerSMSProvider(smsProvider, c.CircuitBreakers.Breaker("sms:"+name))
+}
+
+// newEmailProvider creates the named email provider behind its rate limiter and circuit breaker
+func (c *Container) newEmailProvider(name string) domain.EmailProvider {
+	emailProvider := provider.NewEmailProvider(provider.EmailProviderType(name), c.Config.Providers.EmailProviderConfig)
+	if c.RateLimiter != nil {
+		emailProvider = provider.NewRateLimitedEmailProvider(emailProvider, name, c.RateLimiter)
+	}
+	if c.CircuitBreakers == nil {
+		return emailProvider
+	}
+	return provider.NewCircuitBreakerEmailProvider(emailProvider, c.CircuitBreakers.Breaker("email:"+name))
+}
+
+// retryPolicy converts the configured retry settings into a retry policy
+func retryPolicy(cfg config.RetryConfig) retry.Policy {
+	policy := retry.Policy{
+		MaxRetries: cfg.MaxRetries,
+		BaseDelay:  cfg.BaseDelay,
+		MaxDelay:   cfg.MaxDelay,
+		Multiplier: cfg.Multiplier,
+		Jitter:     retry.Jitter(cfg.Jitter),
+		Budget:     cfg.Budget,
+	}
+	if len(cfg.StatusClasses) > 0 {
+		policy.StatusClasses = make(map[int]retry.Class, len(cfg.StatusClasses))
+		for code, name := range cfg.StatusClasses {
+			// Class names are checked when the configuration is loaded
+			class, _ := retry.ParseClass(name)
+			policy.StatusClasses[code] = class
+		}
+	}
+	return policy
+}
+
+// routingRules converts configured routing rules into provider routing rules
+func routingRules(rules []config.RoutingRule) []provider.RoutingRule {
+	converted := make([]provider.RoutingRule, 0, len(rules))
+	for _, rule := range rules {
+		converted = append(converted, provider.RoutingRule{
+			Field:    rule.Field,
+			Prefix:   rule.Prefix,
+			Provider: rule.Provider,
+		})
+	}
+	return converted
 }
 
 // Close closes all resources in the container
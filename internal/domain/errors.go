@@ -0,0 +1,15 @@
+package domain
+
+import "errors"
+
+// ErrNotFound is returned when a requested resource does not exist
+var ErrNotFound = errors.New("not found")
+
+// ErrConflict is returned when a write conflicts with an existing resource
+var ErrConflict = errors.New("conflict")
+
+// ErrCircuitOpen is returned when a provider's circuit breaker is open and calls fail fast
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// ErrInvalidState is returned when an operation does not apply to a resource in its current state
+var ErrInvalidState = errors.New("invalid state")
@@ -15,32 +15,152 @@ const (
 	MessageTypeEmail = "email"
 )
 
+// Message statuses
+const (
+	MessageStatusScheduled = "scheduled"
+	MessageStatusPending   = "pending"
+	MessageStatusSent      = "sent"
+	MessageStatusDelivered = "delivered"
+	MessageStatusFailed    = "failed"
+	MessageStatusBounced   = "bounced"
+	MessageStatusCancelled = "cancelled"
+)
+
+// Message directions, seen from the business
+const (
+	MessageDirectionInbound  = "inbound"
+	MessageDirectionOutbound = "outbound"
+)
+
+// IsValidMessageDirection reports whether direction is inbound or outbound
+func IsValidMessageDirection(direction string) bool {
+	return direction == MessageDirectionInbound || direction == MessageDirectionOutbound
+}
+
+// Contact identity types
+const (
+	ContactIdentityTypePhone = "phone"
+	ContactIdentityTypeEmail = "email"
+)
+
 // Message represents a message in the system
 type Message struct {
-	ID                  int       `json:"id" db:"id"`
-	ConversationID      int       `json:"conversation_id" db:"conversation_id"`
-	From                string    `json:"from" db:"from_address"`
-	To                  string    `json:"to" db:"to_address"`
-	Type                string    `json:"type" db:"message_type"`
-	Body                string    `json:"body" db:"body"`
-	Attachments         []string  `json:"attachments" db:"attachments"`
-	Status              string    `json:"status" db:"status"`
-	ErrorCode           *string   `json:"error_code,omitempty" db:"error_code"`
-	ErrorMessage        *string   `json:"error_message,omitempty" db:"error_message"`
-	Timestamp           time.Time `json:"timestamp" db:"timestamp"`
-	MessagingProviderID *string   `json:"messaging_provider_id,omitempty" db:"provider_message_id"`
-	CreatedAt           time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt           time.Time `json:"updated_at" db:"updated_at"`
+	ID                  int        `json:"id" db:"id"`
+	ConversationID      int        `json:"conversation_id" db:"conversation_id"`
+	From                string     `json:"from" db:"from_address"`
+	To                  string     `json:"to" db:"to_address"`
+	FromName            *string    `json:"from_name,omitempty" db:"from_name"`
+	ToName              *string    `json:"to_name,omitempty" db:"to_name"`
+	Type                string     `json:"type" db:"message_type"`
+	Direction           string     `json:"direction" db:"direction"`
+	Body                string     `json:"body" db:"body"`
+	Attachments         []string   `json:"attachments" db:"attachments"`
+	Status              string     `json:"status" db:"status"`
+	ErrorCode           *string    `json:"error_code,omitempty" db:"error_code"`
+	ErrorMessage        *string    `json:"error_message,omitempty" db:"error_message"`
+	Timestamp           time.Time  `json:"timestamp" db:"timestamp"`
+	MessagingProviderID *string    `json:"messaging_provider_id,omitempty" db:"provider_message_id"`
+	Provider            *string    `json:"provider,omitempty" db:"provider"`
+	SegmentCount        *int       `json:"segment_count,omitempty" db:"segment_count"`
+	SentAt              *time.Time `json:"sent_at,omitempty" db:"sent_at"`
+	SendAt              *time.Time `json:"send_at,omitempty" db:"send_at"`
+	// FailedAt is when the message was updated to failed, and is nil unless it is failed
+	FailedAt  *time.Time `json:"failed_at,omitempty" db:"failed_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// MessageEvent represents a status transition of a message
+type MessageEvent struct {
+	ID           int       `json:"id" db:"id"`
+	MessageID    int       `json:"message_id" db:"message_id"`
+	Status       string    `json:"status" db:"status"`
+	ErrorCode    *string   `json:"error_code,omitempty" db:"error_code"`
+	ErrorMessage *string   `json:"error_message,omitempty" db:"error_message"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// MessageAttempt records one provider call made while delivering a message
+type MessageAttempt struct {
+	ID            int       `json:"id" db:"id"`
+	MessageID     int       `json:"message_id" db:"message_id"`
+	AttemptNumber int       `json:"attempt_number" db:"attempt_number"`
+	Succeeded     bool      `json:"succeeded" db:"succeeded"`
+	Provider      *string   `json:"provider,omitempty" db:"provider"`
+	ErrorClass    *string   `json:"error_class,omitempty" db:"error_class"`
+	ErrorCode     *string   `json:"error_code,omitempty" db:"error_code"`
+	ErrorMessage  *string   `json:"error_message,omitempty" db:"error_message"`
+	DurationMs    int64     `json:"duration_ms" db:"duration_ms"`
+	RetryDelayMs  int64     `json:"retry_delay_ms" db:"retry_delay_ms"`
+	StartedAt     time.Time `json:"started_at" db:"started_at"`
 }
 
 // Conversation represents a conversation between participants
 type Conversation struct {
-	ID              int       `json:"id" db:"id"`
-	CustomerContact string    `json:"customer_contact" db:"customer_contact"`
-	BusinessContact string    `json:"business_contact" db:"business_contact"`
-	CreatedAt       time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
-	Messages        []Message `json:"messages,omitempty"`
+	ID              int    `json:"id" db:"id"`
+	CustomerContact string `json:"customer_contact" db:"customer_contact"`
+	BusinessContact string `json:"business_contact" db:"business_contact"`
+	// LastMessageAt, LastMessagePreview and LastMessageDirection describe the most recent
+	// message, nil until one is recorded
+	LastMessageAt        *time.Time `json:"last_message_at,omitempty" db:"last_message_at"`
+	LastMessagePreview   *string    `json:"last_message_preview,omitempty" db:"last_message_preview"`
+	LastMessageDirection *string    `json:"last_message_direction,omitempty" db:"last_message_direction"`
+	CreatedAt            time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at" db:"updated_at"`
+	Messages             []Message  `json:"messages,omitempty"`
+}
+
+// Contact represents a customer who reaches the business through one or more identities
+type Contact struct {
+	ID         int               `json:"id" db:"id"`
+	Name       *string           `json:"name,omitempty" db:"name"`
+	Identities []ContactIdentity `json:"identities"`
+	CreatedAt  time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// Addresses returns the addresses of the contact's identities
+func (c *Contact) Addresses() []string {
+	addresses := make([]string, 0, len(c.Identities))
+	for _, identity := range c.Identities {
+		addresses = append(addresses, identity.Address)
+	}
+	return addresses
+}
+
+// ContactIdentity is a canonical phone number or email address of a contact. An address
+// belongs to at most one contact.
+type ContactIdentity struct {
+	ID        int       `json:"id" db:"id"`
+	ContactID int       `json:"contact_id" db:"contact_id"`
+	Type      string    `json:"type" db:"type"`
+	Address   string    `json:"address" db:"address"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// OutboxEntry represents a queued delivery of a persisted outbound message
+type OutboxEntry struct {
+	ID          int       `json:"id" db:"id"`
+	MessageID   int       `json:"message_id" db:"message_id"`
+	Attempts    int       `json:"attempts" db:"attempts"`
+	AvailableAt time.Time `json:"available_at" db:"available_at"`
+	LastError   *string   `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// IdempotencyRecord stores the response to a request made with an Idempotency-Key
+type IdempotencyRecord struct {
+	Key string `json:"key" db:"key"`
+	// Fingerprint identifies the request the key was first used with
+	Fingerprint string `json:"fingerprint" db:"fingerprint"`
+	// StatusCode is nil while the first request is still being processed
+	StatusCode   *int   `json:"status_code,omitempty" db:"status_code"`
+	ResponseBody []byte `json:"response_body,omitempty" db:"response_body"`
+	// LockedUntil is when a request still being processed is considered abandoned
+	LockedUntil time.Time `json:"locked_until" db:"locked_until"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at" db:"expires_at"`
 }
 
 // OutboundSMSRequest represents a request to send an SMS/MMS
@@ -83,6 +203,22 @@ type InboundEmailWebhook struct {
 	Timestamp   time.Time `json:"timestamp,omitempty"`
 }
 
+// MessageStatusWebhook represents a delivery status callback for an outbound SMS/MMS
+type MessageStatusWebhook struct {
+	MessagingProviderID string `json:"messaging_provider_id" binding:"required"`
+	Status              string `json:"status" binding:"required,oneof=sent delivered failed bounced"`
+	ErrorCode           string `json:"error_code"`
+	ErrorMessage        string `json:"error_message"`
+}
+
+// EmailStatusWebhook represents a delivery status callback for an outbound email
+type EmailStatusWebhook struct {
+	XillioID     string `json:"xillio_id" binding:"required"`
+	Status       string `json:"status" binding:"required,oneof=sent delivered failed bounced"`
+	ErrorCode    string `json:"error_code"`
+	ErrorMessage string `json:"error_message"`
+}
+
 // API Response Types
 
 // SendSMSRequest represents a request to send an SMS/MMS
@@ -93,11 +229,17 @@ type SendSMSRequest struct {
 	Body        string    `json:"body" binding:"required"`
 	Attachments []string  `json:"attachments"`
 	Timestamp   time.Time `json:"timestamp,omitempty"`
+	// SendAt schedules the message for later delivery; omitted or past times send immediately
+	SendAt *time.Time `json:"send_at,omitempty"`
 }
 
 // SendSMSResponse represents the response for sending an SMS/MMS
 type SendSMSResponse struct {
-	Message string `json:"message"`
+	Message        string `json:"message"`
+	MessageID      int    `json:"message_id"`
+	ConversationID int    `json:"conversation_id"`
+	Status         string `json:"status"`
+	StatusURL      string `json:"status_url"`
 }
 
 // SendEmailRequest represents a request to send an email
@@ -107,11 +249,17 @@ type SendEmailRequest struct {
 	Body        string    `json:"body" binding:"required"`
 	Attachments []string  `json:"attachments"`
 	Timestamp   time.Time `json:"timestamp,omitempty"`
+	// SendAt schedules the email for later delivery; omitted or past times send immediately
+	SendAt *time.Time `json:"send_at,omitempty"`
 }
 
 // SendEmailResponse represents the response for sending an email
 type SendEmailResponse struct {
-	Message string `json:"message"`
+	Message        string `json:"message"`
+	MessageID      int    `json:"message_id"`
+	ConversationID int    `json:"conversation_id"`
+	Status         string `json:"status"`
+	StatusURL      string `json:"status_url"`
 }
 
 // WebhookResponse represents the response for webhook processing
@@ -127,6 +275,8 @@ type ConversationQuery struct {
 	From            time.Time `form:"from"`
 	To              time.Time `form:"to"`
 	MessageType     string    `form:"message_type"`
+	Direction       string    `form:"direction"`      // Filter by the direction of the last message
+	AwaitingReply   bool      `form:"awaiting_reply"` // Only conversations whose last message is inbound
 	Limit           int       `form:"limit,default=50"`
 	Offset          int       `form:"offset,default=0"`
 	SortBy          string    `form:"sort_by,default=updated_at"`
@@ -134,6 +284,47 @@ type ConversationQuery struct {
 	IncludeMessages bool      `form:"include_messages,default=false"`
 }
 
+// DeadLetterQuery represents query parameters for listing failed outbound messages
+type DeadLetterQuery struct {
+	Provider  string    `form:"provider"`
+	ErrorCode string    `form:"error_code"`
+	From      time.Time `form:"from"` // Failed at or after
+	To        time.Time `form:"to"`   // Failed before
+	Limit     int       `form:"limit,default=50"`
+	Offset    int       `form:"offset,default=0"`
+}
+
+// GetDeadLettersResponse represents the response for listing failed outbound messages
+type GetDeadLettersResponse struct {
+	Messages []Message `json:"messages"`
+	Total    int       `json:"total"`
+	Page     int       `json:"page"`
+	PerPage  int       `json:"per_page"`
+	HasMore  bool      `json:"has_more"`
+}
+
+// RescheduleMessageRequest represents a request to change when a scheduled message is sent
+type RescheduleMessageRequest struct {
+	SendAt time.Time `json:"send_at" binding:"required"`
+}
+
+// ReplayDeadLettersRequest represents a request to resend failed messages
+type ReplayDeadLettersRequest struct {
+	MessageIDs []int `json:"message_ids" binding:"required,min=1,max=100"`
+}
+
+// ReplayFailure describes a message that could not be replayed
+type ReplayFailure struct {
+	MessageID int    `json:"message_id"`
+	Error     string `json:"error"`
+}
+
+// ReplayDeadLettersResponse represents the response for replaying failed messages
+type ReplayDeadLettersResponse struct {
+	Replayed []Message       `json:"replayed"`
+	Failed   []ReplayFailure `json:"failed"`
+}
+
 // GetConversationsResponse represents the response for getting conversations
 type GetConversationsResponse struct {
 	Conversations []Conversation `json:"conversations"`
@@ -148,6 +339,51 @@ type GetConversationMessagesResponse struct {
 	Messages []Message `json:"messages"`
 }
 
+// CreateContactRequest represents a request to create a contact from its identities
+type CreateContactRequest struct {
+	Name   *string  `json:"name,omitempty"`
+	Phones []string `json:"phones,omitempty"`
+	Emails []string `json:"emails,omitempty"`
+}
+
+// MergeContactsRequest represents a request to merge another contact into a contact
+type MergeContactsRequest struct {
+	ContactID int `json:"contact_id" binding:"required"`
+}
+
+// SplitContactRequest represents a request to move identities of a contact to a new contact
+type SplitContactRequest struct {
+	Name      *string  `json:"name,omitempty"`
+	Addresses []string `json:"addresses" binding:"required,min=1"`
+}
+
+// ContactTimelineQuery represents query parameters for getting a contact's timeline
+type ContactTimelineQuery struct {
+	Business string `form:"business"` // Filter by the business phone number or email address
+	Limit    int    `form:"limit,default=50"`
+	Offset   int    `form:"offset,default=0"`
+}
+
+// GetContactTimelineResponse represents the messages exchanged with a contact across all channels
+type GetContactTimelineResponse struct {
+	Contact  Contact   `json:"contact"`
+	Messages []Message `json:"messages"`
+	Total    int       `json:"total"`
+	Page     int       `json:"page"`
+	PerPage  int       `json:"per_page"`
+	HasMore  bool      `json:"has_more"`
+}
+
+// GetMessageEventsResponse represents the response for getting a message's status history
+type GetMessageEventsResponse struct {
+	Events []MessageEvent `json:"events"`
+}
+
+// GetMessageAttemptsResponse represents the response for getting a message's delivery attempts
+type GetMessageAttemptsResponse struct {
+	Attempts []MessageAttempt `json:"attempts"`
+}
+
 // ErrorResponse represents an error response
 type ErrorResponse struct {
 	Error string `json:"error"`
@@ -158,15 +394,37 @@ type ProviderError struct {
 	Code       int    `json:"code"`
 	Message    string `json:"message"`
 	RetryAfter int    `json:"retry_after,omitempty"` // seconds
+	// Provider names the provider that returned the error, when known
+	Provider string `json:"provider,omitempty"`
 }
 
 func (e *ProviderError) Error() string {
 	return fmt.Sprintf("provider error %d: %s", e.Code, e.Message)
 }
 
+// messageStatusTransitions lists the statuses each status may move to.
+// Statuses only move forward so late or duplicate receipts cannot regress a message.
+var messageStatusTransitions = map[string][]string{
+	MessageStatusScheduled: {MessageStatusPending, MessageStatusCancelled},
+	MessageStatusPending:   {MessageStatusSent, MessageStatusDelivered, MessageStatusFailed, MessageStatusBounced},
+	MessageStatusSent:      {MessageStatusDelivered, MessageStatusFailed, MessageStatusBounced},
+	MessageStatusDelivered: {MessageStatusBounced}, // Emails can bounce after the receiving server accepted them
+}
+
+// CanTransitionStatus reports whether a message may move from one status to another
+func CanTransitionStatus(from, to string) bool {
+	for _, allowed := range messageStatusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
 // IsRetryableError checks if the error is retryable (429, 500, 502, 503, 504)
 func IsRetryableError(err error) bool {
-	if providerErr, ok := err.(*ProviderError); ok {
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) {
 		return providerErr.Code == 429 || providerErr.Code == 500 ||
 			providerErr.Code == 502 || providerErr.Code == 503 || providerErr.Code == 504
 	}
@@ -175,7 +433,8 @@ func IsRetryableError(err error) bool {
 
 // GetRetryAfterSeconds returns the retry after duration for rate limit errors
 func GetRetryAfterSeconds(err error) int {
-	if providerErr, ok := err.(*ProviderError); ok && providerErr.Code == 429 {
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) && providerErr.Code == 429 {
 		return providerErr.RetryAfter
 	}
 	return 0
@@ -1,14 +1,25 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
+
+// SendResult describes a message accepted by a provider
+type SendResult struct {
+	ProviderMessageID string    `json:"provider_message_id"`
+	AcceptedAt        time.Time `json:"accepted_at"`
+	Segments          int       `json:"segments"`
+	Provider          string    `json:"provider"`
+}
 
 // SMSProvider defines the interface for SMS/MMS providers
 type SMSProvider interface {
-	SendSMS(ctx context.Context, from, to, body string) error
-	SendMMS(ctx context.Context, from, to, body string, attachments []string) error
+	SendSMS(ctx context.Context, from, to, body string) (*SendResult, error)
+	SendMMS(ctx context.Context, from, to, body string, attachments []string) (*SendResult, error)
 }
 
 // EmailProvider defines the interface for email providers
 type EmailProvider interface {
-	SendEmail(ctx context.Context, from, to, body string, attachments []string) error
+	SendEmail(ctx context.Context, from, to, body string, attachments []string) (*SendResult, error)
 }
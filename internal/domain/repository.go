@@ -1,21 +1,114 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
-// ConversationRepository defines the interface for conversation data access
+// Transactor runs multi-repository operations atomically. Repository calls made with the
+// context passed to fn take part in the transaction, which is committed when fn returns nil
+// and rolled back otherwise. Nested calls join the outer transaction.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// ConversationRepository defines the interface for conversation data access.
+// Lookups return ErrNotFound when no conversation matches.
 type ConversationRepository interface {
+	// Create returns ErrConflict when a conversation between the contacts already exists
 	Create(ctx context.Context, customerContact, businessContact string) (*Conversation, error)
 	GetByID(ctx context.Context, id int) (*Conversation, error)
+	// GetByContacts returns the conversation between the customer and business contacts in that role
 	GetByContacts(ctx context.Context, customerContact, businessContact string) (*Conversation, error)
+	// GetOrCreate atomically returns the conversation between the contacts, creating it if needed
 	GetOrCreate(ctx context.Context, customerContact, businessContact string) (*Conversation, error)
 	List(ctx context.Context, query *ConversationQuery) ([]Conversation, int, error)
+	// RecordMessage bumps the conversation's updated_at, and its last message time, preview and
+	// direction unless a later message has already been recorded
+	RecordMessage(ctx context.Context, conversationID int, at time.Time, preview, direction string) error
+	// SwapContacts exchanges the customer and business contacts of a conversation. It returns
+	// ErrConflict when a conversation with the exchanged contacts already exists.
+	SwapContacts(ctx context.Context, id int) (*Conversation, error)
+	// Delete removes a conversation together with any messages still in it
+	Delete(ctx context.Context, id int) error
+	// ListByCustomerContacts returns the conversations with any of the customer contacts,
+	// only those with businessContact unless it is empty
+	ListByCustomerContacts(ctx context.Context, customerContacts []string, businessContact string) ([]Conversation, error)
+}
+
+// ContactRepository defines the interface for contact data access.
+// Lookups return ErrNotFound when no contact matches.
+type ContactRepository interface {
+	// Create stores the contact with its identities. It returns ErrConflict when one of the
+	// identities already belongs to a contact.
+	Create(ctx context.Context, contact *Contact) error
+	GetByID(ctx context.Context, id int) (*Contact, error)
+	// GetByAddress returns the contact with an identity of the address
+	GetByAddress(ctx context.Context, address string) (*Contact, error)
+	// MoveIdentities moves the identities of one contact with the given addresses to another,
+	// returning the number moved
+	MoveIdentities(ctx context.Context, fromContactID, toContactID int, addresses []string) (int, error)
+	// Delete removes a contact together with any identities still attached to it
+	Delete(ctx context.Context, id int) error
 }
 
-// MessageRepository defines the interface for message data access
+// MessageRepository defines the interface for message data access.
+// Lookups return ErrNotFound when no message matches.
 type MessageRepository interface {
+	// Create returns ErrConflict when a message with the same provider message ID exists
 	Create(ctx context.Context, message *Message) error
 	GetByID(ctx context.Context, id int) (*Message, error)
 	GetByConversationID(ctx context.Context, conversationID int) ([]Message, error)
+	// ListByConversationIDs returns a page of the messages of all the conversations in timestamp
+	// order, and the total count
+	ListByConversationIDs(ctx context.Context, conversationIDs []int, limit, offset int) ([]Message, int, error)
 	GetByProviderMessageID(ctx context.Context, providerMessageID string) (*Message, error)
+	// Update writes the message's delivery state: status, error, provider IDs, segment count and sent time
 	Update(ctx context.Context, message *Message) error
+	// UpdateIfStatus updates the message only while its stored status still equals expectedStatus
+	UpdateIfStatus(ctx context.Context, message *Message, expectedStatus string) (bool, error)
+	GetEvents(ctx context.Context, messageID int) ([]MessageEvent, error)
+	// RecordAttempt stores a delivery attempt, numbering it after the message's earlier attempts
+	RecordAttempt(ctx context.Context, attempt *MessageAttempt) error
+	GetAttempts(ctx context.Context, messageID int) ([]MessageAttempt, error)
+	// ListFailed returns failed messages matching the query, most recently failed first, and the total count
+	ListFailed(ctx context.Context, query *DeadLetterQuery) ([]Message, int, error)
+	// Reschedule changes the send time of a message only while it is still scheduled
+	Reschedule(ctx context.Context, id int, sendAt time.Time) (bool, error)
+	// MoveConversation moves all messages of one conversation to another, returning the number moved
+	MoveConversation(ctx context.Context, fromConversationID, toConversationID int) (int, error)
+}
+
+// OutboxRepository defines the interface for the outbound delivery outbox
+type OutboxRepository interface {
+	// Enqueue persists the message and its outbox entry in a single transaction.
+	// The entry becomes claimable by the dispatcher at availableAt.
+	Enqueue(ctx context.Context, message *Message, availableAt time.Time) (*OutboxEntry, error)
+	// ClaimDue leases up to limit due entries for leaseDuration and increments their attempt count
+	ClaimDue(ctx context.Context, limit int, leaseDuration time.Duration) ([]OutboxEntry, error)
+	// Release makes an entry claimable again at availableAt, recording the last error
+	Release(ctx context.Context, id int, availableAt time.Time, lastError string) error
+	Delete(ctx context.Context, id int) error
+	// Requeue moves a failed message back to pending, clearing its error, and creates an
+	// outbox entry for it in a single transaction. It returns ErrConflict when the message is not failed.
+	Requeue(ctx context.Context, message *Message, availableAt time.Time) (*OutboxEntry, error)
+	// EnqueueScheduled moves up to limit scheduled messages whose send time has passed to
+	// pending and creates their outbox entries, returning the number of messages moved
+	EnqueueScheduled(ctx context.Context, limit int) (int, error)
+}
+
+// IdempotencyRepository defines the interface for idempotency key storage
+type IdempotencyRepository interface {
+	// Reserve claims the key for a new request until expiresAt, which must complete before
+	// lockedUntil. When the key is held by an unexpired record that is completed or still
+	// locked, that record is returned instead and reserved is false.
+	Reserve(ctx context.Context, key, fingerprint string, lockedUntil, expiresAt time.Time) (record *IdempotencyRecord, reserved bool, err error)
+	// Complete stores the response of the request that reserved the key until lockedUntil. It
+	// returns ErrConflict when the key has since been taken over by a retry.
+	Complete(ctx context.Context, key string, lockedUntil time.Time, statusCode int, responseBody []byte) error
+	// Release frees a key reserved until lockedUntil whose request did not complete, so it can
+	// be retried right away. Keys taken over by a retry are left alone.
+	Release(ctx context.Context, key string, lockedUntil time.Time) error
+	// DeleteExpired deletes up to limit expired keys, returning the number deleted
+	DeleteExpired(ctx context.Context, limit int) (int, error)
 }
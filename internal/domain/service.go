@@ -1,17 +1,53 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // MessagingService defines the interface for messaging operations
 type MessagingService interface {
-	SendSMS(ctx context.Context, req *SendSMSRequest) error
-	SendEmail(ctx context.Context, req *SendEmailRequest) error
+	SendSMS(ctx context.Context, req *SendSMSRequest) (*Message, error)
+	SendEmail(ctx context.Context, req *SendEmailRequest) (*Message, error)
+	EnqueueSMS(ctx context.Context, req *SendSMSRequest) (*Message, error)
+	EnqueueEmail(ctx context.Context, req *SendEmailRequest) (*Message, error)
 	HandleInboundSMS(ctx context.Context, webhook *InboundSMSWebhook) error
 	HandleInboundEmail(ctx context.Context, webhook *InboundEmailWebhook) error
+	HandleSMSStatus(ctx context.Context, webhook *MessageStatusWebhook) error
+	HandleEmailStatus(ctx context.Context, webhook *EmailStatusWebhook) error
+	DeliverMessage(ctx context.Context, message *Message) error
+	GetMessage(ctx context.Context, id int) (*Message, error)
+	GetMessageEvents(ctx context.Context, id int) ([]MessageEvent, error)
+	GetMessageAttempts(ctx context.Context, id int) ([]MessageAttempt, error)
+	ListDeadLetters(ctx context.Context, query *DeadLetterQuery) (*GetDeadLettersResponse, error)
+	// ReplayMessage queues a failed outbound message for another delivery through the outbox
+	ReplayMessage(ctx context.Context, id int) (*Message, error)
+	// CancelMessage cancels a scheduled message before it is sent
+	CancelMessage(ctx context.Context, id int) (*Message, error)
+	// RescheduleMessage changes the send time of a scheduled message
+	RescheduleMessage(ctx context.Context, id int, sendAt time.Time) (*Message, error)
 }
 
 // ConversationService defines the interface for conversation operations
 type ConversationService interface {
 	GetConversations(ctx context.Context, query *ConversationQuery) (*GetConversationsResponse, error)
-	GetConversationMessages(ctx context.Context, conversationID int) ([]Message, error)
+	// GetConversationMessages returns the messages of a conversation, only those with the
+	// given direction unless direction is empty
+	GetConversationMessages(ctx context.Context, conversationID int, direction string) ([]Message, error)
+}
+
+// ContactService defines the interface for contact operations
+type ContactService interface {
+	CreateContact(ctx context.Context, req *CreateContactRequest) (*Contact, error)
+	GetContact(ctx context.Context, id int) (*Contact, error)
+	// FindContact returns the contact with an identity of the phone number or email address
+	FindContact(ctx context.Context, address string) (*Contact, error)
+	// MergeContacts moves the identities of the source contact to the target contact and
+	// deletes the source contact
+	MergeContacts(ctx context.Context, targetID, sourceID int) (*Contact, error)
+	// SplitContact moves some identities of a contact to a new contact, which it returns
+	SplitContact(ctx context.Context, id int, req *SplitContactRequest) (*Contact, error)
+	// GetTimeline returns a page of the messages exchanged with the contact over all its
+	// identities and channels in timestamp order, optionally only those with one business contact
+	GetTimeline(ctx context.Context, id int, query *ContactTimelineQuery) (*GetContactTimelineResponse, error)
 }
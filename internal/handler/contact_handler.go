@@ -0,0 +1,225 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"messaging-service/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContactHandler handles HTTP requests for contacts and their cross-channel timelines
+type ContactHandler struct {
+	contactService domain.ContactService
+}
+
+// NewContactHandler creates a new contact handler
+func NewContactHandler(contactService domain.ContactService) *ContactHandler {
+	return &ContactHandler{contactService: contactService}
+}
+
+// CreateContact godoc
+// @Summary Create a contact
+// @Description Create a contact from the phone numbers and email addresses a customer uses. Each address can belong to one contact only.
+// @Tags contacts
+// @Accept json
+// @Produce json
+// @Param contact body domain.CreateContactRequest true "Contact name and identities"
+// @Success 201 {object} domain.Contact
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse "An address already belongs to another contact"
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /contacts [post]
+func (h *ContactHandler) CreateContact(c *gin.Context) {
+	var req domain.CreateContactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	if len(req.Phones) == 0 && len(req.Emails) == 0 {
+		sendErrorResponse(c, http.StatusBadRequest, "At least one phone or email is required", nil)
+		return
+	}
+
+	created, err := h.contactService.CreateContact(c.Request.Context(), &req)
+	if err != nil {
+		sendErrorResponse(c, statusForError(err), "Failed to create contact", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// FindContact godoc
+// @Summary Find a contact by address
+// @Description Look up the contact that a phone number or email address belongs to
+// @Tags contacts
+// @Produce json
+// @Param address query string true "Phone number or email address"
+// @Success 200 {object} domain.Contact
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /contacts [get]
+func (h *ContactHandler) FindContact(c *gin.Context) {
+	address := c.Query("address")
+	if address == "" {
+		sendErrorResponse(c, http.StatusBadRequest, "The address query parameter is required", nil)
+		return
+	}
+
+	found, err := h.contactService.FindContact(c.Request.Context(), address)
+	if err != nil {
+		sendErrorResponse(c, statusForError(err), "Failed to find contact", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, found)
+}
+
+// GetContact godoc
+// @Summary Get a contact
+// @Description Retrieve a contact with its identities
+// @Tags contacts
+// @Produce json
+// @Param id path int true "Contact ID"
+// @Success 200 {object} domain.Contact
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /contacts/{id} [get]
+func (h *ContactHandler) GetContact(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid contact ID", err)
+		return
+	}
+
+	found, err := h.contactService.GetContact(c.Request.Context(), id)
+	if err != nil {
+		sendErrorResponse(c, statusForError(err), "Failed to get contact", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, found)
+}
+
+// MergeContacts godoc
+// @Summary Merge contacts
+// @Description Move all identities of another contact to this contact and delete the other contact
+// @Tags contacts
+// @Accept json
+// @Produce json
+// @Param id path int true "ID of the contact to keep"
+// @Param request body domain.MergeContactsRequest true "Contact to merge into this one"
+// @Success 200 {object} domain.Contact
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse "The contacts are the same"
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /contacts/{id}/merge [post]
+func (h *ContactHandler) MergeContacts(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid contact ID", err)
+		return
+	}
+
+	var req domain.MergeContactsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	merged, err := h.contactService.MergeContacts(c.Request.Context(), id, req.ContactID)
+	if err != nil {
+		sendErrorResponse(c, statusForError(err), "Failed to merge contacts", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, merged)
+}
+
+// SplitContact godoc
+// @Summary Split a contact
+// @Description Move some identities of a contact to a new contact. The contact must keep at least one identity.
+// @Tags contacts
+// @Accept json
+// @Produce json
+// @Param id path int true "Contact ID"
+// @Param request body domain.SplitContactRequest true "Addresses to move and the name of the new contact"
+// @Success 201 {object} domain.Contact
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse "An address is not an identity of the contact, or it would keep none"
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /contacts/{id}/split [post]
+func (h *ContactHandler) SplitContact(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid contact ID", err)
+		return
+	}
+
+	var req domain.SplitContactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	split, err := h.contactService.SplitContact(c.Request.Context(), id, &req)
+	if err != nil {
+		sendErrorResponse(c, statusForError(err), "Failed to split contact", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, split)
+}
+
+// GetContactTimeline godoc
+// @Summary Get the timeline of a contact
+// @Description Retrieve the SMS, MMS and email messages exchanged with a contact over all its identities, in timestamp order
+// @Tags contacts
+// @Produce json
+// @Param id path int true "Contact ID"
+// @Param business query string false "Filter by the business phone number or email address"
+// @Param limit query int false "Number of messages per page (default: 50, max: 100)"
+// @Param offset query int false "Number of messages to skip (default: 0)"
+// @Success 200 {object} domain.GetContactTimelineResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /contacts/{id}/timeline [get]
+func (h *ContactHandler) GetContactTimeline(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid contact ID", err)
+		return
+	}
+
+	var query domain.ContactTimelineQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	// Validate and sanitize parameters
+	if query.Limit > 100 {
+		query.Limit = 100
+	}
+	if query.Limit <= 0 {
+		query.Limit = 50
+	}
+	if query.Offset < 0 {
+		query.Offset = 0
+	}
+
+	timeline, err := h.contactService.GetTimeline(c.Request.Context(), id, &query)
+	if err != nil {
+		sendErrorResponse(c, statusForError(err), "Failed to get contact timeline", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, timeline)
+}
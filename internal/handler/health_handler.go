@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProviderStateReporter reports the circuit breaker state of each provider by name
+type ProviderStateReporter interface {
+	States() map[string]string
+}
+
+// HealthResponse is the body returned by the health check endpoint
+type HealthResponse struct {
+	Status    string            `json:"status"`
+	Timestamp string            `json:"timestamp"`
+	Providers map[string]string `json:"providers,omitempty"`
+}
+
+// HealthHandler handles health check requests
+type HealthHandler struct {
+	providers ProviderStateReporter
+}
+
+// NewHealthHandler creates a new health handler; providers may be nil when circuit breakers are disabled
+func NewHealthHandler(providers ProviderStateReporter) *HealthHandler {
+	return &HealthHandler{providers: providers}
+}
+
+// Health godoc
+// @Summary Health check
+// @Description Report service health and the circuit breaker state of each provider. The status is "degraded" while any provider's breaker is open.
+// @Tags health
+// @Produce json
+// @Success 200 {object} HealthResponse
+// @Router /health [get]
+func (h *HealthHandler) Health(c *gin.Context) {
+	response := HealthResponse{
+		Status:    "ok",
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	if h.providers != nil {
+		response.Providers = h.providers.States()
+		for _, state := range response.Providers {
+			if state == "open" {
+				response.Status = "degraded"
+				break
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -2,45 +2,70 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"messaging-service/internal/contact"
 	"messaging-service/internal/domain"
 
 	"github.com/gin-gonic/gin"
 )
 
+// preferAsync is the RFC 7240 preference clients send to request asynchronous processing
+const preferAsync = "respond-async"
+
 // MessagingHandler handles HTTP requests for messaging operations
 type MessagingHandler struct {
 	messagingService    domain.MessagingService
 	conversationService domain.ConversationService
+	config              HandlerConfig
+}
+
+// HandlerConfig holds messaging handler configuration
+type HandlerConfig struct {
+	// AsyncSend makes send endpoints enqueue messages and respond with 202 Accepted
+	AsyncSend bool `json:"async_send"`
 }
 
 // NewMessagingHandler creates a new messaging handler
 func NewMessagingHandler(messagingService domain.MessagingService, conversationService domain.ConversationService) *MessagingHandler {
+	return NewMessagingHandlerWithConfig(messagingService, conversationService, HandlerConfig{})
+}
+
+// NewMessagingHandlerWithConfig creates a messaging handler with custom configuration
+func NewMessagingHandlerWithConfig(messagingService domain.MessagingService, conversationService domain.ConversationService, config HandlerConfig) *MessagingHandler {
 	return &MessagingHandler{
 		messagingService:    messagingService,
 		conversationService: conversationService,
+		config:              config,
 	}
 }
 
 // SendSMS godoc
 // @Summary Send message
-// @Description Send an SMS or MMS message to a recipient
+// @Description Send an SMS or MMS message to a recipient. When async sending is enabled, the request carries a "Prefer: respond-async" header, or send_at is set, the message is queued for background delivery and 202 Accepted is returned. A future send_at stores the message as scheduled until that time.
 // @Tags messages
 // @Accept json
 // @Produce json
 // @Param message body domain.SendSMSRequest true "Message details"
+// @Param Prefer header string false "Set to respond-async to queue the message and return immediately"
+// @Param Idempotency-Key header string false "Unique key that makes retries of this request return the original response"
 // @Success 200 {object} domain.SendSMSResponse
+// @Success 202 {object} domain.SendSMSResponse
 // @Failure 400 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse "A request with the same Idempotency-Key is in progress"
+// @Failure 422 {object} domain.ErrorResponse "Idempotency-Key was used with a different request"
 // @Failure 500 {object} domain.ErrorResponse
+// @Failure 503 {object} domain.ErrorResponse
 // @Router /messages/message [post]
 func (h *MessagingHandler) SendSMS(c *gin.Context) {
 	var req domain.SendSMSRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.sendErrorResponse(c, http.StatusBadRequest, "Invalid request body", err)
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid request body", err)
 		return
 	}
 
@@ -49,29 +74,52 @@ func (h *MessagingHandler) SendSMS(c *gin.Context) {
 		req.Timestamp = time.Now().UTC()
 	}
 
-	if err := h.messagingService.SendSMS(c.Request.Context(), &req); err != nil {
-		h.sendErrorResponse(c, http.StatusInternalServerError, "Failed to send SMS", err)
+	// Scheduled messages are always queued, as there is nothing to wait for
+	if h.isAsync(c) || req.SendAt != nil {
+		message, err := h.messagingService.EnqueueSMS(c.Request.Context(), &req)
+		if err != nil {
+			sendErrorResponse(c, statusForError(err), "Failed to queue SMS", err)
+			return
+		}
+
+		text := "Message accepted for delivery"
+		if message.Status == domain.MessageStatusScheduled {
+			text = "Message scheduled for delivery"
+		}
+		c.JSON(http.StatusAccepted, h.buildSendSMSResponse(text, message))
+		return
+	}
+
+	message, err := h.messagingService.SendSMS(c.Request.Context(), &req)
+	if err != nil {
+		sendErrorResponse(c, statusForError(err), "Failed to send SMS", err)
 		return
 	}
 
-	c.JSON(http.StatusOK, domain.SendSMSResponse{Message: "Message sent successfully"})
+	c.JSON(http.StatusOK, h.buildSendSMSResponse("Message sent successfully", message))
 }
 
 // SendEmail godoc
 // @Summary Send email message
-// @Description Send an email message to a recipient
+// @Description Send an email message to a recipient. When async sending is enabled, the request carries a "Prefer: respond-async" header, or send_at is set, the email is queued for background delivery and 202 Accepted is returned. A future send_at stores the email as scheduled until that time.
 // @Tags messages
 // @Accept json
 // @Produce json
 // @Param message body domain.SendEmailRequest true "Email message details"
+// @Param Prefer header string false "Set to respond-async to queue the email and return immediately"
+// @Param Idempotency-Key header string false "Unique key that makes retries of this request return the original response"
 // @Success 200 {object} domain.SendEmailResponse
+// @Success 202 {object} domain.SendEmailResponse
 // @Failure 400 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse "A request with the same Idempotency-Key is in progress"
+// @Failure 422 {object} domain.ErrorResponse "Idempotency-Key was used with a different request"
 // @Failure 500 {object} domain.ErrorResponse
+// @Failure 503 {object} domain.ErrorResponse
 // @Router /messages/email [post]
 func (h *MessagingHandler) SendEmail(c *gin.Context) {
 	var req domain.SendEmailRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.sendErrorResponse(c, http.StatusBadRequest, "Invalid request body", err)
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid request body", err)
 		return
 	}
 
@@ -80,12 +128,29 @@ func (h *MessagingHandler) SendEmail(c *gin.Context) {
 		req.Timestamp = time.Now().UTC()
 	}
 
-	if err := h.messagingService.SendEmail(c.Request.Context(), &req); err != nil {
-		h.sendErrorResponse(c, http.StatusInternalServerError, "Failed to send email", err)
+	// Scheduled emails are always queued, as there is nothing to wait for
+	if h.isAsync(c) || req.SendAt != nil {
+		message, err := h.messagingService.EnqueueEmail(c.Request.Context(), &req)
+		if err != nil {
+			sendErrorResponse(c, statusForError(err), "Failed to queue email", err)
+			return
+		}
+
+		text := "Email accepted for delivery"
+		if message.Status == domain.MessageStatusScheduled {
+			text = "Email scheduled for delivery"
+		}
+		c.JSON(http.StatusAccepted, h.buildSendEmailResponse(text, message))
 		return
 	}
 
-	c.JSON(http.StatusOK, domain.SendEmailResponse{Message: "Email sent successfully"})
+	message, err := h.messagingService.SendEmail(c.Request.Context(), &req)
+	if err != nil {
+		sendErrorResponse(c, statusForError(err), "Failed to send email", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.buildSendEmailResponse("Email sent successfully", message))
 }
 
 // HandleInboundSMS godoc
@@ -102,7 +167,7 @@ func (h *MessagingHandler) SendEmail(c *gin.Context) {
 func (h *MessagingHandler) HandleInboundSMS(c *gin.Context) {
 	var webhook domain.InboundSMSWebhook
 	if err := c.ShouldBindJSON(&webhook); err != nil {
-		h.sendErrorResponse(c, http.StatusBadRequest, "Invalid webhook body", err)
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid webhook body", err)
 		return
 	}
 
@@ -112,7 +177,7 @@ func (h *MessagingHandler) HandleInboundSMS(c *gin.Context) {
 	}
 
 	if err := h.messagingService.HandleInboundSMS(c.Request.Context(), &webhook); err != nil {
-		h.sendErrorResponse(c, http.StatusInternalServerError, "Failed to process inbound SMS", err)
+		sendErrorResponse(c, statusForError(err), "Failed to process inbound SMS", err)
 		return
 	}
 
@@ -133,7 +198,7 @@ func (h *MessagingHandler) HandleInboundSMS(c *gin.Context) {
 func (h *MessagingHandler) HandleInboundEmail(c *gin.Context) {
 	var webhook domain.InboundEmailWebhook
 	if err := c.ShouldBindJSON(&webhook); err != nil {
-		h.sendErrorResponse(c, http.StatusBadRequest, "Invalid webhook body", err)
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid webhook body", err)
 		return
 	}
 
@@ -143,36 +208,66 @@ func (h *MessagingHandler) HandleInboundEmail(c *gin.Context) {
 	}
 
 	if err := h.messagingService.HandleInboundEmail(c.Request.Context(), &webhook); err != nil {
-		h.sendErrorResponse(c, http.StatusInternalServerError, "Failed to process inbound email", err)
+		sendErrorResponse(c, statusForError(err), "Failed to process inbound email", err)
 		return
 	}
 
 	c.JSON(http.StatusOK, domain.WebhookResponse{Message: "Inbound email processed successfully"})
 }
 
-// handleOutboundWebhook is a generic handler for outbound webhooks
-func (h *MessagingHandler) handleOutboundWebhook(c *gin.Context, webhookType string, processFunc func(context.Context) error) {
-	// Set timestamp if not provided
-	if err := h.setTimestampIfZero(c); err != nil {
-		h.sendErrorResponse(c, http.StatusBadRequest, "Invalid webhook body", err)
+// HandleSMSStatus godoc
+// @Summary Handle message delivery status webhook
+// @Description Apply a delivery receipt for an outbound SMS or MMS identified by its provider message ID. Receipts that would move a message backwards are ignored.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body domain.MessageStatusWebhook true "Delivery status webhook data"
+// @Success 200 {object} domain.WebhookResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /webhooks/message/status [post]
+func (h *MessagingHandler) HandleSMSStatus(c *gin.Context) {
+	var webhook domain.MessageStatusWebhook
+	h.handleOutboundWebhook(c, "message", &webhook, func(ctx context.Context) error {
+		return h.messagingService.HandleSMSStatus(ctx, &webhook)
+	})
+}
+
+// HandleEmailStatus godoc
+// @Summary Handle email delivery status webhook
+// @Description Apply a delivery receipt or bounce for an outbound email identified by its provider message ID. Receipts that would move a message backwards are ignored.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body domain.EmailStatusWebhook true "Delivery status webhook data"
+// @Success 200 {object} domain.WebhookResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /webhooks/email/status [post]
+func (h *MessagingHandler) HandleEmailStatus(c *gin.Context) {
+	var webhook domain.EmailStatusWebhook
+	h.handleOutboundWebhook(c, "email", &webhook, func(ctx context.Context) error {
+		return h.messagingService.HandleEmailStatus(ctx, &webhook)
+	})
+}
+
+// handleOutboundWebhook is a generic handler for outbound delivery status webhooks
+func (h *MessagingHandler) handleOutboundWebhook(c *gin.Context, webhookType string, webhook interface{}, processFunc func(context.Context) error) {
+	if err := c.ShouldBindJSON(webhook); err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid webhook body", err)
 		return
 	}
 
 	if err := processFunc(c.Request.Context()); err != nil {
-		h.sendErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("Failed to process outbound %s status", webhookType), err)
+		sendErrorResponse(c, statusForError(err), fmt.Sprintf("Failed to process outbound %s status", webhookType), err)
 		return
 	}
 
 	c.JSON(http.StatusOK, domain.WebhookResponse{Message: fmt.Sprintf("Outbound %s status processed successfully", webhookType)})
 }
 
-// setTimestampIfZero sets timestamp to current time if it's zero
-func (h *MessagingHandler) setTimestampIfZero(c *gin.Context) error {
-	// This is a simplified version - in practice, you'd need to handle different webhook types
-	// For now, we'll keep the individual handlers but extract common logic
-	return nil
-}
-
 // GetConversations godoc
 // @Summary Get conversations with filtering and pagination
 // @Description Retrieve conversations with optional filtering, search, and pagination. At least one query parameter is required for performance reasons.
@@ -185,9 +280,11 @@ func (h *MessagingHandler) setTimestampIfZero(c *gin.Context) error {
 // @Param from query string false "Filter conversations updated from date (RFC3339)"
 // @Param to query string false "Filter conversations updated to date (RFC3339)"
 // @Param message_type query string false "Filter by message type (sms, mms, email)"
+// @Param direction query string false "Filter by the direction of the last message (inbound, outbound)"
+// @Param awaiting_reply query bool false "Only conversations whose last message is inbound"
 // @Param limit query int false "Number of conversations per page (default: 50, max: 100)"
 // @Param offset query int false "Number of conversations to skip (default: 0)"
-// @Param sort_by query string false "Sort field (id, created_at, updated_at)"
+// @Param sort_by query string false "Sort field (id, created_at, updated_at, last_message_at)"
 // @Param sort_order query string false "Sort order (asc, desc)"
 // @Param include_messages query bool false "Include messages in response (default: false)"
 // @Success 200 {object} domain.GetConversationsResponse
@@ -197,14 +294,20 @@ func (h *MessagingHandler) setTimestampIfZero(c *gin.Context) error {
 func (h *MessagingHandler) GetConversations(c *gin.Context) {
 	var query domain.ConversationQuery
 	if err := c.ShouldBindQuery(&query); err != nil {
-		h.sendErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err)
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err)
 		return
 	}
 
 	// Validate that at least one query parameter is provided for performance reasons
 	if query.BusinessEmail == "" && query.BusinessPhone == "" && query.Search == "" &&
-		query.From.IsZero() && query.To.IsZero() && query.MessageType == "" {
-		h.sendErrorResponse(c, http.StatusBadRequest, "At least one query parameter is required (business_email, business_phone, search, from, to, or message_type)", nil)
+		query.From.IsZero() && query.To.IsZero() && query.MessageType == "" &&
+		query.Direction == "" && !query.AwaitingReply {
+		sendErrorResponse(c, http.StatusBadRequest, "At least one query parameter is required (business_email, business_phone, search, from, to, message_type, direction, or awaiting_reply)", nil)
+		return
+	}
+
+	if query.Direction != "" && !domain.IsValidMessageDirection(query.Direction) {
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid direction (must be inbound or outbound)", nil)
 		return
 	}
 
@@ -233,7 +336,7 @@ func (h *MessagingHandler) GetConversations(c *gin.Context) {
 
 	response, err := h.conversationService.GetConversations(c.Request.Context(), &query)
 	if err != nil {
-		h.sendErrorResponse(c, http.StatusInternalServerError, "Failed to get conversations", err)
+		sendErrorResponse(c, http.StatusInternalServerError, "Failed to get conversations", err)
 		return
 	}
 
@@ -247,6 +350,7 @@ func (h *MessagingHandler) GetConversations(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "Conversation ID"
+// @Param direction query string false "Filter by message direction (inbound, outbound)"
 // @Success 200 {object} domain.GetConversationMessagesResponse
 // @Failure 400 {object} domain.ErrorResponse
 // @Failure 404 {object} domain.ErrorResponse
@@ -256,21 +360,306 @@ func (h *MessagingHandler) GetConversationMessages(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		h.sendErrorResponse(c, http.StatusBadRequest, "Invalid conversation ID", err)
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid conversation ID", err)
+		return
+	}
+
+	direction := c.Query("direction")
+	if direction != "" && !domain.IsValidMessageDirection(direction) {
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid direction (must be inbound or outbound)", nil)
 		return
 	}
 
-	messages, err := h.conversationService.GetConversationMessages(c.Request.Context(), id)
+	messages, err := h.conversationService.GetConversationMessages(c.Request.Context(), id, direction)
 	if err != nil {
-		h.sendErrorResponse(c, http.StatusInternalServerError, "Failed to get messages", err)
+		sendErrorResponse(c, statusForError(err), "Failed to get messages", err)
 		return
 	}
 
 	c.JSON(http.StatusOK, domain.GetConversationMessagesResponse{Messages: messages})
 }
 
+// isAsync reports whether a send request should be queued instead of delivered inline
+func (h *MessagingHandler) isAsync(c *gin.Context) bool {
+	if h.config.AsyncSend {
+		return true
+	}
+	for _, preference := range strings.Split(c.GetHeader("Prefer"), ",") {
+		if strings.EqualFold(strings.TrimSpace(preference), preferAsync) {
+			return true
+		}
+	}
+	return false
+}
+
+// messageStatusURL returns the API path where a message's status can be polled
+func (h *MessagingHandler) messageStatusURL(messageID int) string {
+	return fmt.Sprintf("/api/messages/%d", messageID)
+}
+
+// buildSendSMSResponse builds the response for a persisted SMS/MMS
+func (h *MessagingHandler) buildSendSMSResponse(text string, message *domain.Message) domain.SendSMSResponse {
+	return domain.SendSMSResponse{
+		Message:        text,
+		MessageID:      message.ID,
+		ConversationID: message.ConversationID,
+		Status:         message.Status,
+		StatusURL:      h.messageStatusURL(message.ID),
+	}
+}
+
+// buildSendEmailResponse builds the response for a persisted email
+func (h *MessagingHandler) buildSendEmailResponse(text string, message *domain.Message) domain.SendEmailResponse {
+	return domain.SendEmailResponse{
+		Message:        text,
+		MessageID:      message.ID,
+		ConversationID: message.ConversationID,
+		Status:         message.Status,
+		StatusURL:      h.messageStatusURL(message.ID),
+	}
+}
+
+// GetMessage godoc
+// @Summary Get a message
+// @Description Retrieve a single message including its delivery status, error details and provider message ID
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Param id path int true "Message ID"
+// @Success 200 {object} domain.Message
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /messages/{id} [get]
+func (h *MessagingHandler) GetMessage(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid message ID", err)
+		return
+	}
+
+	message, err := h.messagingService.GetMessage(c.Request.Context(), id)
+	if err != nil {
+		sendErrorResponse(c, statusForError(err), "Failed to get message", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, message)
+}
+
+// CancelMessage godoc
+// @Summary Cancel a scheduled message
+// @Description Cancel a scheduled message before it is sent
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Param id path int true "Message ID"
+// @Success 200 {object} domain.Message
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse "Message is not scheduled"
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /messages/{id} [delete]
+func (h *MessagingHandler) CancelMessage(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid message ID", err)
+		return
+	}
+
+	message, err := h.messagingService.CancelMessage(c.Request.Context(), id)
+	if err != nil {
+		sendErrorResponse(c, statusForError(err), "Failed to cancel message", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, message)
+}
+
+// RescheduleMessage godoc
+// @Summary Reschedule a scheduled message
+// @Description Change the send time of a scheduled message that has not been sent yet
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Param id path int true "Message ID"
+// @Param request body domain.RescheduleMessageRequest true "New send time"
+// @Success 200 {object} domain.Message
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse "Message is not scheduled"
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /messages/{id} [patch]
+func (h *MessagingHandler) RescheduleMessage(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid message ID", err)
+		return
+	}
+
+	var req domain.RescheduleMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	if !req.SendAt.After(time.Now()) {
+		sendErrorResponse(c, http.StatusBadRequest, "send_at must be in the future", nil)
+		return
+	}
+
+	message, err := h.messagingService.RescheduleMessage(c.Request.Context(), id, req.SendAt)
+	if err != nil {
+		sendErrorResponse(c, statusForError(err), "Failed to reschedule message", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, message)
+}
+
+// GetMessageEvents godoc
+// @Summary Get message status history
+// @Description Retrieve the status transitions of a message in chronological order
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Param id path int true "Message ID"
+// @Success 200 {object} domain.GetMessageEventsResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /messages/{id}/events [get]
+func (h *MessagingHandler) GetMessageEvents(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid message ID", err)
+		return
+	}
+
+	events, err := h.messagingService.GetMessageEvents(c.Request.Context(), id)
+	if err != nil {
+		sendErrorResponse(c, statusForError(err), "Failed to get message events", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.GetMessageEventsResponse{Events: events})
+}
+
+// GetMessageAttempts godoc
+// @Summary Get message delivery attempts
+// @Description Retrieve every provider call made while delivering a message, including failed and retried attempts
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Param id path int true "Message ID"
+// @Success 200 {object} domain.GetMessageAttemptsResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /messages/{id}/attempts [get]
+func (h *MessagingHandler) GetMessageAttempts(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid message ID", err)
+		return
+	}
+
+	attempts, err := h.messagingService.GetMessageAttempts(c.Request.Context(), id)
+	if err != nil {
+		sendErrorResponse(c, statusForError(err), "Failed to get message attempts", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.GetMessageAttemptsResponse{Attempts: attempts})
+}
+
+// ListDeadLetters godoc
+// @Summary List failed outbound messages
+// @Description Retrieve outbound messages that failed permanently, most recently failed first, with the last provider error
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param provider query string false "Filter by the provider that failed the message"
+// @Param error_code query string false "Filter by error code"
+// @Param from query string false "Filter messages that failed at or after this time (RFC3339)"
+// @Param to query string false "Filter messages that failed before this time (RFC3339)"
+// @Param limit query int false "Number of messages per page (default: 50, max: 100)"
+// @Param offset query int false "Number of messages to skip (default: 0)"
+// @Success 200 {object} domain.GetDeadLettersResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /admin/dead-letters [get]
+func (h *MessagingHandler) ListDeadLetters(c *gin.Context) {
+	var query domain.DeadLetterQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	if query.Limit > 100 {
+		query.Limit = 100
+	}
+
+	response, err := h.messagingService.ListDeadLetters(c.Request.Context(), &query)
+	if err != nil {
+		sendErrorResponse(c, http.StatusInternalServerError, "Failed to get dead letters", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ReplayDeadLetters godoc
+// @Summary Replay failed outbound messages
+// @Description Queue failed messages for another delivery through the normal send pipeline. Messages that cannot be replayed are reported individually.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body domain.ReplayDeadLettersRequest true "IDs of the failed messages to replay"
+// @Success 200 {object} domain.ReplayDeadLettersResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Router /admin/dead-letters/replay [post]
+func (h *MessagingHandler) ReplayDeadLetters(c *gin.Context) {
+	var req domain.ReplayDeadLettersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	response := domain.ReplayDeadLettersResponse{
+		Replayed: []domain.Message{},
+		Failed:   []domain.ReplayFailure{},
+	}
+	for _, id := range req.MessageIDs {
+		message, err := h.messagingService.ReplayMessage(c.Request.Context(), id)
+		if err != nil {
+			response.Failed = append(response.Failed, domain.ReplayFailure{MessageID: id, Error: err.Error()})
+			continue
+		}
+		response.Replayed = append(response.Replayed, *message)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// statusForError maps service errors onto HTTP status codes
+func statusForError(err error) int {
+	if errors.Is(err, contact.ErrInvalidPhoneNumber) || errors.Is(err, contact.ErrInvalidEmailAddress) {
+		return http.StatusBadRequest
+	}
+	if errors.Is(err, domain.ErrNotFound) {
+		return http.StatusNotFound
+	}
+	if errors.Is(err, domain.ErrInvalidState) || errors.Is(err, domain.ErrConflict) {
+		return http.StatusConflict
+	}
+	if errors.Is(err, domain.ErrCircuitOpen) {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
 // sendErrorResponse sends a consistent error response
-func (h *MessagingHandler) sendErrorResponse(c *gin.Context, statusCode int, message string, err error) {
+func sendErrorResponse(c *gin.Context, statusCode int, message string, err error) {
 	errorMsg := message
 	if err != nil {
 		errorMsg = message + ": " + err.Error()
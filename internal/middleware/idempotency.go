@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"messaging-service/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+	// Send endpoints only respond with JSON
+	idempotentContentType = "application/json; charset=utf-8"
+)
+
+// IdempotencyMiddleware makes requests carrying an Idempotency-Key header safe to retry.
+// The first request with a key is processed and its response stored; repeats within ttl
+// get the stored response without being processed again. Reusing a key with a different
+// request returns 422, and a repeat arriving while the first request is still being
+// processed returns 409. A request that has not completed within lease is considered
+// abandoned, and its key can be taken over by a retry. Server errors are not stored, since
+// they are usually transient, so a retry after one is processed again.
+func IdempotencyMiddleware(repo domain.IdempotencyRepository, ttl, lease time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			abortWithError(c, http.StatusBadRequest, "Idempotency-Key must be at most 255 characters")
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, "Failed to read request body: "+err.Error())
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		fingerprint := requestFingerprint(c.Request.Method, c.Request.URL.Path, body)
+
+		now := time.Now()
+		record, reserved, err := repo.Reserve(c.Request.Context(), key, fingerprint, now.Add(lease), now.Add(ttl))
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, "Failed to check idempotency key: "+err.Error())
+			return
+		}
+
+		if !reserved {
+			switch {
+			case record.Fingerprint != fingerprint:
+				abortWithError(c, http.StatusUnprocessableEntity, "Idempotency-Key was already used with a different request")
+			case record.StatusCode == nil:
+				abortWithError(c, http.StatusConflict, "A request with this Idempotency-Key is still being processed")
+			default:
+				c.Header(IdempotentReplayedHeader, "true")
+				c.Data(*record.StatusCode, idempotentContentType, record.ResponseBody)
+				c.Abort()
+			}
+			return
+		}
+
+		// Free the key when the request does not complete, including when the handler
+		// panics, so that a retry is processed instead of being rejected until it expires.
+		// The reservation's lock identifies this request, so a key a retry has taken over
+		// is neither released nor overwritten.
+		ctx := context.WithoutCancel(c.Request.Context())
+		completed := false
+		defer func() {
+			if !completed {
+				_ = repo.Release(ctx, key, record.LockedUntil)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		c.Next()
+
+		// Store the response even if the client has gone away, since it may retry
+		if status := c.Writer.Status(); status < http.StatusInternalServerError {
+			completed = repo.Complete(ctx, key, record.LockedUntil, status, recorder.body.Bytes()) == nil
+		}
+	}
+}
+
+// requestFingerprint identifies a request by its method, path and body
+func requestFingerprint(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func abortWithError(c *gin.Context, statusCode int, message string) {
+	c.AbortWithStatusJSON(statusCode, domain.ErrorResponse{Error: message})
+}
+
+// responseRecorder keeps a copy of the response body as it is written
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
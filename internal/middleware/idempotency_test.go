@@ -0,0 +1,263 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"messaging-service/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryIdempotencyRepository keeps idempotency records in memory
+type memoryIdempotencyRepository struct {
+	mu      sync.Mutex
+	records map[string]*domain.IdempotencyRecord
+	// completeErr is returned by Complete when set
+	completeErr error
+}
+
+func newMemoryIdempotencyRepository() *memoryIdempotencyRepository {
+	return &memoryIdempotencyRepository{records: make(map[string]*domain.IdempotencyRecord)}
+}
+
+func (r *memoryIdempotencyRepository) Reserve(ctx context.Context, key, fingerprint string, lockedUntil, expiresAt time.Time) (*domain.IdempotencyRecord, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if record, ok := r.records[key]; ok && record.ExpiresAt.After(now) && (record.StatusCode != nil || record.LockedUntil.After(now)) {
+		copied := *record
+		return &copied, false, nil
+	}
+	record := &domain.IdempotencyRecord{Key: key, Fingerprint: fingerprint, LockedUntil: lockedUntil, CreatedAt: now, ExpiresAt: expiresAt}
+	r.records[key] = record
+	copied := *record
+	return &copied, true, nil
+}
+
+func (r *memoryIdempotencyRepository) Complete(ctx context.Context, key string, lockedUntil time.Time, statusCode int, responseBody []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.completeErr != nil {
+		return r.completeErr
+	}
+	record, ok := r.records[key]
+	if !ok || !record.LockedUntil.Equal(lockedUntil) || record.StatusCode != nil {
+		return domain.ErrConflict
+	}
+	record.StatusCode = &statusCode
+	record.ResponseBody = responseBody
+	return nil
+}
+
+func (r *memoryIdempotencyRepository) Release(ctx context.Context, key string, lockedUntil time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if record, ok := r.records[key]; ok && record.LockedUntil.Equal(lockedUntil) && record.StatusCode == nil {
+		delete(r.records, key)
+	}
+	return nil
+}
+
+func (r *memoryIdempotencyRepository) DeleteExpired(ctx context.Context, limit int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleted := 0
+	for key, record := range r.records {
+		if deleted < limit && !record.ExpiresAt.After(time.Now()) {
+			delete(r.records, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func newIdempotentRouter(repo domain.IdempotencyRepository, ttl time.Duration) (*gin.Engine, *int) {
+	gin.SetMode(gin.TestMode)
+
+	calls := 0
+	router := gin.New()
+	router.POST("/messages", IdempotencyMiddleware(repo, ttl, time.Minute), func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusOK, gin.H{"message_id": calls})
+	})
+	return router, &calls
+}
+
+func postWithKey(router *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/messages", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyMiddleware_ReplaysResponse(t *testing.T) {
+	router, calls := newIdempotentRouter(newMemoryIdempotencyRepository(), time.Hour)
+
+	first := postWithKey(router, "key-1", `{"body":"hello"}`)
+	second := postWithKey(router, "key-1", `{"body":"hello"}`)
+
+	assert.Equal(t, 1, *calls)
+	assert.Equal(t, http.StatusOK, second.Code)
+	assert.JSONEq(t, first.Body.String(), second.Body.String())
+	assert.Empty(t, first.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, "true", second.Header().Get(IdempotentReplayedHeader))
+}
+
+func TestIdempotencyMiddleware_RejectsKeyReuseWithDifferentBody(t *testing.T) {
+	router, calls := newIdempotentRouter(newMemoryIdempotencyRepository(), time.Hour)
+
+	postWithKey(router, "key-1", `{"body":"hello"}`)
+	w := postWithKey(router, "key-1", `{"body":"goodbye"}`)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, 1, *calls)
+}
+
+func TestIdempotencyMiddleware_RejectsRequestInProgress(t *testing.T) {
+	repo := newMemoryIdempotencyRepository()
+	router, calls := newIdempotentRouter(repo, time.Hour)
+
+	// Reserve the key as if another request were still being processed
+	fingerprint := requestFingerprint("POST", "/messages", []byte(`{"body":"hello"}`))
+	_, _, _ = repo.Reserve(context.Background(), "key-1", fingerprint, time.Now().Add(time.Minute), time.Now().Add(time.Hour))
+
+	w := postWithKey(router, "key-1", `{"body":"hello"}`)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Zero(t, *calls)
+}
+
+func TestIdempotencyMiddleware_TakesOverAbandonedRequest(t *testing.T) {
+	repo := newMemoryIdempotencyRepository()
+	router, calls := newIdempotentRouter(repo, time.Hour)
+
+	// Reserve the key as if a request had died while holding it
+	fingerprint := requestFingerprint("POST", "/messages", []byte(`{"body":"hello"}`))
+	_, _, _ = repo.Reserve(context.Background(), "key-1", fingerprint, time.Now().Add(-time.Second), time.Now().Add(time.Hour))
+
+	w := postWithKey(router, "key-1", `{"body":"hello"}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, *calls)
+}
+
+func TestIdempotencyMiddleware_ReleasesKeyWhenHandlerPanics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := newMemoryIdempotencyRepository()
+
+	calls := 0
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.POST("/messages", IdempotencyMiddleware(repo, time.Hour, time.Minute), func(c *gin.Context) {
+		calls++
+		if calls == 1 {
+			panic("boom")
+		}
+		c.JSON(http.StatusOK, gin.H{"message_id": calls})
+	})
+
+	first := postWithKey(router, "key-1", `{"body":"hello"}`)
+	second := postWithKey(router, "key-1", `{"body":"hello"}`)
+
+	assert.Equal(t, http.StatusInternalServerError, first.Code)
+	assert.Equal(t, http.StatusOK, second.Code)
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotencyMiddleware_ReleasesKeyWhenResponseIsNotStored(t *testing.T) {
+	repo := newMemoryIdempotencyRepository()
+	repo.completeErr = errors.New("connection refused")
+	router, calls := newIdempotentRouter(repo, time.Hour)
+
+	postWithKey(router, "key-1", `{"body":"hello"}`)
+	w := postWithKey(router, "key-1", `{"body":"hello"}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 2, *calls)
+}
+
+func TestIdempotencyMiddleware_DoesNotStoreServerErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := newMemoryIdempotencyRepository()
+
+	calls := 0
+	router := gin.New()
+	router.POST("/messages", IdempotencyMiddleware(repo, time.Hour, time.Minute), func(c *gin.Context) {
+		calls++
+		if calls == 1 {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "circuit breaker is open"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message_id": calls})
+	})
+
+	first := postWithKey(router, "key-1", `{"body":"hello"}`)
+	second := postWithKey(router, "key-1", `{"body":"hello"}`)
+	third := postWithKey(router, "key-1", `{"body":"hello"}`)
+
+	assert.Equal(t, http.StatusServiceUnavailable, first.Code)
+	assert.Equal(t, http.StatusOK, second.Code)
+	assert.Empty(t, second.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, "true", third.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotencyMiddleware_KeepsKeyTakenOverByRetry(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := newMemoryIdempotencyRepository()
+
+	// The first request outlives its lease, and a retry takes the key over and completes
+	// before the first request finishes
+	calls := 0
+	var retry *httptest.ResponseRecorder
+	router := gin.New()
+	router.POST("/messages", IdempotencyMiddleware(repo, time.Hour, -time.Second), func(c *gin.Context) {
+		calls++
+		if calls == 1 {
+			retry = postWithKey(router, "key-1", `{"body":"hello"}`)
+		}
+		c.JSON(http.StatusOK, gin.H{"message_id": calls})
+	})
+
+	postWithKey(router, "key-1", `{"body":"hello"}`)
+
+	// The retry's response is the one stored, and the first request did not release it
+	record := repo.records["key-1"]
+	assert.Equal(t, http.StatusOK, retry.Code)
+	assert.NotNil(t, record.StatusCode)
+	assert.JSONEq(t, retry.Body.String(), string(record.ResponseBody))
+}
+
+func TestIdempotencyMiddleware_ProcessesAgainAfterExpiry(t *testing.T) {
+	router, calls := newIdempotentRouter(newMemoryIdempotencyRepository(), -time.Second)
+
+	postWithKey(router, "key-1", `{"body":"hello"}`)
+	w := postWithKey(router, "key-1", `{"body":"hello"}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 2, *calls)
+}
+
+func TestIdempotencyMiddleware_IgnoresRequestsWithoutKey(t *testing.T) {
+	router, calls := newIdempotentRouter(newMemoryIdempotencyRepository(), time.Hour)
+
+	postWithKey(router, "", `{"body":"hello"}`)
+	postWithKey(router, "", `{"body":"hello"}`)
+
+	assert.Equal(t, 2, *calls)
+}
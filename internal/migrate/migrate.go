@@ -0,0 +1,320 @@
+// Package migrate applies versioned SQL migrations to the database. Every migration runs in
+// its own transaction together with its entry in the schema_migrations table. On PostgreSQL
+// runs are serialized across instances with an advisory lock.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Dialect holds the database specific statements used by the migrator
+type Dialect struct {
+	// Lock and Unlock serialize migration runs across processes; empty when not needed
+	Lock   string
+	Unlock string
+	// CreateTable creates the schema_migrations table if it does not exist
+	CreateTable string
+	// Insert and Delete add and remove a version; Insert takes the version and name
+	Insert string
+	Delete string
+	// IsApplied counts the rows of a version
+	IsApplied string
+}
+
+// Postgres is the dialect for PostgreSQL, which holds an advisory lock while migrating
+var Postgres = Dialect{
+	Lock:   `SELECT pg_advisory_lock(7267946135)`,
+	Unlock: `SELECT pg_advisory_unlock(7267946135)`,
+	CreateTable: `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`,
+	Insert:    `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`,
+	Delete:    `DELETE FROM schema_migrations WHERE version = $1`,
+	IsApplied: `SELECT COUNT(*) FROM schema_migrations WHERE version = $1`,
+}
+
+// SQLite is the dialect for SQLite. No lock is needed because each migration transaction
+// takes the database write lock and checks again that the migration is still pending.
+var SQLite = Dialect{
+	CreateTable: `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`,
+	Insert:    `INSERT INTO schema_migrations (version, name) VALUES (?, ?)`,
+	Delete:    `DELETE FROM schema_migrations WHERE version = ?`,
+	IsApplied: `SELECT COUNT(*) FROM schema_migrations WHERE version = ?`,
+}
+
+// fileNamePattern matches migration files such as 001_init_schema.up.sql
+var fileNamePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is one versioned schema change
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	// Down reverts the migration; empty when it cannot be reverted
+	Down string
+}
+
+// Status describes whether a migration has been applied
+type Status struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+// Load reads the migrations in dir of fsys, ordered by version
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		contents, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", migration.Name, match[2], version)
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(contents)
+		} else {
+			migration.Down = string(contents)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up migration", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// Migrator applies and reverts migrations
+type Migrator struct {
+	db         *sql.DB
+	dialect    Dialect
+	migrations []Migration
+	logger     *zap.Logger
+}
+
+// NewMigrator creates a migrator for the given migrations
+func NewMigrator(db *sql.DB, dialect Dialect, migrations []Migration, logger *zap.Logger) *Migrator {
+	return &Migrator{
+		db:         db,
+		dialect:    dialect,
+		migrations: migrations,
+		logger:     logger,
+	}
+}
+
+// Up applies all pending migrations in version order and returns how many were applied
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	applied := 0
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		versions, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := versions[migration.Version]; ok {
+				continue
+			}
+			if err := m.apply(ctx, conn, migration, migration.Up, true); err != nil {
+				return err
+			}
+			applied++
+		}
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down reverts the most recently applied migrations, at most steps of them, and returns how
+// many were reverted
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	reverted := 0
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		versions, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && reverted < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := versions[migration.Version]; !ok {
+				continue
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("migration %d_%s cannot be reverted", migration.Version, migration.Name)
+			}
+			if err := m.apply(ctx, conn, migration, migration.Down, false); err != nil {
+				return err
+			}
+			reverted++
+		}
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Status lists all known migrations and when they were applied
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		versions, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			status := Status{Version: migration.Version, Name: migration.Name}
+			if appliedAt, ok := versions[migration.Version]; ok {
+				status.AppliedAt = &appliedAt
+			}
+			statuses = append(statuses, status)
+		}
+		return nil
+	})
+
+	return statuses, err
+}
+
+// withLock runs fn on a dedicated connection holding the dialect's migration lock, creating
+// the schema_migrations table first if needed
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
+	}
+	defer conn.Close()
+
+	// Session-level locks belong to the connection, so the same one must unlock it
+	if m.dialect.Lock != "" {
+		if _, err := conn.ExecContext(ctx, m.dialect.Lock); err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		defer conn.ExecContext(context.WithoutCancel(ctx), m.dialect.Unlock)
+	}
+
+	if _, err := conn.ExecContext(ctx, m.dialect.CreateTable); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	return fn(conn)
+}
+
+// apply runs a migration script and records or removes its version in one transaction
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration Migration, script string, up bool) error {
+	direction := "down"
+	if up {
+		direction = "up"
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Another process may have run the migration since the versions were read
+	var count int
+	if err := tx.QueryRowContext(ctx, m.dialect.IsApplied, migration.Version).Scan(&count); err != nil {
+		return fmt.Errorf("failed to check migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	if (count > 0) == up {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return fmt.Errorf("failed to run migration %d_%s %s: %w", migration.Version, migration.Name, direction, err)
+	}
+
+	if up {
+		_, err = tx.ExecContext(ctx, m.dialect.Insert, migration.Version, migration.Name)
+	} else {
+		_, err = tx.ExecContext(ctx, m.dialect.Delete, migration.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	m.logger.Info("Applied migration",
+		zap.Int("version", migration.Version),
+		zap.String("name", migration.Name),
+		zap.String("direction", direction))
+	return nil
+}
+
+// appliedVersions returns the applied migration versions and when they were applied
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	versions := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		versions[version] = appliedAt
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating applied migrations: %w", err)
+	}
+
+	return versions, nil
+}
@@ -0,0 +1,47 @@
+package migrate
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_OrdersMigrationsByVersion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/010_add_index.up.sql":      {Data: []byte("CREATE INDEX idx ON t(c);")},
+		"migrations/002_create_table.up.sql":   {Data: []byte("CREATE TABLE t (c INT);")},
+		"migrations/002_create_table.down.sql": {Data: []byte("DROP TABLE t;")},
+	}
+
+	migrations, err := Load(fsys, "migrations")
+
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+	assert.Equal(t, Migration{Version: 2, Name: "create_table", Up: "CREATE TABLE t (c INT);", Down: "DROP TABLE t;"}, migrations[0])
+	assert.Equal(t, 10, migrations[1].Version)
+	assert.Equal(t, "add_index", migrations[1].Name)
+	assert.Empty(t, migrations[1].Down)
+}
+
+func TestLoad_RejectsInvalidMigrations(t *testing.T) {
+	tests := []struct {
+		name  string
+		files fstest.MapFS
+	}{
+		{"invalid file name", fstest.MapFS{"migrations/create_table.sql": {}}},
+		{"missing up migration", fstest.MapFS{"migrations/001_create_table.down.sql": {Data: []byte("DROP TABLE t;")}}},
+		{"duplicate version", fstest.MapFS{
+			"migrations/001_create_table.up.sql": {Data: []byte("CREATE TABLE t (c INT);")},
+			"migrations/001_create_other.up.sql": {Data: []byte("CREATE TABLE o (c INT);")},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(tt.files, "migrations")
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// maxAttachmentSize caps the size of a single downloaded attachment (SendGrid allows 30MB per message)
+const maxAttachmentSize = 20 << 20
+
+// fetchedAttachment holds a downloaded attachment ready to be embedded in a provider request
+type fetchedAttachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+// fetchAttachment downloads the attachment at rawURL
+func fetchAttachment(ctx context.Context, client *http.Client, rawURL string) (*fetchedAttachment, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil, fmt.Errorf("invalid attachment URL %q", rawURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create attachment request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download attachment %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download attachment %s: unexpected status %d", rawURL, resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxAttachmentSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attachment %s: %w", rawURL, err)
+	}
+	if len(content) > maxAttachmentSize {
+		return nil, fmt.Errorf("attachment %s exceeds %d bytes", rawURL, maxAttachmentSize)
+	}
+
+	filename := path.Base(parsed.Path)
+	if filename == "." || filename == "/" {
+		filename = "attachment"
+	}
+
+	return &fetchedAttachment{
+		Filename:    filename,
+		ContentType: attachmentContentType(resp.Header.Get("Content-Type"), filename),
+		Content:     content,
+	}, nil
+}
+
+// attachmentContentType prefers the served media type and falls back to the file extension
+func attachmentContentType(header, filename string) string {
+	if mediaType, _, err := mime.ParseMediaType(header); err == nil && mediaType != "" {
+		return mediaType
+	}
+	if byExt := mime.TypeByExtension(path.Ext(filename)); byExt != "" {
+		mediaType, _, _ := strings.Cut(byExt, ";")
+		return mediaType
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,374 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"messaging-service/internal/domain"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// CircuitState is the state of a circuit breaker
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitHalfOpen
+	CircuitOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitHalfOpen:
+		return "half_open"
+	case CircuitOpen:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerConfig holds circuit breaker configuration
+type CircuitBreakerConfig struct {
+	// Window is the period over which the failure rate is measured
+	Window time.Duration
+	// MinRequests is the number of calls in the window before the breaker may open
+	MinRequests int
+	// FailureRate is the fraction of failed calls in the window that opens the breaker
+	FailureRate float64
+	// CoolDown is how long the breaker stays open before letting trial calls through
+	CoolDown time.Duration
+	// HalfOpenRequests is the number of concurrent trial calls allowed while half-open
+	HalfOpenRequests int
+}
+
+// DefaultCircuitBreakerConfig returns default circuit breaker configuration
+func DefaultCircuitBreakerConfig() CircuitBreakerConfig {
+	return CircuitBreakerConfig{
+		Window:           30 * time.Second,
+		MinRequests:      10,
+		FailureRate:      0.5,
+		CoolDown:         30 * time.Second,
+		HalfOpenRequests: 1,
+	}
+}
+
+// callOutcome is a completed call recorded in the failure-rate window
+type callOutcome struct {
+	at     time.Time
+	failed bool
+}
+
+// CircuitBreaker stops calling a provider that keeps failing and probes it again after a cool-down
+type CircuitBreaker struct {
+	name   string
+	config CircuitBreakerConfig
+	now    func() time.Time
+
+	mu               sync.Mutex
+	state            CircuitState
+	openedAt         time.Time
+	outcomes         []callOutcome
+	halfOpenInFlight int
+	// generation changes on every state transition, so calls admitted before one can be told apart
+	generation uint64
+}
+
+// NewCircuitBreaker creates a new circuit breaker
+func NewCircuitBreaker(name string, config CircuitBreakerConfig) *CircuitBreaker {
+	return &CircuitBreaker{
+		name:   name,
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// Name returns the name of the breaker
+func (b *CircuitBreaker) Name() string {
+	return b.name
+}
+
+// State returns the current state, moving an open breaker to half-open once the cool-down has passed
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refreshState()
+	return b.state
+}
+
+// Healthy reports whether the breaker currently lets calls through
+func (b *CircuitBreaker) Healthy() bool {
+	return b.State() != CircuitOpen
+}
+
+// Execute runs call unless the breaker is open, and records its outcome
+func (b *CircuitBreaker) Execute(ctx context.Context, call func() error) error {
+	generation, err := b.allow()
+	if err != nil {
+		return err
+	}
+
+	err = call()
+	b.record(ctx, generation, err)
+	return err
+}
+
+// allow reserves a call slot or fails fast when the breaker is open. It returns the
+// generation the call was admitted in, which record uses to spot stale outcomes.
+func (b *CircuitBreaker) allow() (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refreshState()
+
+	switch b.state {
+	case CircuitOpen:
+		return 0, fmt.Errorf("provider %s: %w", b.name, domain.ErrCircuitOpen)
+	case CircuitHalfOpen:
+		if b.halfOpenInFlight >= b.config.HalfOpenRequests {
+			return 0, fmt.Errorf("provider %s: %w", b.name, domain.ErrCircuitOpen)
+		}
+		b.halfOpenInFlight++
+	}
+	return b.generation, nil
+}
+
+// record stores the outcome of a call admitted in generation and updates the state
+func (b *CircuitBreaker) record(ctx context.Context, generation uint64, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Calls admitted before the last state transition, such as slow calls that were in
+	// flight when the breaker opened, say nothing about the current state
+	if generation != b.generation {
+		return
+	}
+
+	// Cancelled calls, rejected requests and local errors say nothing about the provider's health
+	failed := err != nil && ctx.Err() == nil && isProviderFailure(err)
+	neutral := err != nil && !failed
+
+	// Only trial calls are admitted in a half-open generation
+	if b.state == CircuitHalfOpen {
+		b.halfOpenInFlight--
+		switch {
+		case neutral:
+		case failed:
+			b.open()
+		default:
+			b.close()
+		}
+		return
+	}
+
+	if neutral {
+		return
+	}
+
+	now := b.now()
+	b.outcomes = append(b.outcomes, callOutcome{at: now, failed: failed})
+	b.pruneOutcomes(now)
+
+	if b.state == CircuitClosed && len(b.outcomes) >= b.config.MinRequests {
+		failures := 0
+		for _, outcome := range b.outcomes {
+			if outcome.failed {
+				failures++
+			}
+		}
+		if float64(failures)/float64(len(b.outcomes)) >= b.config.FailureRate {
+			b.open()
+		}
+	}
+}
+
+// refreshState moves an open breaker to half-open once the cool-down has passed
+func (b *CircuitBreaker) refreshState() {
+	if b.state == CircuitOpen && b.now().Sub(b.openedAt) >= b.config.CoolDown {
+		b.state = CircuitHalfOpen
+		b.halfOpenInFlight = 0
+		b.generation++
+	}
+}
+
+func (b *CircuitBreaker) open() {
+	b.state = CircuitOpen
+	b.generation++
+	b.openedAt = b.now()
+	b.outcomes = nil
+}
+
+func (b *CircuitBreaker) close() {
+	b.state = CircuitClosed
+	b.generation++
+	b.outcomes = nil
+}
+
+// pruneOutcomes drops outcomes that fell out of the window
+func (b *CircuitBreaker) pruneOutcomes(now time.Time) {
+	cutoff := now.Add(-b.config.Window)
+	keep := 0
+	for keep < len(b.outcomes) && b.outcomes[keep].at.Before(cutoff) {
+		keep++
+	}
+	b.outcomes = b.outcomes[keep:]
+}
+
+// isProviderFailure reports whether err indicates the provider is unavailable: a transport error
+// or a 5xx or 408 response. Other 4xx responses, including 429, and local errors such as invalid
+// attachments or rate limiter waits are specific to the request or the sender.
+func isProviderFailure(err error) bool {
+	var providerErr *domain.ProviderError
+	if errors.As(err, &providerErr) {
+		return providerErr.Code >= http.StatusInternalServerError || providerErr.Code == http.StatusRequestTimeout
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// CircuitBreakerSMSProvider wraps an SMS provider with a circuit breaker
+type CircuitBreakerSMSProvider struct {
+	provider domain.SMSProvider
+	breaker  *CircuitBreaker
+}
+
+// NewCircuitBreakerSMSProvider creates an SMS provider guarded by the given breaker
+func NewCircuitBreakerSMSProvider(provider domain.SMSProvider, breaker *CircuitBreaker) *CircuitBreakerSMSProvider {
+	return &CircuitBreakerSMSProvider{provider: provider, breaker: breaker}
+}
+
+func (p *CircuitBreakerSMSProvider) SendSMS(ctx context.Context, from, to, body string) (*domain.SendResult, error) {
+	var result *domain.SendResult
+	err := p.breaker.Execute(ctx, func() error {
+		var err error
+		result, err = p.provider.SendSMS(ctx, from, to, body)
+		return err
+	})
+	return result, err
+}
+
+func (p *CircuitBreakerSMSProvider) SendMMS(ctx context.Context, from, to, body string, attachments []string) (*domain.SendResult, error) {
+	var result *domain.SendResult
+	err := p.breaker.Execute(ctx, func() error {
+		var err error
+		result, err = p.provider.SendMMS(ctx, from, to, body, attachments)
+		return err
+	})
+	return result, err
+}
+
+// Healthy reports whether the breaker lets calls through
+func (p *CircuitBreakerSMSProvider) Healthy() bool {
+	return p.breaker.Healthy()
+}
+
+// CircuitBreakerEmailProvider wraps an email provider with a circuit breaker
+type CircuitBreakerEmailProvider struct {
+	provider domain.EmailProvider
+	breaker  *CircuitBreaker
+}
+
+// NewCircuitBreakerEmailProvider creates an email provider guarded by the given breaker
+func NewCircuitBreakerEmailProvider(provider domain.EmailProvider, breaker *CircuitBreaker) *CircuitBreakerEmailProvider {
+	return &CircuitBreakerEmailProvider{provider: provider, breaker: breaker}
+}
+
+func (p *CircuitBreakerEmailProvider) SendEmail(ctx context.Context, from, to, body string, attachments []string) (*domain.SendResult, error) {
+	var result *domain.SendResult
+	err := p.breaker.Execute(ctx, func() error {
+		var err error
+		result, err = p.provider.SendEmail(ctx, from, to, body, attachments)
+		return err
+	})
+	return result, err
+}
+
+// Healthy reports whether the breaker lets calls through
+func (p *CircuitBreakerEmailProvider) Healthy() bool {
+	return p.breaker.Healthy()
+}
+
+// CircuitBreakerRegistry keeps the breakers of all providers and reports their state
+type CircuitBreakerRegistry struct {
+	config CircuitBreakerConfig
+
+	mu       sync.RWMutex
+	breakers map[string]*CircuitBreaker
+}
+
+// NewCircuitBreakerRegistry creates a registry and exposes breaker state as an OpenTelemetry gauge
+func NewCircuitBreakerRegistry(config CircuitBreakerConfig) *CircuitBreakerRegistry {
+	registry := &CircuitBreakerRegistry{
+		config:   config,
+		breakers: make(map[string]*CircuitBreaker),
+	}
+
+	meter := otel.GetMeterProvider().Meter("messaging-service")
+	_, _ = meter.Int64ObservableGauge("provider_circuit_breaker_state",
+		metric.WithDescription("Provider circuit breaker state (0 = closed, 1 = half-open, 2 = open)"),
+		metric.WithUnit("1"),
+		metric.WithInt64Callback(func(_ context.Context, observer metric.Int64Observer) error {
+			for name, state := range registry.snapshot() {
+				// Breakers are named channel:provider, e.g. sms:twilio
+				channel, providerName, ok := strings.Cut(name, ":")
+				if !ok {
+					channel, providerName = "", name
+				}
+				observer.Observe(int64(state), metric.WithAttributes(
+					attribute.String("channel", channel),
+					attribute.String("provider", providerName),
+				))
+			}
+			return nil
+		}),
+	)
+
+	return registry
+}
+
+// Breaker returns the breaker registered under name, creating it on first use
+func (r *CircuitBreakerRegistry) Breaker(name string) *CircuitBreaker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if breaker, ok := r.breakers[name]; ok {
+		return breaker
+	}
+	breaker := NewCircuitBreaker(name, r.config)
+	r.breakers[name] = breaker
+	return breaker
+}
+
+// States returns the state of every registered breaker by name
+func (r *CircuitBreakerRegistry) States() map[string]string {
+	states := make(map[string]string)
+	for name, state := range r.snapshot() {
+		states[name] = state.String()
+	}
+	return states
+}
+
+func (r *CircuitBreakerRegistry) snapshot() map[string]CircuitState {
+	r.mu.RLock()
+	breakers := make([]*CircuitBreaker, 0, len(r.breakers))
+	for _, breaker := range r.breakers {
+		breakers = append(breakers, breaker)
+	}
+	r.mu.RUnlock()
+
+	states := make(map[string]CircuitState, len(breakers))
+	for _, breaker := range breakers {
+		states[breaker.Name()] = breaker.State()
+	}
+	return states
+}
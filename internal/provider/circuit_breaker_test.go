@@ -0,0 +1,279 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"messaging-service/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// switchableSMSProvider fails with err until it is cleared
+type switchableSMSProvider struct {
+	err   error
+	calls int
+}
+
+func (p *switchableSMSProvider) SendSMS(ctx context.Context, from, to, body string) (*domain.SendResult, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &domain.SendResult{ProviderMessageID: "SM123", Segments: 1}, nil
+}
+
+func (p *switchableSMSProvider) SendMMS(ctx context.Context, from, to, body string, attachments []string) (*domain.SendResult, error) {
+	return p.SendSMS(ctx, from, to, body)
+}
+
+// testBreaker returns a breaker with a controllable clock
+func testBreaker(config CircuitBreakerConfig) (*CircuitBreaker, *time.Time) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	breaker := NewCircuitBreaker("sms:test", config)
+	breaker.now = func() time.Time { return now }
+	return breaker, &now
+}
+
+// errConnectionReset is a transport error, as returned before any response is received
+var errConnectionReset = &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}
+
+func testBreakerConfig() CircuitBreakerConfig {
+	return CircuitBreakerConfig{
+		Window:           time.Minute,
+		MinRequests:      4,
+		FailureRate:      0.5,
+		CoolDown:         30 * time.Second,
+		HalfOpenRequests: 1,
+	}
+}
+
+func sendN(t *testing.T, p domain.SMSProvider, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		_, _ = p.SendSMS(context.Background(), "+12016661234", "+18045551234", "Hello")
+	}
+}
+
+func TestCircuitBreaker_OpensOnFailureRate(t *testing.T) {
+	breaker, _ := testBreaker(testBreakerConfig())
+	upstream := &switchableSMSProvider{}
+	p := NewCircuitBreakerSMSProvider(upstream, breaker)
+
+	sendN(t, p, 2)
+	upstream.err = &domain.ProviderError{Code: http.StatusServiceUnavailable, Message: "down"}
+	sendN(t, p, 1)
+	assert.Equal(t, CircuitClosed, breaker.State(), "below the minimum request count")
+
+	sendN(t, p, 1)
+	assert.Equal(t, CircuitOpen, breaker.State())
+	assert.False(t, p.Healthy())
+
+	// Open breakers fail fast without calling the provider
+	calls := upstream.calls
+	_, err := p.SendSMS(context.Background(), "+12016661234", "+18045551234", "Hello")
+	assert.ErrorIs(t, err, domain.ErrCircuitOpen)
+	assert.Equal(t, calls, upstream.calls)
+}
+
+func TestCircuitBreaker_IgnoresPermanentErrors(t *testing.T) {
+	breaker, _ := testBreaker(testBreakerConfig())
+	upstream := &switchableSMSProvider{err: &domain.ProviderError{Code: http.StatusBadRequest, Message: "invalid number"}}
+	p := NewCircuitBreakerSMSProvider(upstream, breaker)
+
+	sendN(t, p, 10)
+
+	assert.Equal(t, CircuitClosed, breaker.State())
+	assert.Equal(t, 10, upstream.calls)
+}
+
+func TestCircuitBreaker_IgnoresRateLimitsAndLocalErrors(t *testing.T) {
+	breaker, _ := testBreaker(testBreakerConfig())
+	errs := []error{
+		&domain.ProviderError{Code: http.StatusTooManyRequests, Message: "rate limited", RetryAfter: 1},
+		fmt.Errorf("send failed: %w", &domain.ProviderError{Code: http.StatusTooManyRequests, Message: "rate limited"}),
+		errors.New("invalid attachment URL"),
+		errors.New("failed to read attachment: unexpected EOF"),
+	}
+
+	for i := 0; i < 3; i++ {
+		for _, err := range errs {
+			_ = breaker.Execute(context.Background(), func() error { return err })
+		}
+	}
+
+	assert.Equal(t, CircuitClosed, breaker.State())
+}
+
+func TestCircuitBreaker_CountsTimeoutsAndWrappedOutages(t *testing.T) {
+	breaker, _ := testBreaker(testBreakerConfig())
+	errs := []error{
+		&domain.ProviderError{Code: http.StatusRequestTimeout, Message: "timeout"},
+		fmt.Errorf("send failed: %w", &domain.ProviderError{Code: http.StatusBadGateway, Message: "bad gateway"}),
+		fmt.Errorf("send failed: %w", errConnectionReset),
+		&domain.ProviderError{Code: http.StatusInternalServerError, Message: "boom"},
+	}
+
+	for _, err := range errs {
+		_ = breaker.Execute(context.Background(), func() error { return err })
+	}
+
+	assert.Equal(t, CircuitOpen, breaker.State())
+}
+
+func TestCircuitBreaker_HalfOpenIgnoresNeutralTrialCalls(t *testing.T) {
+	breaker, now := testBreaker(testBreakerConfig())
+	for i := 0; i < 4; i++ {
+		_ = breaker.Execute(context.Background(), func() error { return errConnectionReset })
+	}
+	*now = now.Add(30 * time.Second)
+
+	// A trial call that fails locally frees its slot without deciding the state
+	_ = breaker.Execute(context.Background(), func() error { return errors.New("invalid attachment URL") })
+	assert.Equal(t, CircuitHalfOpen, breaker.State())
+
+	require.NoError(t, breaker.Execute(context.Background(), func() error { return nil }))
+	assert.Equal(t, CircuitClosed, breaker.State())
+}
+
+func TestCircuitBreaker_IgnoresCancelledCalls(t *testing.T) {
+	breaker, _ := testBreaker(testBreakerConfig())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for i := 0; i < 10; i++ {
+		_ = breaker.Execute(ctx, func() error { return ctx.Err() })
+	}
+
+	assert.Equal(t, CircuitClosed, breaker.State())
+}
+
+func TestCircuitBreaker_ForgetsFailuresOutsideWindow(t *testing.T) {
+	breaker, now := testBreaker(testBreakerConfig())
+	failure := errConnectionReset
+
+	for i := 0; i < 3; i++ {
+		_ = breaker.Execute(context.Background(), func() error { return failure })
+	}
+	*now = now.Add(2 * time.Minute)
+	_ = breaker.Execute(context.Background(), func() error { return failure })
+
+	assert.Equal(t, CircuitClosed, breaker.State())
+}
+
+func TestCircuitBreaker_HalfOpenRecovery(t *testing.T) {
+	breaker, now := testBreaker(testBreakerConfig())
+	upstream := &switchableSMSProvider{err: &domain.ProviderError{Code: http.StatusInternalServerError, Message: "boom"}}
+	p := NewCircuitBreakerSMSProvider(upstream, breaker)
+
+	sendN(t, p, 4)
+	require.Equal(t, CircuitOpen, breaker.State())
+
+	// A failed trial call reopens the breaker for another cool-down
+	*now = now.Add(30 * time.Second)
+	assert.Equal(t, CircuitHalfOpen, breaker.State())
+	sendN(t, p, 1)
+	assert.Equal(t, CircuitOpen, breaker.State())
+
+	*now = now.Add(29 * time.Second)
+	assert.Equal(t, CircuitOpen, breaker.State())
+
+	// A successful trial call closes it
+	*now = now.Add(time.Second)
+	upstream.err = nil
+	result, err := p.SendSMS(context.Background(), "+12016661234", "+18045551234", "Hello")
+	require.NoError(t, err)
+	assert.Equal(t, "SM123", result.ProviderMessageID)
+	assert.Equal(t, CircuitClosed, breaker.State())
+}
+
+func TestCircuitBreaker_LimitsHalfOpenTrialCalls(t *testing.T) {
+	breaker, now := testBreaker(testBreakerConfig())
+	failure := errConnectionReset
+	for i := 0; i < 4; i++ {
+		_ = breaker.Execute(context.Background(), func() error { return failure })
+	}
+	*now = now.Add(30 * time.Second)
+
+	// While the trial call is in flight, other calls are rejected
+	err := breaker.Execute(context.Background(), func() error {
+		return breaker.Execute(context.Background(), func() error { return nil })
+	})
+
+	assert.ErrorIs(t, err, domain.ErrCircuitOpen)
+}
+
+func TestCircuitBreaker_IgnoresCallsStraddlingATrip(t *testing.T) {
+	breaker, now := testBreaker(testBreakerConfig())
+	failure := errConnectionReset
+
+	// A slow call admitted while closed is still in flight when the breaker opens and
+	// the cool-down passes, and a trial call has been let through
+	var trialGeneration uint64
+	err := breaker.Execute(context.Background(), func() error {
+		for i := 0; i < 4; i++ {
+			_ = breaker.Execute(context.Background(), func() error { return failure })
+		}
+		*now = now.Add(30 * time.Second)
+		require.Equal(t, CircuitHalfOpen, breaker.State())
+
+		var err error
+		trialGeneration, err = breaker.allow()
+		require.NoError(t, err)
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Its success neither closes the breaker nor frees the trial slot
+	assert.Equal(t, CircuitHalfOpen, breaker.State())
+	_, err = breaker.allow()
+	assert.ErrorIs(t, err, domain.ErrCircuitOpen)
+
+	// The trial call decides
+	breaker.record(context.Background(), trialGeneration, nil)
+	assert.Equal(t, CircuitClosed, breaker.State())
+}
+
+func TestFailoverSMSProvider_SkipsOpenCircuit(t *testing.T) {
+	breaker, _ := testBreaker(testBreakerConfig())
+	primary := &switchableSMSProvider{err: &domain.ProviderError{Code: http.StatusServiceUnavailable, Message: "down"}}
+	secondary := NewMockSMSProvider()
+
+	failover, err := NewFailoverSMSProvider([]NamedSMSProvider{
+		{Name: "primary", Provider: NewCircuitBreakerSMSProvider(primary, breaker)},
+		{Name: "secondary", Provider: secondary},
+	}, nil)
+	require.NoError(t, err)
+
+	sendN(t, failover, 4)
+	require.Equal(t, CircuitOpen, breaker.State())
+
+	calls := primary.calls
+	result, err := failover.SendSMS(context.Background(), "+12016661234", "+18045551234", "Hello")
+	require.NoError(t, err)
+	assert.Equal(t, "secondary", result.Provider)
+	assert.Equal(t, calls, primary.calls)
+}
+
+func TestCircuitBreakerRegistry_States(t *testing.T) {
+	registry := NewCircuitBreakerRegistry(testBreakerConfig())
+	sms := registry.Breaker("sms:twilio")
+	registry.Breaker("email:sendgrid")
+	assert.Same(t, sms, registry.Breaker("sms:twilio"))
+
+	failure := errConnectionReset
+	for i := 0; i < 4; i++ {
+		_ = sms.Execute(context.Background(), func() error { return failure })
+	}
+
+	assert.Equal(t, map[string]string{
+		"sms:twilio":     "open",
+		"email:sendgrid": "closed",
+	}, registry.States())
+}
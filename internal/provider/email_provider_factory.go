@@ -10,14 +10,31 @@ type EmailProviderType string
 const (
 	EmailProviderMock     EmailProviderType = "mock"
 	EmailProviderSendGrid EmailProviderType = "sendgrid"
+	EmailProviderSMTP     EmailProviderType = "smtp"
 )
 
+// DefaultEmailSubject is the subject line used for outbound emails unless configured otherwise
+const DefaultEmailSubject = "New message"
+
 // NewEmailProvider creates an email provider based on the specified type
 func NewEmailProvider(providerType EmailProviderType, config map[string]string) domain.EmailProvider {
 	switch providerType {
 	case EmailProviderSendGrid:
-		apiKey := config["api_key"]
-		return NewSendGridEmailProvider(apiKey)
+		return NewSendGridEmailProviderWithConfig(SendGridConfig{
+			APIKey:  config["api_key"],
+			BaseURL: config["base_url"],
+			Subject: config["subject"],
+		})
+	case EmailProviderSMTP:
+		return NewSMTPEmailProvider(SMTPConfig{
+			Host:     config["host"],
+			Port:     config["port"],
+			Username: config["username"],
+			Password: config["password"],
+			TLSMode:  config["tls_mode"],
+			Auth:     config["auth"],
+			Subject:  config["subject"],
+		})
 	case EmailProviderMock:
 		fallthrough
 	default:
@@ -0,0 +1,291 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"messaging-service/internal/domain"
+)
+
+// Routing rule fields
+const (
+	RouteByTo   = "to"
+	RouteByFrom = "from"
+)
+
+// RoutingRule prefers a provider for messages whose To or From address starts with Prefix
+type RoutingRule struct {
+	Field    string
+	Prefix   string
+	Provider string
+}
+
+// HealthReporter is implemented by providers that know whether they can currently accept traffic
+type HealthReporter interface {
+	Healthy() bool
+}
+
+// NamedSMSProvider is an SMS provider registered under a name for routing
+type NamedSMSProvider struct {
+	Name     string
+	Provider domain.SMSProvider
+}
+
+// NamedEmailProvider is an email provider registered under a name for routing
+type NamedEmailProvider struct {
+	Name     string
+	Provider domain.EmailProvider
+}
+
+// providerRouter orders provider names for a message according to routing rules and health
+type providerRouter struct {
+	names     []string
+	rules     []RoutingRule
+	reporters map[string]HealthReporter
+
+	mu        sync.RWMutex
+	unhealthy map[string]bool
+}
+
+func newProviderRouter(names []string, rules []RoutingRule, reporters map[string]HealthReporter) *providerRouter {
+	return &providerRouter{
+		names:     names,
+		rules:     rules,
+		reporters: reporters,
+		unhealthy: make(map[string]bool),
+	}
+}
+
+// candidates returns providers matching a rule first, followed by the remaining providers in configured order
+func (r *providerRouter) candidates(from, to string) []string {
+	ordered := make([]string, 0, len(r.names))
+	seen := make(map[string]bool, len(r.names))
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			ordered = append(ordered, name)
+		}
+	}
+
+	for _, rule := range r.rules {
+		address := to
+		if rule.Field == RouteByFrom {
+			address = from
+		}
+		if strings.HasPrefix(address, rule.Prefix) {
+			add(rule.Provider)
+		}
+	}
+	for _, name := range r.names {
+		add(name)
+	}
+
+	return ordered
+}
+
+func (r *providerRouter) setHealthy(name string, healthy bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if healthy {
+		delete(r.unhealthy, name)
+	} else {
+		r.unhealthy[name] = true
+	}
+}
+
+func (r *providerRouter) isHealthy(name string) bool {
+	r.mu.RLock()
+	markedUnhealthy := r.unhealthy[name]
+	r.mu.RUnlock()
+	if markedUnhealthy {
+		return false
+	}
+	if reporter, ok := r.reporters[name]; ok {
+		return reporter.Healthy()
+	}
+	return true
+}
+
+// send tries each healthy candidate in turn, moving on only when the failure is retryable or the breaker is open
+func (r *providerRouter) send(ctx context.Context, from, to string, sendFunc func(name string) (*domain.SendResult, error)) (*domain.SendResult, error) {
+	var lastErr error
+	for _, name := range r.candidates(from, to) {
+		if !r.isHealthy(name) {
+			continue
+		}
+
+		result, err := sendFunc(name)
+		// Attribute failures to the configured provider name, like successful sends
+		err = attributeError(err, name)
+		if err == nil {
+			if result == nil {
+				result = &domain.SendResult{}
+			}
+			// Record the configured provider name so the message shows which route was taken
+			result.Provider = name
+			return result, nil
+		}
+
+		// A breaker that opened since the health check is skipped like an unhealthy provider
+		if ctx.Err() != nil || !(domain.IsRetryableError(err) || errors.Is(err, domain.ErrCircuitOpen)) {
+			return nil, err
+		}
+		lastErr = err
+	}
+
+	// Every provider was skipped, so fail fast the same way an open breaker does
+	if lastErr == nil {
+		return nil, fmt.Errorf("no healthy provider available: %w", domain.ErrCircuitOpen)
+	}
+	return nil, lastErr
+}
+
+// attributeError names the provider in the ProviderError err holds, which decorators may have wrapped
+func attributeError(err error, name string) error {
+	var providerErr *domain.ProviderError
+	if !errors.As(err, &providerErr) {
+		return err
+	}
+
+	named := *providerErr
+	named.Provider = name
+	if err == error(providerErr) {
+		return &named
+	}
+	return &attributedError{err: err, named: &named}
+}
+
+// attributedError keeps a wrapped error chain intact while errors.As finds the named ProviderError
+type attributedError struct {
+	err   error
+	named *domain.ProviderError
+}
+
+func (e *attributedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *attributedError) Unwrap() error {
+	return e.err
+}
+
+func (e *attributedError) As(target interface{}) bool {
+	if providerErr, ok := target.(**domain.ProviderError); ok {
+		*providerErr = e.named
+		return true
+	}
+	return false
+}
+
+// FailoverSMSProvider routes SMS/MMS through an ordered list of providers, failing over on retryable errors
+type FailoverSMSProvider struct {
+	providers map[string]domain.SMSProvider
+	router    *providerRouter
+}
+
+// NewFailoverSMSProvider creates an SMS provider that fails over between the given providers
+func NewFailoverSMSProvider(providers []NamedSMSProvider, rules []RoutingRule) (*FailoverSMSProvider, error) {
+	names, err := routeNames(len(providers), func(i int) string { return providers[i].Name }, rules)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]domain.SMSProvider, len(providers))
+	reporters := make(map[string]HealthReporter)
+	for _, p := range providers {
+		byName[p.Name] = p.Provider
+		if reporter, ok := p.Provider.(HealthReporter); ok {
+			reporters[p.Name] = reporter
+		}
+	}
+
+	return &FailoverSMSProvider{providers: byName, router: newProviderRouter(names, rules, reporters)}, nil
+}
+
+func (p *FailoverSMSProvider) SendSMS(ctx context.Context, from, to, body string) (*domain.SendResult, error) {
+	return p.router.send(ctx, from, to, func(name string) (*domain.SendResult, error) {
+		return p.providers[name].SendSMS(ctx, from, to, body)
+	})
+}
+
+func (p *FailoverSMSProvider) SendMMS(ctx context.Context, from, to, body string, attachments []string) (*domain.SendResult, error) {
+	return p.router.send(ctx, from, to, func(name string) (*domain.SendResult, error) {
+		return p.providers[name].SendMMS(ctx, from, to, body, attachments)
+	})
+}
+
+// SetHealthy marks a provider as healthy or unhealthy; unhealthy providers are skipped
+func (p *FailoverSMSProvider) SetHealthy(name string, healthy bool) {
+	p.router.setHealthy(name, healthy)
+}
+
+// FailoverEmailProvider routes email through an ordered list of providers, failing over on retryable errors
+type FailoverEmailProvider struct {
+	providers map[string]domain.EmailProvider
+	router    *providerRouter
+}
+
+// NewFailoverEmailProvider creates an email provider that fails over between the given providers
+func NewFailoverEmailProvider(providers []NamedEmailProvider, rules []RoutingRule) (*FailoverEmailProvider, error) {
+	names, err := routeNames(len(providers), func(i int) string { return providers[i].Name }, rules)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]domain.EmailProvider, len(providers))
+	reporters := make(map[string]HealthReporter)
+	for _, p := range providers {
+		byName[p.Name] = p.Provider
+		if reporter, ok := p.Provider.(HealthReporter); ok {
+			reporters[p.Name] = reporter
+		}
+	}
+
+	return &FailoverEmailProvider{providers: byName, router: newProviderRouter(names, rules, reporters)}, nil
+}
+
+func (p *FailoverEmailProvider) SendEmail(ctx context.Context, from, to, body string, attachments []string) (*domain.SendResult, error) {
+	return p.router.send(ctx, from, to, func(name string) (*domain.SendResult, error) {
+		return p.providers[name].SendEmail(ctx, from, to, body, attachments)
+	})
+}
+
+// SetHealthy marks a provider as healthy or unhealthy; unhealthy providers are skipped
+func (p *FailoverEmailProvider) SetHealthy(name string, healthy bool) {
+	p.router.setHealthy(name, healthy)
+}
+
+// routeNames validates provider names and rules and returns the names in configured order
+func routeNames(count int, nameAt func(int) string, rules []RoutingRule) ([]string, error) {
+	if count == 0 {
+		return nil, fmt.Errorf("at least one provider is required")
+	}
+
+	names := make([]string, 0, count)
+	known := make(map[string]bool, count)
+	for i := 0; i < count; i++ {
+		name := nameAt(i)
+		if name == "" {
+			return nil, fmt.Errorf("provider name cannot be empty")
+		}
+		if known[name] {
+			return nil, fmt.Errorf("duplicate provider: %s", name)
+		}
+		known[name] = true
+		names = append(names, name)
+	}
+
+	for _, rule := range rules {
+		if rule.Field != RouteByTo && rule.Field != RouteByFrom {
+			return nil, fmt.Errorf("invalid routing rule field: %s", rule.Field)
+		}
+		if !known[rule.Provider] {
+			return nil, fmt.Errorf("routing rule references unknown provider: %s", rule.Provider)
+		}
+	}
+
+	return names, nil
+}
@@ -0,0 +1,172 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"messaging-service/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unhealthyProvider reports itself as unhealthy to the failover provider
+type unhealthyProvider struct {
+	*MockSMSProvider
+}
+
+func (p *unhealthyProvider) Healthy() bool {
+	return false
+}
+
+func TestFailoverSMSProvider_FailsOverOnRetryableError(t *testing.T) {
+	primary := NewMockSMSProviderWithErrorCode(500)
+	secondary := NewMockSMSProvider()
+
+	failover, err := NewFailoverSMSProvider([]NamedSMSProvider{
+		{Name: "primary", Provider: primary},
+		{Name: "secondary", Provider: secondary},
+	}, nil)
+	require.NoError(t, err)
+
+	result, err := failover.SendSMS(context.Background(), "+12016661234", "+18045551234", "Hello")
+
+	require.NoError(t, err)
+	assert.Equal(t, "secondary", result.Provider)
+	assert.Len(t, secondary.(*MockSMSProvider).GetMessages(), 1)
+}
+
+func TestFailoverSMSProvider_StopsOnPermanentError(t *testing.T) {
+	primary := NewMockSMSProviderWithErrorCode(400)
+	secondary := NewMockSMSProvider()
+
+	failover, err := NewFailoverSMSProvider([]NamedSMSProvider{
+		{Name: "primary", Provider: primary},
+		{Name: "secondary", Provider: secondary},
+	}, nil)
+	require.NoError(t, err)
+
+	_, err = failover.SendSMS(context.Background(), "+12016661234", "+18045551234", "Hello")
+
+	assert.Error(t, err)
+	assert.Empty(t, secondary.(*MockSMSProvider).GetMessages())
+}
+
+func TestFailoverSMSProvider_ReturnsLastErrorWhenAllFail(t *testing.T) {
+	failover, err := NewFailoverSMSProvider([]NamedSMSProvider{
+		{Name: "primary", Provider: NewMockSMSProviderWithErrorCode(500)},
+		{Name: "secondary", Provider: NewMockSMSProviderWithErrorCode(429)},
+	}, nil)
+	require.NoError(t, err)
+
+	_, err = failover.SendMMS(context.Background(), "+12016661234", "+18045551234", "Hello", []string{"https://example.com/a.jpg"})
+
+	var providerErr *domain.ProviderError
+	require.ErrorAs(t, err, &providerErr)
+	assert.Equal(t, 429, providerErr.Code)
+	assert.Equal(t, "secondary", providerErr.Provider)
+}
+
+func TestFailoverSMSProvider_NamesWrappedProviderErrors(t *testing.T) {
+	wrapped := &switchableSMSProvider{err: fmt.Errorf("send failed: %w", &domain.ProviderError{Code: 400, Message: "invalid number"})}
+	failover, err := NewFailoverSMSProvider([]NamedSMSProvider{
+		{Name: "primary", Provider: wrapped},
+	}, nil)
+	require.NoError(t, err)
+
+	_, err = failover.SendSMS(context.Background(), "+12016661234", "+18045551234", "Hello")
+
+	var providerErr *domain.ProviderError
+	require.ErrorAs(t, err, &providerErr)
+	assert.Equal(t, 400, providerErr.Code)
+	assert.Equal(t, "primary", providerErr.Provider)
+	assert.Contains(t, err.Error(), "send failed")
+}
+
+func TestFailoverSMSProvider_RoutingRules(t *testing.T) {
+	primary := NewMockSMSProvider()
+	uk := NewMockSMSProvider()
+	tollFree := NewMockSMSProvider()
+
+	failover, err := NewFailoverSMSProvider([]NamedSMSProvider{
+		{Name: "primary", Provider: primary},
+		{Name: "uk", Provider: uk},
+		{Name: "toll-free", Provider: tollFree},
+	}, []RoutingRule{
+		{Field: RouteByTo, Prefix: "+44", Provider: "uk"},
+		{Field: RouteByFrom, Prefix: "+1800", Provider: "toll-free"},
+	})
+	require.NoError(t, err)
+
+	result, err := failover.SendSMS(context.Background(), "+12016661234", "+447700900123", "Hello")
+	require.NoError(t, err)
+	assert.Equal(t, "uk", result.Provider)
+
+	result, err = failover.SendSMS(context.Background(), "+18005550000", "+18045551234", "Hello")
+	require.NoError(t, err)
+	assert.Equal(t, "toll-free", result.Provider)
+
+	result, err = failover.SendSMS(context.Background(), "+12016661234", "+18045551234", "Hello")
+	require.NoError(t, err)
+	assert.Equal(t, "primary", result.Provider)
+}
+
+func TestFailoverSMSProvider_SkipsUnhealthyProviders(t *testing.T) {
+	primary := NewMockSMSProvider()
+	secondary := NewMockSMSProvider()
+	reporting := &unhealthyProvider{MockSMSProvider: NewMockSMSProvider().(*MockSMSProvider)}
+
+	failover, err := NewFailoverSMSProvider([]NamedSMSProvider{
+		{Name: "reporting", Provider: reporting},
+		{Name: "primary", Provider: primary},
+		{Name: "secondary", Provider: secondary},
+	}, nil)
+	require.NoError(t, err)
+
+	failover.SetHealthy("primary", false)
+	result, err := failover.SendSMS(context.Background(), "+12016661234", "+18045551234", "Hello")
+	require.NoError(t, err)
+	assert.Equal(t, "secondary", result.Provider)
+	assert.Empty(t, reporting.GetMessages())
+
+	failover.SetHealthy("primary", true)
+	result, err = failover.SendSMS(context.Background(), "+12016661234", "+18045551234", "Hello")
+	require.NoError(t, err)
+	assert.Equal(t, "primary", result.Provider)
+
+	failover.SetHealthy("primary", false)
+	failover.SetHealthy("secondary", false)
+	_, err = failover.SendSMS(context.Background(), "+12016661234", "+18045551234", "Hello")
+	assert.ErrorIs(t, err, domain.ErrCircuitOpen)
+}
+
+func TestFailoverEmailProvider_FailsOver(t *testing.T) {
+	backup := NewMockEmailProvider()
+
+	failover, err := NewFailoverEmailProvider([]NamedEmailProvider{
+		{Name: "sendgrid", Provider: NewMockEmailProviderWithErrorCode(500)},
+		{Name: "smtp", Provider: backup},
+	}, nil)
+	require.NoError(t, err)
+
+	result, err := failover.SendEmail(context.Background(), "user@usehatchapp.com", "contact@gmail.com", "Hello", nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "smtp", result.Provider)
+	assert.Len(t, backup.(*MockEmailProvider).GetMessages(), 1)
+}
+
+func TestNewFailoverSMSProvider_InvalidConfiguration(t *testing.T) {
+	_, err := NewFailoverSMSProvider(nil, nil)
+	assert.Error(t, err)
+
+	_, err = NewFailoverSMSProvider([]NamedSMSProvider{{Name: "a", Provider: NewMockSMSProvider()}, {Name: "a", Provider: NewMockSMSProvider()}}, nil)
+	assert.Error(t, err)
+
+	_, err = NewFailoverSMSProvider([]NamedSMSProvider{{Name: "a", Provider: NewMockSMSProvider()}}, []RoutingRule{{Field: RouteByTo, Prefix: "+44", Provider: "b"}})
+	assert.Error(t, err)
+
+	_, err = NewFailoverSMSProvider([]NamedSMSProvider{{Name: "a", Provider: NewMockSMSProvider()}}, []RoutingRule{{Field: "body", Prefix: "x", Provider: "a"}})
+	assert.Error(t, err)
+}
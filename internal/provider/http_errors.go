@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"messaging-service/internal/domain"
+)
+
+// parseRetryAfter converts a Retry-After header (delay in seconds or HTTP date) to seconds
+func parseRetryAfter(header string, now time.Time) int {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return seconds
+	}
+
+	if at, err := http.ParseTime(header); err == nil {
+		if delay := at.Sub(now); delay > 0 {
+			return int(delay.Round(time.Second).Seconds())
+		}
+	}
+
+	return 0
+}
+
+// transportError converts a failed HTTP round trip into an error the retry logic understands.
+// Cancellation is passed through untouched; everything else is treated as a temporary outage.
+func transportError(ctx context.Context, provider, providerName string, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return &domain.ProviderError{
+		Code:     http.StatusServiceUnavailable,
+		Message:  fmt.Sprintf("%s request failed: %v", providerName, err),
+		Provider: provider,
+	}
+}
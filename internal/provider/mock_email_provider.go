@@ -21,6 +21,7 @@ type MockEmailMessage struct {
 	Body        string
 	Attachments []string
 	Timestamp   time.Time
+	Result      *domain.SendResult
 }
 
 // NewMockEmailProvider creates a new mock email provider
@@ -48,39 +49,41 @@ func NewMockEmailProviderWithErrorCode(errorCode int) domain.EmailProvider {
 	}
 }
 
-func (p *MockEmailProvider) SendEmail(ctx context.Context, from, to, body string, attachments []string) error {
+func (p *MockEmailProvider) SendEmail(ctx context.Context, from, to, body string, attachments []string) (*domain.SendResult, error) {
 	// Handle specific error codes
 	if p.shouldFail {
 		switch p.errorCode {
 		case 500:
-			return &domain.ProviderError{
+			return nil, &domain.ProviderError{
 				Code:    500,
 				Message: "Internal server error",
 			}
 		case 429:
-			return &domain.ProviderError{
+			return nil, &domain.ProviderError{
 				Code:       429,
 				Message:    "Too many requests",
 				RetryAfter: 30, // 30 seconds
 			}
 		default:
-			return fmt.Errorf("mock email provider failure")
+			return nil, fmt.Errorf("mock email provider failure")
 		}
 	}
 
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
+	result := newMockSendResult(1)
 	message := MockEmailMessage{
 		From:        from,
 		To:          to,
 		Body:        body,
 		Attachments: attachments,
-		Timestamp:   time.Now(),
+		Timestamp:   result.AcceptedAt,
+		Result:      result,
 	}
 
 	p.messages = append(p.messages, message)
-	return nil
+	return result, nil
 }
 
 // GetMessages returns all sent messages (for testing)
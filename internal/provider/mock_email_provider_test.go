@@ -18,8 +18,9 @@ func TestMockEmailProvider_SendEmail(t *testing.T) {
 	body := "Test email message"
 	attachments := []string{"https://example.com/document.pdf"}
 
-	err := provider.SendEmail(ctx, from, to, body, attachments)
+	result, err := provider.SendEmail(ctx, from, to, body, attachments)
 	assert.NoError(t, err)
+	assert.NotEmpty(t, result.ProviderMessageID)
 
 	mockProvider := provider.(*MockEmailProvider)
 	messages := mockProvider.GetMessages()
@@ -39,7 +40,7 @@ func TestMockEmailProvider_WithFailure(t *testing.T) {
 	body := "Test email message"
 	attachments := []string{}
 
-	err := provider.SendEmail(ctx, from, to, body, attachments)
+	_, err := provider.SendEmail(ctx, from, to, body, attachments)
 	assert.Error(t, err)
 
 	// Should return a ProviderError with 500 status
@@ -61,7 +62,7 @@ func TestMockEmailProvider_ClearMessages(t *testing.T) {
 	ctx := context.Background()
 
 	// Send a message
-	err := provider.SendEmail(ctx, "user@usehatchapp.com", "contact@gmail.com", "Test message", []string{})
+	_, err := provider.SendEmail(ctx, "user@usehatchapp.com", "contact@gmail.com", "Test message", []string{})
 	assert.NoError(t, err)
 
 	// Verify message was sent
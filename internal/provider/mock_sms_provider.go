@@ -6,6 +6,8 @@ import (
 	"messaging-service/internal/domain"
 	"sync"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type MockSMSProvider struct {
@@ -21,6 +23,7 @@ type MockSMSMessage struct {
 	Body        string
 	Attachments []string
 	Timestamp   time.Time
+	Result      *domain.SendResult
 }
 
 // NewMockSMSProvider creates a new mock SMS provider
@@ -48,73 +51,88 @@ func NewMockSMSProviderWithErrorCode(errorCode int) domain.SMSProvider {
 	}
 }
 
-func (p *MockSMSProvider) SendSMS(ctx context.Context, from, to, body string) error {
+func (p *MockSMSProvider) SendSMS(ctx context.Context, from, to, body string) (*domain.SendResult, error) {
 	// Handle specific error codes
 	if p.shouldFail {
 		switch p.errorCode {
 		case 500:
-			return &domain.ProviderError{
+			return nil, &domain.ProviderError{
 				Code:    500,
 				Message: "Internal server error",
 			}
 		case 429:
-			return &domain.ProviderError{
+			return nil, &domain.ProviderError{
 				Code:       429,
 				Message:    "Too many requests",
 				RetryAfter: 30, // 30 seconds
 			}
 		default:
-			return fmt.Errorf("mock SMS provider failure")
+			return nil, fmt.Errorf("mock SMS provider failure")
 		}
 	}
 
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
+	result := newMockSendResult(CountSMSSegments(body))
 	message := MockSMSMessage{
 		From:      from,
 		To:        to,
 		Body:      body,
-		Timestamp: time.Now(),
+		Timestamp: result.AcceptedAt,
+		Result:    result,
 	}
 
 	p.messages = append(p.messages, message)
-	return nil
+	return result, nil
 }
 
-func (p *MockSMSProvider) SendMMS(ctx context.Context, from, to, body string, attachments []string) error {
+func (p *MockSMSProvider) SendMMS(ctx context.Context, from, to, body string, attachments []string) (*domain.SendResult, error) {
 	// Handle specific error codes
 	if p.shouldFail {
 		switch p.errorCode {
 		case 500:
-			return &domain.ProviderError{
+			return nil, &domain.ProviderError{
 				Code:    500,
 				Message: "Internal server error",
 			}
 		case 429:
-			return &domain.ProviderError{
+			return nil, &domain.ProviderError{
 				Code:       429,
 				Message:    "Too many requests",
 				RetryAfter: 30, // 30 seconds
 			}
 		default:
-			return fmt.Errorf("mock MMS provider failure")
+			return nil, fmt.Errorf("mock MMS provider failure")
 		}
 	}
 
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
+	// MMS is delivered as a single multimedia message regardless of body length
+	result := newMockSendResult(1)
 	message := MockSMSMessage{
 		From:        from,
 		To:          to,
 		Body:        body,
 		Attachments: attachments,
-		Timestamp:   time.Now(),
+		Timestamp:   result.AcceptedAt,
+		Result:      result,
 	}
 
 	p.messages = append(p.messages, message)
-	return nil
+	return result, nil
+}
+
+// newMockSendResult builds a send result with a generated provider message ID
+func newMockSendResult(segments int) *domain.SendResult {
+	return &domain.SendResult{
+		ProviderMessageID: uuid.New().String(),
+		AcceptedAt:        time.Now().UTC(),
+		Segments:          segments,
+		Provider:          "mock",
+	}
 }
 
 // GetMessages returns all sent messages (for testing)
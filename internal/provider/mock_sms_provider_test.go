@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"messaging-service/internal/domain"
@@ -13,8 +14,12 @@ func TestMockSMSProvider_SendSMS_Success(t *testing.T) {
 	provider := NewMockSMSProvider()
 	ctx := context.Background()
 
-	err := provider.SendSMS(ctx, "+1234567890", "+0987654321", "Hello, World!")
+	result, err := provider.SendSMS(ctx, "+1234567890", "+0987654321", "Hello, World!")
 	assert.NoError(t, err)
+	assert.NotEmpty(t, result.ProviderMessageID)
+	assert.Equal(t, "mock", result.Provider)
+	assert.Equal(t, 1, result.Segments)
+	assert.False(t, result.AcceptedAt.IsZero())
 
 	mockProvider := provider.(*MockSMSProvider)
 	messages := mockProvider.GetMessages()
@@ -22,13 +27,14 @@ func TestMockSMSProvider_SendSMS_Success(t *testing.T) {
 	assert.Equal(t, "+1234567890", messages[0].From)
 	assert.Equal(t, "+0987654321", messages[0].To)
 	assert.Equal(t, "Hello, World!", messages[0].Body)
+	assert.Equal(t, result, messages[0].Result)
 }
 
 func TestMockSMSProvider_SendSMS_WithFailure(t *testing.T) {
 	provider := NewMockSMSProviderWithFailure()
 	ctx := context.Background()
 
-	err := provider.SendSMS(ctx, "+1234567890", "+0987654321", "Hello, World!")
+	_, err := provider.SendSMS(ctx, "+1234567890", "+0987654321", "Hello, World!")
 	assert.Error(t, err)
 
 	// Should return a ProviderError with 500 status
@@ -44,7 +50,7 @@ func TestMockSMSProvider_SendSMS_WithSpecificErrorCode(t *testing.T) {
 	provider := NewMockSMSProviderWithErrorCode(429)
 	ctx := context.Background()
 
-	err := provider.SendSMS(ctx, "+1234567890", "+0987654321", "Hello, World!")
+	_, err := provider.SendSMS(ctx, "+1234567890", "+0987654321", "Hello, World!")
 	assert.Error(t, err)
 
 	// Should return a ProviderError with 429 status
@@ -62,7 +68,7 @@ func TestMockSMSProvider_SendMMS_Success(t *testing.T) {
 	ctx := context.Background()
 	attachments := []string{"image1.jpg", "image2.png"}
 
-	err := provider.SendMMS(ctx, "+1234567890", "+0987654321", "Hello, World!", attachments)
+	_, err := provider.SendMMS(ctx, "+1234567890", "+0987654321", "Hello, World!", attachments)
 	assert.NoError(t, err)
 
 	mockProvider := provider.(*MockSMSProvider)
@@ -79,7 +85,7 @@ func TestMockSMSProvider_SendMMS_WithFailure(t *testing.T) {
 	ctx := context.Background()
 	attachments := []string{"image1.jpg"}
 
-	err := provider.SendMMS(ctx, "+1234567890", "+0987654321", "Hello, World!", attachments)
+	_, err := provider.SendMMS(ctx, "+1234567890", "+0987654321", "Hello, World!", attachments)
 	assert.Error(t, err)
 
 	if providerErr, ok := err.(*domain.ProviderError); ok {
@@ -95,7 +101,7 @@ func TestMockSMSProvider_ClearMessages(t *testing.T) {
 	ctx := context.Background()
 
 	// Send a message
-	err := provider.SendSMS(ctx, "+1234567890", "+0987654321", "Hello, World!")
+	_, err := provider.SendSMS(ctx, "+1234567890", "+0987654321", "Hello, World!")
 	assert.NoError(t, err)
 
 	// Verify message was sent
@@ -126,6 +132,10 @@ func TestDomain_IsRetryableError(t *testing.T) {
 		assert.False(t, domain.IsRetryableError(err), "Error code %d should not be retryable", code)
 	}
 
+	// Test wrapped ProviderError
+	wrappedErr := fmt.Errorf("send failed: %w", &domain.ProviderError{Code: 503, Message: "unavailable"})
+	assert.True(t, domain.IsRetryableError(wrappedErr))
+
 	// Test non-ProviderError
 	regularErr := assert.AnError
 	assert.False(t, domain.IsRetryableError(regularErr))
@@ -140,6 +150,10 @@ func TestDomain_GetRetryAfterSeconds(t *testing.T) {
 	err = &domain.ProviderError{Code: 500, Message: "server error"}
 	assert.Equal(t, 0, domain.GetRetryAfterSeconds(err))
 
+	// Test wrapped rate limit error
+	wrappedErr := fmt.Errorf("send failed: %w", &domain.ProviderError{Code: 429, Message: "rate limited", RetryAfter: 30})
+	assert.Equal(t, 30, domain.GetRetryAfterSeconds(wrappedErr))
+
 	// Test non-ProviderError
 	regularErr := assert.AnError
 	assert.Equal(t, 0, domain.GetRetryAfterSeconds(regularErr))
@@ -0,0 +1,269 @@
+package provider
+
+import (
+	"context"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"messaging-service/internal/contact"
+	"messaging-service/internal/domain"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// NumberType is the kind of sender number, which determines the carrier throughput limit
+type NumberType string
+
+const (
+	NumberTypeLongCode  NumberType = "long_code"
+	NumberTypeTollFree  NumberType = "toll_free"
+	NumberTypeShortCode NumberType = "short_code"
+)
+
+// tollFreePrefixes are the North American toll-free area codes
+var tollFreePrefixes = []string{"+1800", "+1833", "+1844", "+1855", "+1866", "+1877", "+1888"}
+
+// ClassifyNumber determines the number type of a sender address
+func ClassifyNumber(number string) NumberType {
+	number = strings.TrimSpace(number)
+
+	if contact.IsShortCode(number) {
+		return NumberTypeShortCode
+	}
+	for _, prefix := range tollFreePrefixes {
+		if strings.HasPrefix(number, prefix) {
+			return NumberTypeTollFree
+		}
+	}
+	return NumberTypeLongCode
+}
+
+// RateLimitConfig holds outbound throughput limits in messages per second
+type RateLimitConfig struct {
+	LongCodeMPS  float64
+	TollFreeMPS  float64
+	ShortCodeMPS float64
+	// EmailPerSecond limits each email provider account
+	EmailPerSecond float64
+}
+
+// DefaultRateLimitConfig returns default rate limits based on typical US carrier throughput
+func DefaultRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		LongCodeMPS:    1,
+		TollFreeMPS:    3,
+		ShortCodeMPS:   100,
+		EmailPerSecond: 100,
+	}
+}
+
+// rateFor returns the configured rate for a sender number type
+func (c RateLimitConfig) rateFor(numberType NumberType) float64 {
+	switch numberType {
+	case NumberTypeTollFree:
+		return c.TollFreeMPS
+	case NumberTypeShortCode:
+		return c.ShortCodeMPS
+	default:
+		return c.LongCodeMPS
+	}
+}
+
+// tokenBucket refills at rate tokens per second up to burst; callers reserve a token
+// and wait until it is due, so waiters are served in order
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, now time.Time) *tokenBucket {
+	burst := math.Max(1, math.Ceil(rate))
+	return &tokenBucket{rate: rate, burst: burst, tokens: burst, last: now}
+}
+
+// reserve takes a token and returns how long to wait before it may be used
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed.Seconds()*b.rate)
+		b.last = now
+	}
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel returns a reserved token that will not be used
+func (b *tokenBucket) cancel() {
+	b.tokens = math.Min(b.burst, b.tokens+1)
+}
+
+// idle reports whether the bucket has refilled completely by now, in which case it has no
+// waiters and behaves exactly like a new bucket
+func (b *tokenBucket) idle(now time.Time) bool {
+	return b.tokens+now.Sub(b.last).Seconds()*b.rate >= b.burst
+}
+
+// bucketSweepInterval is how often idle buckets are evicted so senders that stop sending
+// do not hold memory forever
+const bucketSweepInterval = time.Minute
+
+// RateLimiter throttles outbound sends with a token bucket per provider and sender
+type RateLimiter struct {
+	config RateLimitConfig
+	now    func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+
+	queueDepth metric.Int64UpDownCounter
+	waitTime   metric.Float64Histogram
+}
+
+// NewRateLimiter creates a new rate limiter and registers its metrics
+func NewRateLimiter(config RateLimitConfig) *RateLimiter {
+	meter := otel.GetMeterProvider().Meter("messaging-service")
+
+	queueDepth, _ := meter.Int64UpDownCounter("provider_rate_limit_queue_depth",
+		metric.WithDescription("Number of sends waiting for a rate limit token"),
+		metric.WithUnit("1"),
+	)
+	waitTime, _ := meter.Float64Histogram("provider_rate_limit_wait_seconds",
+		metric.WithDescription("Time sends spent waiting for a rate limit token"),
+		metric.WithUnit("s"),
+	)
+
+	return &RateLimiter{
+		config:     config,
+		now:        time.Now,
+		buckets:    make(map[string]*tokenBucket),
+		lastSweep:  time.Now(),
+		queueDepth: queueDepth,
+		waitTime:   waitTime,
+	}
+}
+
+// WaitSMS blocks until the provider may send another message from the given number
+func (l *RateLimiter) WaitSMS(ctx context.Context, providerName, from string) error {
+	numberType := ClassifyNumber(from)
+	return l.wait(ctx, providerName+":"+from, l.config.rateFor(numberType),
+		attribute.String("provider", providerName),
+		attribute.String("number_type", string(numberType)),
+	)
+}
+
+// WaitEmail blocks until the email provider account may send another message
+func (l *RateLimiter) WaitEmail(ctx context.Context, providerName string) error {
+	return l.wait(ctx, providerName, l.config.EmailPerSecond,
+		attribute.String("provider", providerName),
+	)
+}
+
+func (l *RateLimiter) wait(ctx context.Context, key string, rate float64, attrs ...attribute.KeyValue) error {
+	// A non-positive rate disables limiting for this kind of sender
+	if rate <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := l.now()
+	l.evictIdle(now)
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = newTokenBucket(rate, now)
+		l.buckets[key] = bucket
+	}
+	delay := bucket.reserve(now)
+	l.mu.Unlock()
+
+	attributes := metric.WithAttributes(attrs...)
+	l.waitTime.Record(ctx, delay.Seconds(), attributes)
+	if delay == 0 {
+		return nil
+	}
+
+	l.queueDepth.Add(ctx, 1, attributes)
+	defer l.queueDepth.Add(context.WithoutCancel(ctx), -1, attributes)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		bucket.cancel()
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// evictIdle drops buckets that have refilled completely, at most once per bucketSweepInterval.
+// Must be called with l.mu held.
+func (l *RateLimiter) evictIdle(now time.Time) {
+	if now.Sub(l.lastSweep) < bucketSweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, bucket := range l.buckets {
+		if bucket.idle(now) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// RateLimitedSMSProvider waits for a rate limit token before each SMS/MMS send
+type RateLimitedSMSProvider struct {
+	provider domain.SMSProvider
+	name     string
+	limiter  *RateLimiter
+}
+
+// NewRateLimitedSMSProvider creates an SMS provider throttled per sender number
+func NewRateLimitedSMSProvider(provider domain.SMSProvider, name string, limiter *RateLimiter) *RateLimitedSMSProvider {
+	return &RateLimitedSMSProvider{provider: provider, name: name, limiter: limiter}
+}
+
+func (p *RateLimitedSMSProvider) SendSMS(ctx context.Context, from, to, body string) (*domain.SendResult, error) {
+	if err := p.limiter.WaitSMS(ctx, p.name, from); err != nil {
+		return nil, err
+	}
+	return p.provider.SendSMS(ctx, from, to, body)
+}
+
+func (p *RateLimitedSMSProvider) SendMMS(ctx context.Context, from, to, body string, attachments []string) (*domain.SendResult, error) {
+	if err := p.limiter.WaitSMS(ctx, p.name, from); err != nil {
+		return nil, err
+	}
+	return p.provider.SendMMS(ctx, from, to, body, attachments)
+}
+
+// RateLimitedEmailProvider waits for a rate limit token before each email send
+type RateLimitedEmailProvider struct {
+	provider domain.EmailProvider
+	name     string
+	limiter  *RateLimiter
+}
+
+// NewRateLimitedEmailProvider creates an email provider throttled per account
+func NewRateLimitedEmailProvider(provider domain.EmailProvider, name string, limiter *RateLimiter) *RateLimitedEmailProvider {
+	return &RateLimitedEmailProvider{provider: provider, name: name, limiter: limiter}
+}
+
+func (p *RateLimitedEmailProvider) SendEmail(ctx context.Context, from, to, body string, attachments []string) (*domain.SendResult, error) {
+	if err := p.limiter.WaitEmail(ctx, p.name); err != nil {
+		return nil, err
+	}
+	return p.provider.SendEmail(ctx, from, to, body, attachments)
+}
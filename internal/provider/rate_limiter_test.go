@@ -0,0 +1,122 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyNumber(t *testing.T) {
+	tests := []struct {
+		number   string
+		expected NumberType
+	}{
+		{"+12016661234", NumberTypeLongCode},
+		{"+447911123456", NumberTypeLongCode},
+		{"+18005551234", NumberTypeTollFree},
+		{"+18885551234", NumberTypeTollFree},
+		{"12345", NumberTypeShortCode},
+		{"123456", NumberTypeShortCode},
+		{"1234567", NumberTypeLongCode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.number, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ClassifyNumber(tt.number))
+		})
+	}
+}
+
+func TestTokenBucket_Reserve(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	bucket := newTokenBucket(2, now)
+
+	// The bucket starts full
+	assert.Zero(t, bucket.reserve(now))
+	assert.Zero(t, bucket.reserve(now))
+
+	// Further reservations queue up behind each other
+	assert.Equal(t, 500*time.Millisecond, bucket.reserve(now))
+	assert.Equal(t, time.Second, bucket.reserve(now))
+
+	// Cancelled reservations are returned to the bucket
+	bucket.cancel()
+	assert.Equal(t, time.Second, bucket.reserve(now))
+
+	// Tokens refill over time
+	assert.Zero(t, bucket.reserve(now.Add(2*time.Second)))
+}
+
+func TestTokenBucket_FractionalRate(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	bucket := newTokenBucket(0.5, now)
+
+	assert.Zero(t, bucket.reserve(now))
+	assert.Equal(t, 2*time.Second, bucket.reserve(now))
+}
+
+func TestRateLimiter_KeysByProviderAndSender(t *testing.T) {
+	limiter := NewRateLimiter(RateLimitConfig{LongCodeMPS: 1, ShortCodeMPS: 1})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	require.NoError(t, limiter.WaitSMS(ctx, "twilio", "+12016661234"))
+
+	// Other senders and providers have their own buckets
+	require.NoError(t, limiter.WaitSMS(ctx, "twilio", "+12016669999"))
+	require.NoError(t, limiter.WaitSMS(ctx, "mock", "+12016661234"))
+
+	// The same sender must wait for the next token, which comes after the deadline
+	err := limiter.WaitSMS(ctx, "twilio", "+12016661234")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRateLimiter_EvictsIdleBuckets(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(RateLimitConfig{LongCodeMPS: 1})
+	limiter.now = func() time.Time { return now }
+	limiter.lastSweep = now
+	ctx := context.Background()
+
+	require.NoError(t, limiter.WaitSMS(ctx, "twilio", "+12016661234"))
+	require.NoError(t, limiter.WaitSMS(ctx, "twilio", "+12016669999"))
+	assert.Len(t, limiter.buckets, 2)
+
+	// A bucket still refilling is kept; one that has refilled completely is evicted
+	now = now.Add(bucketSweepInterval)
+	limiter.buckets["twilio:+12016669999"].tokens = -bucketSweepInterval.Seconds()
+	require.NoError(t, limiter.WaitSMS(ctx, "mock", "+12016661234"))
+
+	assert.Len(t, limiter.buckets, 2)
+	assert.Contains(t, limiter.buckets, "twilio:+12016669999")
+	assert.Contains(t, limiter.buckets, "mock:+12016661234")
+}
+
+func TestRateLimiter_ZeroRateDisablesLimit(t *testing.T) {
+	limiter := NewRateLimiter(RateLimitConfig{})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	for i := 0; i < 100; i++ {
+		require.NoError(t, limiter.WaitEmail(ctx, "sendgrid"))
+	}
+}
+
+func TestRateLimitedSMSProvider_WaitsBetweenSends(t *testing.T) {
+	limiter := NewRateLimiter(RateLimitConfig{LongCodeMPS: 20})
+	mock := NewMockSMSProvider().(*MockSMSProvider)
+	p := NewRateLimitedSMSProvider(mock, "mock", limiter)
+
+	start := time.Now()
+	for i := 0; i < 22; i++ {
+		_, err := p.SendSMS(context.Background(), "+12016661234", "+18045551234", "Hello")
+		require.NoError(t, err)
+	}
+
+	// 20 sends fit in the initial burst, the remaining 2 wait 50ms each
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+	assert.Len(t, mock.GetMessages(), 22)
+}
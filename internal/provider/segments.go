@@ -0,0 +1,68 @@
+package provider
+
+import "unicode/utf16"
+
+// gsm7Basic holds the characters of the GSM 03.38 basic character set
+const gsm7Basic = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+	"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+
+// gsm7Extended holds the characters that need an escape sequence and take two septets
+const gsm7Extended = "^{}\\[~]|€\f"
+
+// SMS segment sizes for single and concatenated messages
+const (
+	gsm7SingleSegment = 160
+	gsm7MultiSegment  = 153
+	ucs2SingleSegment = 70
+	ucs2MultiSegment  = 67
+)
+
+var (
+	gsm7BasicSet    = runeSet(gsm7Basic)
+	gsm7ExtendedSet = runeSet(gsm7Extended)
+)
+
+// CountSMSSegments returns the number of SMS segments needed to carry body.
+// Bodies that fit the GSM-7 alphabet use 7-bit encoding, everything else is sent as UCS-2.
+func CountSMSSegments(body string) int {
+	if body == "" {
+		return 1
+	}
+
+	if septets, ok := gsm7Length(body); ok {
+		return segmentsFor(septets, gsm7SingleSegment, gsm7MultiSegment)
+	}
+
+	return segmentsFor(len(utf16.Encode([]rune(body))), ucs2SingleSegment, ucs2MultiSegment)
+}
+
+// gsm7Length returns the number of septets body takes in GSM-7, or false if it cannot be encoded
+func gsm7Length(body string) (int, bool) {
+	septets := 0
+	for _, r := range body {
+		switch {
+		case gsm7BasicSet[r]:
+			septets++
+		case gsm7ExtendedSet[r]:
+			septets += 2
+		default:
+			return 0, false
+		}
+	}
+	return septets, true
+}
+
+func segmentsFor(length, single, multi int) int {
+	if length <= single {
+		return 1
+	}
+	return (length + multi - 1) / multi
+}
+
+func runeSet(chars string) map[rune]bool {
+	set := make(map[rune]bool, len(chars))
+	for _, r := range chars {
+		set[r] = true
+	}
+	return set
+}
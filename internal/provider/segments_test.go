@@ -0,0 +1,33 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountSMSSegments(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected int
+	}{
+		{"empty body", "", 1},
+		{"short GSM-7", "Hello, World!", 1},
+		{"GSM-7 single segment limit", strings.Repeat("a", 160), 1},
+		{"GSM-7 two segments", strings.Repeat("a", 161), 2},
+		{"GSM-7 extended characters take two septets", strings.Repeat("€", 80), 1},
+		{"GSM-7 extended characters overflow", strings.Repeat("€", 81), 2},
+		{"UCS-2 single segment limit", strings.Repeat("ж", 70), 1},
+		{"UCS-2 two segments", strings.Repeat("ж", 71), 2},
+		{"emoji uses surrogate pairs", strings.Repeat("😀", 35), 1},
+		{"emoji overflow", strings.Repeat("😀", 36), 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, CountSMSSegments(tt.body))
+		})
+	}
+}
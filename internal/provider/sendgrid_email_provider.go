@@ -1,55 +1,195 @@
 package provider
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"messaging-service/internal/domain"
 )
 
+// DefaultSendGridBaseURL is the base URL of the SendGrid v3 API
+const DefaultSendGridBaseURL = "https://api.sendgrid.com"
+
+// SendGridConfig holds SendGrid provider configuration
+type SendGridConfig struct {
+	APIKey  string
+	BaseURL string
+	Subject string
+	Timeout time.Duration
+}
+
 // SendGridEmailProvider implements domain.EmailProvider for SendGrid
 type SendGridEmailProvider struct {
 	apiKey     string
+	baseURL    string
+	subject    string
 	httpClient *http.Client
-	shouldFail bool
-	errorCode  int
 }
 
 // NewSendGridEmailProvider creates a new SendGrid email provider
 func NewSendGridEmailProvider(apiKey string) *SendGridEmailProvider {
+	return NewSendGridEmailProviderWithConfig(SendGridConfig{APIKey: apiKey})
+}
+
+// NewSendGridEmailProviderWithConfig creates a new SendGrid email provider with custom configuration
+func NewSendGridEmailProviderWithConfig(config SendGridConfig) *SendGridEmailProvider {
+	if config.BaseURL == "" {
+		config.BaseURL = DefaultSendGridBaseURL
+	}
+	if config.Subject == "" {
+		config.Subject = DefaultEmailSubject
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 30 * time.Second
+	}
+
 	return &SendGridEmailProvider{
-		apiKey: apiKey,
+		apiKey:  config.APIKey,
+		baseURL: strings.TrimRight(config.BaseURL, "/"),
+		subject: config.Subject,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout: config.Timeout,
 		},
 	}
 }
 
-// SendEmail sends an email through SendGrid
-func (p *SendGridEmailProvider) SendEmail(ctx context.Context, from, to, body string, attachments []string) error {
-	// Simulate provider errors for testing
-	if p.shouldFail {
-		return &domain.ProviderError{
-			Code:    p.errorCode,
-			Message: fmt.Sprintf("SendGrid error: %d", p.errorCode),
-		}
+// sendGridMail is the v3 Mail Send request body
+type sendGridMail struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+	Attachments      []sendGridAttachment      `json:"attachments,omitempty"`
+}
+
+type sendGridPersonalization struct {
+	To []sendGridAddress `json:"to"`
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridAttachment struct {
+	Content     string `json:"content"`
+	Type        string `json:"type"`
+	Filename    string `json:"filename"`
+	Disposition string `json:"disposition"`
+}
+
+// sendGridErrorResponse is the error body returned by the v3 API
+type sendGridErrorResponse struct {
+	Errors []struct {
+		Message string `json:"message"`
+		Field   string `json:"field"`
+	} `json:"errors"`
+}
+
+// SendEmail sends an email through the SendGrid v3 Mail Send API
+func (p *SendGridEmailProvider) SendEmail(ctx context.Context, from, to, body string, attachments []string) (*domain.SendResult, error) {
+	mail, err := p.buildMail(ctx, from, to, body, attachments)
+	if err != nil {
+		return nil, err
 	}
 
-	// In a real implementation, you would:
-	// 1. Create the SendGrid API request
-	// 2. Add attachments if provided
-	// 3. Send the request to SendGrid API
-	// 4. Handle the response
+	payload, err := json.Marshal(mail)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal SendGrid request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/v3/mail/send", bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SendGrid request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, transportError(ctx, string(EmailProviderSendGrid), "SendGrid", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, p.responseError(resp)
+	}
+
+	return &domain.SendResult{
+		ProviderMessageID: resp.Header.Get("X-Message-Id"),
+		AcceptedAt:        time.Now().UTC(),
+		Segments:          1,
+		Provider:          string(EmailProviderSendGrid),
+	}, nil
+}
+
+// buildMail builds the Mail Send payload, downloading and encoding any attachments
+func (p *SendGridEmailProvider) buildMail(ctx context.Context, from, to, body string, attachments []string) (*sendGridMail, error) {
+	mail := &sendGridMail{
+		Personalizations: []sendGridPersonalization{{To: []sendGridAddress{{Email: to}}}},
+		From:             sendGridAddress{Email: from},
+		Subject:          p.subject,
+		Content:          []sendGridContent{{Type: "text/html", Value: body}},
+	}
 
-	// For now, we'll just simulate success
-	fmt.Printf("SendGrid: Sending email from %s to %s\n", from, to)
-	return nil
+	for _, attachmentURL := range attachments {
+		attachment, err := fetchAttachment(ctx, p.httpClient, attachmentURL)
+		if err != nil {
+			return nil, err
+		}
+		mail.Attachments = append(mail.Attachments, sendGridAttachment{
+			Content:     base64.StdEncoding.EncodeToString(attachment.Content),
+			Type:        attachment.ContentType,
+			Filename:    attachment.Filename,
+			Disposition: "attachment",
+		})
+	}
+
+	return mail, nil
 }
 
-// SetFailureMode sets the provider to fail with specific error code (for testing)
-func (p *SendGridEmailProvider) SetFailureMode(shouldFail bool, errorCode int) {
-	p.shouldFail = shouldFail
-	p.errorCode = errorCode
+// responseError converts a non-2xx SendGrid response into a domain.ProviderError
+func (p *SendGridEmailProvider) responseError(resp *http.Response) error {
+	providerErr := &domain.ProviderError{
+		Code:     resp.StatusCode,
+		Message:  fmt.Sprintf("SendGrid error: %d", resp.StatusCode),
+		Provider: string(EmailProviderSendGrid),
+	}
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	var errorResponse sendGridErrorResponse
+	if json.Unmarshal(respBody, &errorResponse) == nil && len(errorResponse.Errors) > 0 {
+		messages := make([]string, 0, len(errorResponse.Errors))
+		for _, e := range errorResponse.Errors {
+			messages = append(messages, e.Message)
+		}
+		providerErr.Message = fmt.Sprintf("SendGrid error: %d: %s", resp.StatusCode, strings.Join(messages, "; "))
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		now := time.Now()
+		providerErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), now)
+		// SendGrid reports rate limit windows as a Unix reset time
+		if providerErr.RetryAfter == 0 {
+			if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+				if delay := time.Unix(reset, 0).Sub(now); delay > 0 {
+					providerErr.RetryAfter = int(delay.Round(time.Second).Seconds())
+				}
+			}
+		}
+	}
+
+	return providerErr
 }
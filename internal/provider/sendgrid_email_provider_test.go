@@ -2,34 +2,201 @@ package provider
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
+
+	"messaging-service/internal/domain"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+func newTestSendGridProvider(serverURL string) *SendGridEmailProvider {
+	return NewSendGridEmailProviderWithConfig(SendGridConfig{
+		APIKey:  "test-api-key",
+		BaseURL: serverURL,
+		Subject: "Test subject",
+	})
+}
+
 func TestSendGridEmailProvider_SendEmail_Success(t *testing.T) {
-	provider := NewSendGridEmailProvider("test-api-key")
+	var received sendGridMail
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v3/mail/send", r.URL.Path)
+		assert.Equal(t, "Bearer test-api-key", r.Header.Get("Authorization"))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+
+		w.Header().Set("X-Message-Id", "sg-message-1")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	provider := newTestSendGridProvider(server.URL)
+
+	result, err := provider.SendEmail(context.Background(), "from@test.com", "to@test.com", "<p>Test email</p>", nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "sg-message-1", result.ProviderMessageID)
+	assert.Equal(t, "sendgrid", result.Provider)
+	assert.False(t, result.AcceptedAt.IsZero())
+
+	assert.Equal(t, "from@test.com", received.From.Email)
+	require.Len(t, received.Personalizations, 1)
+	assert.Equal(t, []sendGridAddress{{Email: "to@test.com"}}, received.Personalizations[0].To)
+	assert.Equal(t, "Test subject", received.Subject)
+	assert.Equal(t, []sendGridContent{{Type: "text/html", Value: "<p>Test email</p>"}}, received.Content)
+	assert.Empty(t, received.Attachments)
+}
+
+func TestSendGridEmailProvider_SendEmail_WithAttachments(t *testing.T) {
+	files := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		_, _ = w.Write([]byte("%PDF-1.4 test"))
+	}))
+	defer files.Close()
+
+	var received sendGridMail
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
 
-	err := provider.SendEmail(context.Background(), "from@test.com", "to@test.com", "Test email", nil)
+	provider := newTestSendGridProvider(server.URL)
 
-	assert.NoError(t, err)
+	_, err := provider.SendEmail(context.Background(), "from@test.com", "to@test.com", "Test email", []string{files.URL + "/docs/file.pdf"})
+
+	require.NoError(t, err)
+	require.Len(t, received.Attachments, 1)
+	assert.Equal(t, "file.pdf", received.Attachments[0].Filename)
+	assert.Equal(t, "application/pdf", received.Attachments[0].Type)
+	assert.Equal(t, "attachment", received.Attachments[0].Disposition)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("%PDF-1.4 test")), received.Attachments[0].Content)
 }
 
-func TestSendGridEmailProvider_SendEmail_WithFailure(t *testing.T) {
-	provider := NewSendGridEmailProvider("test-api-key")
-	provider.SetFailureMode(true, 500)
+func TestSendGridEmailProvider_SendEmail_AttachmentNotFound(t *testing.T) {
+	files := httptest.NewServer(http.NotFoundHandler())
+	defer files.Close()
+
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
 
-	err := provider.SendEmail(context.Background(), "from@test.com", "to@test.com", "Test email", nil)
+	provider := newTestSendGridProvider(server.URL)
+
+	_, err := provider.SendEmail(context.Background(), "from@test.com", "to@test.com", "Test email", []string{files.URL + "/missing.pdf"})
 
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "SendGrid error: 500")
+	assert.False(t, domain.IsRetryableError(err))
+	assert.False(t, called)
 }
 
-func TestSendGridEmailProvider_SendEmail_WithAttachments(t *testing.T) {
-	provider := NewSendGridEmailProvider("test-api-key")
-	attachments := []string{"https://example.com/file.pdf"}
+func TestSendGridEmailProvider_SendEmail_ErrorResponses(t *testing.T) {
+	tests := []struct {
+		name               string
+		status             int
+		headers            map[string]string
+		body               string
+		expectedRetryAfter int
+		expectedRetryable  bool
+		expectedMessage    string
+	}{
+		{
+			name:              "bad request",
+			status:            http.StatusBadRequest,
+			body:              `{"errors":[{"message":"The from address does not match a verified Sender Identity.","field":"from"}]}`,
+			expectedRetryable: false,
+			expectedMessage:   "SendGrid error: 400: The from address does not match a verified Sender Identity.",
+		},
+		{
+			name:              "unauthorized",
+			status:            http.StatusUnauthorized,
+			expectedRetryable: false,
+			expectedMessage:   "SendGrid error: 401",
+		},
+		{
+			name:               "rate limited",
+			status:             http.StatusTooManyRequests,
+			headers:            map[string]string{"Retry-After": "12"},
+			expectedRetryAfter: 12,
+			expectedRetryable:  true,
+			expectedMessage:    "SendGrid error: 429",
+		},
+		{
+			name:              "server error",
+			status:            http.StatusInternalServerError,
+			expectedRetryable: true,
+			expectedMessage:   "SendGrid error: 500",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for key, value := range tt.headers {
+					w.Header().Set(key, value)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			provider := newTestSendGridProvider(server.URL)
+
+			_, err := provider.SendEmail(context.Background(), "from@test.com", "to@test.com", "Test email", nil)
+
+			var providerErr *domain.ProviderError
+			require.ErrorAs(t, err, &providerErr)
+			assert.Equal(t, tt.status, providerErr.Code)
+			assert.Equal(t, tt.expectedMessage, providerErr.Message)
+			assert.Equal(t, tt.expectedRetryAfter, providerErr.RetryAfter)
+			assert.Equal(t, tt.expectedRetryable, domain.IsRetryableError(err))
+		})
+	}
+}
+
+func TestSendGridEmailProvider_SendEmail_Unreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	provider := newTestSendGridProvider(server.URL)
+
+	_, err := provider.SendEmail(context.Background(), "from@test.com", "to@test.com", "Test email", nil)
+
+	assert.Error(t, err)
+	assert.True(t, domain.IsRetryableError(err))
+}
+
+func TestSendGridEmailProvider_SendEmail_Cancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	provider := newTestSendGridProvider(server.URL)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := provider.SendEmail(ctx, "from@test.com", "to@test.com", "Test email", nil)
+
+	assert.ErrorIs(t, err, context.Canceled)
+}
 
-	err := provider.SendEmail(context.Background(), "from@test.com", "to@test.com", "Test email", attachments)
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC)
 
-	assert.NoError(t, err)
+	assert.Equal(t, 0, parseRetryAfter("", now))
+	assert.Equal(t, 30, parseRetryAfter("30", now))
+	assert.Equal(t, 0, parseRetryAfter("-5", now))
+	assert.Equal(t, 90, parseRetryAfter(now.Add(90*time.Second).Format(http.TimeFormat), now))
+	assert.Equal(t, 0, parseRetryAfter("soon", now))
 }
@@ -0,0 +1,29 @@
+package provider
+
+import (
+	"messaging-service/internal/domain"
+)
+
+// SMSProviderType represents the type of SMS provider
+type SMSProviderType string
+
+const (
+	SMSProviderMock   SMSProviderType = "mock"
+	SMSProviderTwilio SMSProviderType = "twilio"
+)
+
+// NewSMSProvider creates an SMS provider based on the specified type
+func NewSMSProvider(providerType SMSProviderType, config map[string]string) domain.SMSProvider {
+	switch providerType {
+	case SMSProviderTwilio:
+		return NewTwilioSMSProviderWithConfig(TwilioConfig{
+			AccountSID: config["account_sid"],
+			AuthToken:  config["auth_token"],
+			BaseURL:    config["base_url"],
+		})
+	case SMSProviderMock:
+		fallthrough
+	default:
+		return NewMockSMSProvider()
+	}
+}
@@ -0,0 +1,368 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"messaging-service/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// SMTP transport security modes
+const (
+	SMTPTLSNone     = "none"
+	SMTPTLSStartTLS = "starttls"
+	SMTPTLSImplicit = "tls"
+)
+
+// SMTP authentication mechanisms
+const (
+	SMTPAuthNone  = "none"
+	SMTPAuthPlain = "plain"
+	SMTPAuthLogin = "login"
+)
+
+// SMTPConfig holds SMTP provider configuration
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	TLSMode  string
+	Auth     string
+	Subject  string
+	Timeout  time.Duration
+	// TLSConfig overrides the TLS settings used for STARTTLS and implicit TLS
+	TLSConfig *tls.Config
+}
+
+// SMTPEmailProvider implements domain.EmailProvider over SMTP
+type SMTPEmailProvider struct {
+	config SMTPConfig
+}
+
+// NewSMTPEmailProvider creates a new SMTP email provider
+func NewSMTPEmailProvider(config SMTPConfig) *SMTPEmailProvider {
+	if config.Port == "" {
+		config.Port = "587"
+	}
+	if config.TLSMode == "" {
+		config.TLSMode = SMTPTLSStartTLS
+	}
+	if config.Auth == "" {
+		config.Auth = SMTPAuthPlain
+	}
+	if config.Subject == "" {
+		config.Subject = DefaultEmailSubject
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 30 * time.Second
+	}
+
+	return &SMTPEmailProvider{config: config}
+}
+
+// SendEmail delivers an email to the configured SMTP server
+func (p *SMTPEmailProvider) SendEmail(ctx context.Context, from, to, body string, attachments []string) (*domain.SendResult, error) {
+	messageID := fmt.Sprintf("<%s@%s>", uuid.New().String(), messageIDDomain(from, p.config.Host))
+
+	message, err := p.buildMessage(ctx, from, to, body, attachments, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.deliver(ctx, from, to, message); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, smtpError(err)
+	}
+
+	return &domain.SendResult{
+		ProviderMessageID: messageID,
+		AcceptedAt:        time.Now().UTC(),
+		Segments:          1,
+		Provider:          string(EmailProviderSMTP),
+	}, nil
+}
+
+// deliver runs one SMTP transaction for the message
+func (p *SMTPEmailProvider) deliver(ctx context.Context, from, to string, message []byte) error {
+	conn, err := p.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// net/smtp has no context support, so bound the session with a deadline and close it on cancellation
+	deadline := time.Now().Add(p.config.Timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, p.config.Host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if p.config.TLSMode == SMTPTLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("SMTP server %s does not support STARTTLS", p.config.Host)
+		}
+		if err := client.StartTLS(p.tlsConfig()); err != nil {
+			return err
+		}
+	}
+
+	if auth := p.auth(); auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(message); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// dial opens the connection to the SMTP server, negotiating TLS up front in implicit TLS mode
+func (p *SMTPEmailProvider) dial(ctx context.Context) (net.Conn, error) {
+	address := net.JoinHostPort(p.config.Host, p.config.Port)
+	dialer := &net.Dialer{Timeout: p.config.Timeout}
+
+	if p.config.TLSMode == SMTPTLSImplicit {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: p.tlsConfig()}
+		return tlsDialer.DialContext(ctx, "tcp", address)
+	}
+
+	return dialer.DialContext(ctx, "tcp", address)
+}
+
+func (p *SMTPEmailProvider) tlsConfig() *tls.Config {
+	if p.config.TLSConfig != nil {
+		config := p.config.TLSConfig.Clone()
+		if config.ServerName == "" {
+			config.ServerName = p.config.Host
+		}
+		return config
+	}
+	return &tls.Config{ServerName: p.config.Host, MinVersion: tls.VersionTLS12}
+}
+
+// auth returns the configured authentication mechanism, or nil when no credentials are set
+func (p *SMTPEmailProvider) auth() smtp.Auth {
+	if p.config.Username == "" || p.config.Auth == SMTPAuthNone {
+		return nil
+	}
+	if p.config.Auth == SMTPAuthLogin {
+		return &loginAuth{username: p.config.Username, password: p.config.Password, host: p.config.Host}
+	}
+	return smtp.PlainAuth("", p.config.Username, p.config.Password, p.config.Host)
+}
+
+// buildMessage assembles the RFC 5322 message, using multipart/mixed when there are attachments
+func (p *SMTPEmailProvider) buildMessage(ctx context.Context, from, to, body string, attachments []string, messageID string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	headers := textproto.MIMEHeader{}
+	headers.Set("From", from)
+	headers.Set("To", to)
+	headers.Set("Subject", mime.QEncoding.Encode("utf-8", p.config.Subject))
+	headers.Set("Date", time.Now().Format(time.RFC1123Z))
+	headers.Set("Message-ID", messageID)
+	headers.Set("MIME-Version", "1.0")
+
+	if len(attachments) == 0 {
+		headers.Set("Content-Type", "text/html; charset=UTF-8")
+		headers.Set("Content-Transfer-Encoding", "quoted-printable")
+		writeHeaders(&buf, headers)
+		if err := writeQuotedPrintable(&buf, body); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	// Download attachments before writing anything so a bad URL fails fast
+	fetched := make([]*fetchedAttachment, 0, len(attachments))
+	client := &http.Client{Timeout: p.config.Timeout}
+	for _, attachmentURL := range attachments {
+		attachment, err := fetchAttachment(ctx, client, attachmentURL)
+		if err != nil {
+			return nil, err
+		}
+		fetched = append(fetched, attachment)
+	}
+
+	var parts bytes.Buffer
+	writer := multipart.NewWriter(&parts)
+
+	bodyPart, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/html; charset=UTF-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create email body part: %w", err)
+	}
+	if err := writeQuotedPrintable(bodyPart, body); err != nil {
+		return nil, err
+	}
+
+	for _, attachment := range fetched {
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(attachment.ContentType, map[string]string{"name": attachment.Filename})},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create attachment part: %w", err)
+		}
+		writeBase64Lines(part, attachment.Content)
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish email: %w", err)
+	}
+
+	headers.Set("Content-Type", mime.FormatMediaType("multipart/mixed", map[string]string{"boundary": writer.Boundary()}))
+	writeHeaders(&buf, headers)
+	buf.Write(parts.Bytes())
+
+	return buf.Bytes(), nil
+}
+
+// smtpHeaderOrder keeps the message headers in a conventional, deterministic order
+var smtpHeaderOrder = []string{"From", "To", "Subject", "Date", "Message-ID", "MIME-Version", "Content-Type", "Content-Transfer-Encoding"}
+
+func writeHeaders(buf *bytes.Buffer, headers textproto.MIMEHeader) {
+	for _, key := range smtpHeaderOrder {
+		if value := headers.Get(key); value != "" {
+			fmt.Fprintf(buf, "%s: %s\r\n", key, value)
+		}
+	}
+	buf.WriteString("\r\n")
+}
+
+func writeQuotedPrintable(w interface{ Write([]byte) (int, error) }, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return fmt.Errorf("failed to encode email body: %w", err)
+	}
+	return qp.Close()
+}
+
+// writeBase64Lines writes content as base64 wrapped at 76 characters per RFC 2045
+func writeBase64Lines(w interface{ Write([]byte) (int, error) }, content []byte) {
+	encoded := base64.StdEncoding.EncodeToString(content)
+	for len(encoded) > 76 {
+		_, _ = w.Write([]byte(encoded[:76] + "\r\n"))
+		encoded = encoded[76:]
+	}
+	_, _ = w.Write([]byte(encoded + "\r\n"))
+}
+
+// messageIDDomain picks the domain used in generated Message-ID headers
+func messageIDDomain(from, host string) string {
+	if at := strings.LastIndex(from, "@"); at >= 0 && at < len(from)-1 {
+		return strings.Trim(from[at+1:], "<> ")
+	}
+	return host
+}
+
+// smtpError maps SMTP reply codes onto provider errors: 4xx replies are transient, 5xx are permanent
+func smtpError(err error) error {
+	var protoErr *textproto.Error
+	if !errors.As(err, &protoErr) {
+		// Connection and TLS failures are treated as a temporary outage
+		return &domain.ProviderError{
+			Code:     http.StatusServiceUnavailable,
+			Message:  fmt.Sprintf("SMTP request failed: %v", err),
+			Provider: string(EmailProviderSMTP),
+		}
+	}
+
+	providerErr := &domain.ProviderError{
+		Message:  fmt.Sprintf("SMTP error %d: %s", protoErr.Code, protoErr.Msg),
+		Provider: string(EmailProviderSMTP),
+	}
+	switch {
+	case protoErr.Code >= 400 && protoErr.Code < 500:
+		providerErr.Code = http.StatusServiceUnavailable
+	case protoErr.Code == 530 || protoErr.Code == 534 || protoErr.Code == 535:
+		providerErr.Code = http.StatusUnauthorized
+	default:
+		providerErr.Code = http.StatusBadRequest
+	}
+	return providerErr
+}
+
+// loginAuth implements the LOGIN authentication mechanism, which net/smtp does not provide
+type loginAuth struct {
+	username string
+	password string
+	host     string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	// Like PLAIN, only send credentials over TLS or to localhost
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		return []byte(a.username), nil
+	case "password:":
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected LOGIN challenge: %s", fromServer)
+	}
+}
+
+func isLocalhost(name string) bool {
+	return name == "localhost" || name == "127.0.0.1" || name == "::1"
+}
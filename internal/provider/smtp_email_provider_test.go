@@ -0,0 +1,359 @@
+package provider
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"messaging-service/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSMTPMessage is a message received by fakeSMTPServer
+type fakeSMTPMessage struct {
+	From     string
+	To       string
+	Username string
+	Password string
+	TLS      bool
+	Data     string
+}
+
+// fakeSMTPServer is a minimal in-process SMTP server for provider tests
+type fakeSMTPServer struct {
+	listener  net.Listener
+	tlsConfig *tls.Config
+	rcptReply string
+
+	mu       sync.Mutex
+	messages []fakeSMTPMessage
+}
+
+// newFakeSMTPServer starts a fake SMTP server. STARTTLS is offered when tlsConfig is set,
+// and the whole session is TLS when implicitTLS is true.
+func newFakeSMTPServer(t *testing.T, tlsConfig *tls.Config, implicitTLS bool) *fakeSMTPServer {
+	var listener net.Listener
+	var err error
+	if implicitTLS {
+		listener, err = tls.Listen("tcp", "127.0.0.1:0", tlsConfig)
+	} else {
+		listener, err = net.Listen("tcp", "127.0.0.1:0")
+	}
+	require.NoError(t, err)
+
+	server := &fakeSMTPServer{listener: listener}
+	if !implicitTLS {
+		server.tlsConfig = tlsConfig
+	}
+	go server.serve()
+	t.Cleanup(func() { listener.Close() })
+
+	return server
+}
+
+func (s *fakeSMTPServer) Port() string {
+	return strconv.Itoa(s.listener.Addr().(*net.TCPAddr).Port)
+}
+
+func (s *fakeSMTPServer) Messages() []fakeSMTPMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]fakeSMTPMessage(nil), s.messages...)
+}
+
+func (s *fakeSMTPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTPServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	_, isTLS := conn.(*tls.Conn)
+	text := textproto.NewConn(conn)
+	var current fakeSMTPMessage
+	current.TLS = isTLS
+
+	reply := func(line string) { _ = text.PrintfLine("%s", line) }
+	reply("220 fake.smtp ESMTP ready")
+
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(line)
+
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250-fake.smtp")
+			if s.tlsConfig != nil && !current.TLS {
+				reply("250-STARTTLS")
+			}
+			reply("250 AUTH PLAIN LOGIN")
+		case command == "STARTTLS":
+			reply("220 Ready to start TLS")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			text = textproto.NewConn(conn)
+			current.TLS = true
+		case strings.HasPrefix(command, "AUTH PLAIN "):
+			decoded, _ := base64.StdEncoding.DecodeString(line[len("AUTH PLAIN "):])
+			parts := strings.Split(string(decoded), "\x00")
+			if len(parts) == 3 {
+				current.Username, current.Password = parts[1], parts[2]
+			}
+			reply("235 2.7.0 Authentication successful")
+		case command == "AUTH LOGIN":
+			reply("334 " + base64.StdEncoding.EncodeToString([]byte("Username:")))
+			username, _ := text.ReadLine()
+			reply("334 " + base64.StdEncoding.EncodeToString([]byte("Password:")))
+			password, _ := text.ReadLine()
+			decodedUser, _ := base64.StdEncoding.DecodeString(username)
+			decodedPass, _ := base64.StdEncoding.DecodeString(password)
+			current.Username, current.Password = string(decodedUser), string(decodedPass)
+			reply("235 2.7.0 Authentication successful")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			current.From = strings.Trim(line[len("MAIL FROM:"):], "<> ")
+			if i := strings.Index(current.From, ">"); i >= 0 {
+				current.From = current.From[:i]
+			}
+			reply("250 2.1.0 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			if s.rcptReply != "" {
+				reply(s.rcptReply)
+				continue
+			}
+			current.To = strings.Trim(line[len("RCPT TO:"):], "<> ")
+			reply("250 2.1.5 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			data, err := io.ReadAll(text.DotReader())
+			if err != nil {
+				return
+			}
+			current.Data = string(data)
+			s.mu.Lock()
+			s.messages = append(s.messages, current)
+			s.mu.Unlock()
+			reply("250 2.0.0 OK queued")
+		case command == "QUIT":
+			reply("221 2.0.0 Bye")
+			return
+		default:
+			reply("502 5.5.2 Command not recognized")
+		}
+	}
+}
+
+// newTestTLSConfigs returns a server TLS config with a self-signed certificate for 127.0.0.1
+// and a client config that trusts it
+func newTestTLSConfigs(t *testing.T) (*tls.Config, *tls.Config) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "fake.smtp"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	serverConfig := &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	clientConfig := &tls.Config{RootCAs: pool}
+	return serverConfig, clientConfig
+}
+
+func TestSMTPEmailProvider_SendEmail_PlainAuth(t *testing.T) {
+	server := newFakeSMTPServer(t, nil, false)
+	provider := NewSMTPEmailProvider(SMTPConfig{
+		Host:     "127.0.0.1",
+		Port:     server.Port(),
+		Username: "user",
+		Password: "secret",
+		TLSMode:  SMTPTLSNone,
+		Auth:     SMTPAuthPlain,
+		Subject:  "Hello there",
+	})
+
+	result, err := provider.SendEmail(context.Background(), "user@usehatchapp.com", "contact@gmail.com", "<p>Hi ✓</p>", nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "smtp", result.Provider)
+	assert.True(t, strings.HasSuffix(result.ProviderMessageID, "@usehatchapp.com>"))
+
+	messages := server.Messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "user@usehatchapp.com", messages[0].From)
+	assert.Equal(t, "contact@gmail.com", messages[0].To)
+	assert.Equal(t, "user", messages[0].Username)
+	assert.Equal(t, "secret", messages[0].Password)
+
+	parsed, err := mail.ReadMessage(strings.NewReader(messages[0].Data))
+	require.NoError(t, err)
+	assert.Equal(t, "Hello there", parsed.Header.Get("Subject"))
+	assert.Equal(t, result.ProviderMessageID, parsed.Header.Get("Message-ID"))
+	assert.Equal(t, "text/html; charset=UTF-8", parsed.Header.Get("Content-Type"))
+
+	// The SMTP client terminates the data with a line break before the final dot
+	body, err := io.ReadAll(quotedprintable.NewReader(parsed.Body))
+	require.NoError(t, err)
+	assert.Equal(t, "<p>Hi ✓</p>", strings.TrimRight(string(body), "\r\n"))
+}
+
+func TestSMTPEmailProvider_SendEmail_StartTLSWithLoginAndAttachment(t *testing.T) {
+	files := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		_, _ = w.Write([]byte("%PDF-1.4 test"))
+	}))
+	defer files.Close()
+
+	serverTLS, clientTLS := newTestTLSConfigs(t)
+	server := newFakeSMTPServer(t, serverTLS, false)
+	provider := NewSMTPEmailProvider(SMTPConfig{
+		Host:      "127.0.0.1",
+		Port:      server.Port(),
+		Username:  "user",
+		Password:  "secret",
+		TLSMode:   SMTPTLSStartTLS,
+		Auth:      SMTPAuthLogin,
+		TLSConfig: clientTLS,
+	})
+
+	_, err := provider.SendEmail(context.Background(), "user@usehatchapp.com", "contact@gmail.com", "See attached", []string{files.URL + "/report.pdf"})
+
+	require.NoError(t, err)
+	messages := server.Messages()
+	require.Len(t, messages, 1)
+	assert.True(t, messages[0].TLS)
+	assert.Equal(t, "user", messages[0].Username)
+	assert.Equal(t, "secret", messages[0].Password)
+
+	parsed, err := mail.ReadMessage(strings.NewReader(messages[0].Data))
+	require.NoError(t, err)
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/mixed", mediaType)
+
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+
+	bodyPart, err := reader.NextPart()
+	require.NoError(t, err)
+	body, err := io.ReadAll(bodyPart)
+	require.NoError(t, err)
+	assert.Equal(t, "See attached", string(body))
+
+	attachmentPart, err := reader.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "report.pdf", attachmentPart.FileName())
+	encoded, err := io.ReadAll(attachmentPart)
+	require.NoError(t, err)
+	content, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(encoded), "\r\n", ""))
+	require.NoError(t, err)
+	assert.Equal(t, "%PDF-1.4 test", string(content))
+
+	_, err = reader.NextPart()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestSMTPEmailProvider_SendEmail_ImplicitTLS(t *testing.T) {
+	serverTLS, clientTLS := newTestTLSConfigs(t)
+	server := newFakeSMTPServer(t, serverTLS, true)
+	provider := NewSMTPEmailProvider(SMTPConfig{
+		Host:      "127.0.0.1",
+		Port:      server.Port(),
+		TLSMode:   SMTPTLSImplicit,
+		TLSConfig: clientTLS,
+	})
+
+	_, err := provider.SendEmail(context.Background(), "user@usehatchapp.com", "contact@gmail.com", "Hello", nil)
+
+	require.NoError(t, err)
+	messages := server.Messages()
+	require.Len(t, messages, 1)
+	assert.True(t, messages[0].TLS)
+	assert.Empty(t, messages[0].Username)
+}
+
+func TestSMTPEmailProvider_SendEmail_ReplyCodes(t *testing.T) {
+	tests := []struct {
+		name              string
+		rcptReply         string
+		expectedCode      int
+		expectedRetryable bool
+	}{
+		{"transient failure", "451 4.3.0 Try again later", http.StatusServiceUnavailable, true},
+		{"mailbox unavailable", "550 5.1.1 No such user", http.StatusBadRequest, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeSMTPServer(t, nil, false)
+			server.rcptReply = tt.rcptReply
+			provider := NewSMTPEmailProvider(SMTPConfig{Host: "127.0.0.1", Port: server.Port(), TLSMode: SMTPTLSNone})
+
+			_, err := provider.SendEmail(context.Background(), "user@usehatchapp.com", "contact@gmail.com", "Hello", nil)
+
+			var providerErr *domain.ProviderError
+			require.ErrorAs(t, err, &providerErr)
+			assert.Equal(t, tt.expectedCode, providerErr.Code)
+			assert.Contains(t, providerErr.Message, tt.rcptReply[:3])
+			assert.Equal(t, tt.expectedRetryable, domain.IsRetryableError(err))
+			assert.Empty(t, server.Messages())
+		})
+	}
+}
+
+func TestSMTPEmailProvider_SendEmail_Unreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	provider := NewSMTPEmailProvider(SMTPConfig{Host: "127.0.0.1", Port: strconv.Itoa(port), TLSMode: SMTPTLSNone})
+
+	_, err = provider.SendEmail(context.Background(), "user@usehatchapp.com", "contact@gmail.com", "Hello", nil)
+
+	assert.Error(t, err)
+	assert.True(t, domain.IsRetryableError(err))
+}
@@ -0,0 +1,168 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"messaging-service/internal/domain"
+)
+
+// DefaultTwilioBaseURL is the base URL of the Twilio REST API
+const DefaultTwilioBaseURL = "https://api.twilio.com"
+
+// TwilioConfig holds Twilio provider configuration
+type TwilioConfig struct {
+	AccountSID string
+	AuthToken  string
+	BaseURL    string
+	Timeout    time.Duration
+}
+
+// TwilioSMSProvider implements domain.SMSProvider using the Twilio Messages API
+type TwilioSMSProvider struct {
+	accountSID string
+	authToken  string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewTwilioSMSProvider creates a new Twilio SMS provider
+func NewTwilioSMSProvider(accountSID, authToken string) *TwilioSMSProvider {
+	return NewTwilioSMSProviderWithConfig(TwilioConfig{AccountSID: accountSID, AuthToken: authToken})
+}
+
+// NewTwilioSMSProviderWithConfig creates a new Twilio SMS provider with custom configuration
+func NewTwilioSMSProviderWithConfig(config TwilioConfig) *TwilioSMSProvider {
+	if config.BaseURL == "" {
+		config.BaseURL = DefaultTwilioBaseURL
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 30 * time.Second
+	}
+
+	return &TwilioSMSProvider{
+		accountSID: config.AccountSID,
+		authToken:  config.AuthToken,
+		baseURL:    strings.TrimRight(config.BaseURL, "/"),
+		httpClient: &http.Client{
+			Timeout: config.Timeout,
+		},
+	}
+}
+
+// twilioMessage is the subset of the Twilio Message resource we read
+type twilioMessage struct {
+	SID         string `json:"sid"`
+	Status      string `json:"status"`
+	NumSegments string `json:"num_segments"`
+	DateCreated string `json:"date_created"`
+}
+
+// twilioErrorResponse is the error body returned by the Twilio REST API
+type twilioErrorResponse struct {
+	Code     int    `json:"code"`
+	Message  string `json:"message"`
+	MoreInfo string `json:"more_info"`
+}
+
+// SendSMS sends an SMS through Twilio
+func (p *TwilioSMSProvider) SendSMS(ctx context.Context, from, to, body string) (*domain.SendResult, error) {
+	return p.send(ctx, from, to, body, nil)
+}
+
+// SendMMS sends an MMS through Twilio, passing each attachment as a MediaUrl
+func (p *TwilioSMSProvider) SendMMS(ctx context.Context, from, to, body string, attachments []string) (*domain.SendResult, error) {
+	return p.send(ctx, from, to, body, attachments)
+}
+
+func (p *TwilioSMSProvider) send(ctx context.Context, from, to, body string, mediaURLs []string) (*domain.SendResult, error) {
+	form := url.Values{}
+	form.Set("From", from)
+	form.Set("To", to)
+	form.Set("Body", body)
+	for _, mediaURL := range mediaURLs {
+		form.Add("MediaUrl", mediaURL)
+	}
+
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", p.baseURL, url.PathEscape(p.accountSID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Twilio request: %w", err)
+	}
+	req.SetBasicAuth(p.accountSID, p.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, transportError(ctx, string(SMSProviderTwilio), "Twilio", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return nil, transportError(ctx, string(SMSProviderTwilio), "Twilio", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, p.responseError(resp, respBody)
+	}
+
+	var message twilioMessage
+	if err := json.Unmarshal(respBody, &message); err != nil {
+		return nil, fmt.Errorf("failed to decode Twilio response: %w", err)
+	}
+
+	return &domain.SendResult{
+		ProviderMessageID: message.SID,
+		AcceptedAt:        twilioAcceptedAt(message.DateCreated),
+		Segments:          twilioSegments(message.NumSegments, body, len(mediaURLs) > 0),
+		Provider:          string(SMSProviderTwilio),
+	}, nil
+}
+
+// responseError converts a non-2xx Twilio response into a domain.ProviderError
+func (p *TwilioSMSProvider) responseError(resp *http.Response, body []byte) error {
+	providerErr := &domain.ProviderError{
+		Code:     resp.StatusCode,
+		Message:  fmt.Sprintf("Twilio error: %d", resp.StatusCode),
+		Provider: string(SMSProviderTwilio),
+	}
+
+	var errorResponse twilioErrorResponse
+	if json.Unmarshal(body, &errorResponse) == nil && errorResponse.Message != "" {
+		providerErr.Message = fmt.Sprintf("Twilio error %d: %s", errorResponse.Code, errorResponse.Message)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		providerErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	}
+
+	return providerErr
+}
+
+// twilioAcceptedAt parses Twilio's RFC 2822 creation date, falling back to the current time
+func twilioAcceptedAt(dateCreated string) time.Time {
+	if accepted, err := time.Parse(time.RFC1123Z, dateCreated); err == nil {
+		return accepted.UTC()
+	}
+	return time.Now().UTC()
+}
+
+// twilioSegments reads the segment count reported by Twilio, estimating it when missing
+func twilioSegments(numSegments, body string, isMMS bool) int {
+	if segments, err := strconv.Atoi(numSegments); err == nil && segments > 0 {
+		return segments
+	}
+	if isMMS {
+		return 1
+	}
+	return CountSMSSegments(body)
+}
@@ -0,0 +1,141 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"messaging-service/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTwilioProvider(serverURL string) *TwilioSMSProvider {
+	return NewTwilioSMSProviderWithConfig(TwilioConfig{
+		AccountSID: "AC123",
+		AuthToken:  "secret",
+		BaseURL:    serverURL,
+	})
+}
+
+func TestTwilioSMSProvider_SendSMS_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/2010-04-01/Accounts/AC123/Messages.json", r.URL.Path)
+		assert.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"))
+
+		user, pass, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "AC123", user)
+		assert.Equal(t, "secret", pass)
+
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "+12016661234", r.PostForm.Get("From"))
+		assert.Equal(t, "+18045551234", r.PostForm.Get("To"))
+		assert.Equal(t, "Hello, World!", r.PostForm.Get("Body"))
+		assert.Empty(t, r.PostForm["MediaUrl"])
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"sid":"SM123","status":"queued","num_segments":"2","date_created":"Fri, 01 Nov 2024 12:00:00 +0000"}`))
+	}))
+	defer server.Close()
+
+	provider := newTestTwilioProvider(server.URL)
+
+	result, err := provider.SendSMS(context.Background(), "+12016661234", "+18045551234", "Hello, World!")
+
+	require.NoError(t, err)
+	assert.Equal(t, "SM123", result.ProviderMessageID)
+	assert.Equal(t, "twilio", result.Provider)
+	assert.Equal(t, 2, result.Segments)
+	assert.Equal(t, time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC), result.AcceptedAt)
+}
+
+func TestTwilioSMSProvider_SendMMS_PassesMediaURLs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, []string{"https://example.com/a.jpg", "https://example.com/b.png"}, r.PostForm["MediaUrl"])
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"sid":"MM123","status":"queued"}`))
+	}))
+	defer server.Close()
+
+	provider := newTestTwilioProvider(server.URL)
+
+	result, err := provider.SendMMS(context.Background(), "+12016661234", "+18045551234", "Look", []string{"https://example.com/a.jpg", "https://example.com/b.png"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "MM123", result.ProviderMessageID)
+	assert.Equal(t, 1, result.Segments)
+	assert.False(t, result.AcceptedAt.IsZero())
+}
+
+func TestTwilioSMSProvider_SendSMS_ErrorResponses(t *testing.T) {
+	tests := []struct {
+		name               string
+		status             int
+		headers            map[string]string
+		body               string
+		expectedMessage    string
+		expectedRetryAfter int
+		expectedRetryable  bool
+	}{
+		{
+			name:              "invalid number",
+			status:            http.StatusBadRequest,
+			body:              `{"code":21211,"message":"The 'To' number is not a valid phone number.","status":400}`,
+			expectedMessage:   "Twilio error 21211: The 'To' number is not a valid phone number.",
+			expectedRetryable: false,
+		},
+		{
+			name:               "rate limited",
+			status:             http.StatusTooManyRequests,
+			headers:            map[string]string{"Retry-After": "5"},
+			body:               `{"code":20429,"message":"Too Many Requests","status":429}`,
+			expectedMessage:    "Twilio error 20429: Too Many Requests",
+			expectedRetryAfter: 5,
+			expectedRetryable:  true,
+		},
+		{
+			name:              "server error without body",
+			status:            http.StatusServiceUnavailable,
+			expectedMessage:   "Twilio error: 503",
+			expectedRetryable: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for key, value := range tt.headers {
+					w.Header().Set(key, value)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			provider := newTestTwilioProvider(server.URL)
+
+			_, err := provider.SendSMS(context.Background(), "+12016661234", "+18045551234", "Hello")
+
+			var providerErr *domain.ProviderError
+			require.ErrorAs(t, err, &providerErr)
+			assert.Equal(t, tt.status, providerErr.Code)
+			assert.Equal(t, tt.expectedMessage, providerErr.Message)
+			assert.Equal(t, tt.expectedRetryAfter, providerErr.RetryAfter)
+			assert.Equal(t, tt.expectedRetryable, domain.IsRetryableError(err))
+		})
+	}
+}
+
+func TestNewSMSProvider(t *testing.T) {
+	assert.IsType(t, &TwilioSMSProvider{}, NewSMSProvider(SMSProviderTwilio, map[string]string{"account_sid": "AC123", "auth_token": "secret"}))
+	assert.IsType(t, &MockSMSProvider{}, NewSMSProvider(SMSProviderMock, nil))
+	assert.IsType(t, &MockSMSProvider{}, NewSMSProvider("unknown", nil))
+}
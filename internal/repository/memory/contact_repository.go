@@ -0,0 +1,152 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"messaging-service/internal/domain"
+)
+
+type contactRepository struct {
+	mu             sync.RWMutex
+	nextID         int
+	nextIdentityID int
+	contacts       map[int]*domain.Contact
+}
+
+// NewContactRepository creates a new in-memory contact repository
+func NewContactRepository() domain.ContactRepository {
+	return &contactRepository{
+		nextID:         1,
+		nextIdentityID: 1,
+		contacts:       make(map[int]*domain.Contact),
+	}
+}
+
+func (r *contactRepository) Create(ctx context.Context, contact *domain.Contact) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, identity := range contact.Identities {
+		if r.findByAddress(identity.Address) != nil || slices.ContainsFunc(contact.Identities[:i], func(other domain.ContactIdentity) bool {
+			return other.Address == identity.Address
+		}) {
+			return fmt.Errorf("contact identity %s already exists: %w", identity.Address, domain.ErrConflict)
+		}
+	}
+
+	now := time.Now()
+	contact.ID = r.nextID
+	contact.CreatedAt = now
+	contact.UpdatedAt = now
+	r.nextID++
+
+	for i := range contact.Identities {
+		contact.Identities[i].ID = r.nextIdentityID
+		contact.Identities[i].ContactID = contact.ID
+		contact.Identities[i].CreatedAt = now
+		r.nextIdentityID++
+	}
+	if contact.Identities == nil {
+		contact.Identities = []domain.ContactIdentity{}
+	}
+
+	r.contacts[contact.ID] = cloneContact(contact)
+	return nil
+}
+
+func (r *contactRepository) GetByID(ctx context.Context, id int) (*domain.Contact, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	contact, ok := r.contacts[id]
+	if !ok {
+		return nil, fmt.Errorf("contact %d: %w", id, domain.ErrNotFound)
+	}
+
+	return cloneContact(contact), nil
+}
+
+func (r *contactRepository) GetByAddress(ctx context.Context, address string) (*domain.Contact, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	contact := r.findByAddress(address)
+	if contact == nil {
+		return nil, fmt.Errorf("contact with identity %s: %w", address, domain.ErrNotFound)
+	}
+
+	return cloneContact(contact), nil
+}
+
+func (r *contactRepository) MoveIdentities(ctx context.Context, fromContactID, toContactID int, addresses []string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	from, ok := r.contacts[fromContactID]
+	if !ok {
+		return 0, nil
+	}
+	to, ok := r.contacts[toContactID]
+	if !ok {
+		return 0, fmt.Errorf("contact %d: %w", toContactID, domain.ErrNotFound)
+	}
+
+	var kept []domain.ContactIdentity
+	moved := 0
+	for _, identity := range from.Identities {
+		if !slices.Contains(addresses, identity.Address) {
+			kept = append(kept, identity)
+			continue
+		}
+		identity.ContactID = toContactID
+		to.Identities = append(to.Identities, identity)
+		moved++
+	}
+	if moved == 0 {
+		return 0, nil
+	}
+
+	now := time.Now()
+	from.Identities = append([]domain.ContactIdentity{}, kept...)
+	from.UpdatedAt = now
+	slices.SortFunc(to.Identities, func(a, b domain.ContactIdentity) int { return a.ID - b.ID })
+	to.UpdatedAt = now
+
+	return moved, nil
+}
+
+func (r *contactRepository) Delete(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.contacts[id]; !ok {
+		return fmt.Errorf("contact %d: %w", id, domain.ErrNotFound)
+	}
+	delete(r.contacts, id)
+
+	return nil
+}
+
+// findByAddress returns the contact with an identity of the address
+func (r *contactRepository) findByAddress(address string) *domain.Contact {
+	for _, contact := range r.contacts {
+		for _, identity := range contact.Identities {
+			if identity.Address == address {
+				return contact
+			}
+		}
+	}
+	return nil
+}
+
+// cloneContact copies a contact so callers and the repository do not share state
+func cloneContact(contact *domain.Contact) *domain.Contact {
+	copied := *contact
+	copied.Name = cloneString(contact.Name)
+	copied.Identities = append([]domain.ContactIdentity{}, contact.Identities...)
+	return &copied
+}
@@ -0,0 +1,299 @@
+// Package memory provides in-memory repository implementations for tests and local
+// development. They follow the semantics of the PostgreSQL repositories but keep no data
+// across restarts.
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"messaging-service/internal/domain"
+)
+
+type conversationRepository struct {
+	mu            sync.RWMutex
+	nextID        int
+	conversations map[int]*domain.Conversation
+}
+
+// NewConversationRepository creates a new in-memory conversation repository
+func NewConversationRepository() domain.ConversationRepository {
+	return &conversationRepository{
+		nextID:        1,
+		conversations: make(map[int]*domain.Conversation),
+	}
+}
+
+func (r *conversationRepository) Create(ctx context.Context, customerContact, businessContact string) (*domain.Conversation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.findExact(customerContact, businessContact) != nil {
+		return nil, fmt.Errorf("conversation between %s and %s already exists: %w", customerContact, businessContact, domain.ErrConflict)
+	}
+
+	return cloneConversation(r.insert(customerContact, businessContact)), nil
+}
+
+func (r *conversationRepository) GetByID(ctx context.Context, id int) (*domain.Conversation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	conv, ok := r.conversations[id]
+	if !ok {
+		return nil, fmt.Errorf("conversation %d: %w", id, domain.ErrNotFound)
+	}
+
+	return cloneConversation(conv), nil
+}
+
+func (r *conversationRepository) GetByContacts(ctx context.Context, customerContact, businessContact string) (*domain.Conversation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	conv := r.findExact(customerContact, businessContact)
+	if conv == nil {
+		return nil, fmt.Errorf("conversation between %s and %s: %w", customerContact, businessContact, domain.ErrNotFound)
+	}
+
+	return cloneConversation(conv), nil
+}
+
+func (r *conversationRepository) GetOrCreate(ctx context.Context, customerContact, businessContact string) (*domain.Conversation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	conv := r.findExact(customerContact, businessContact)
+	if conv == nil {
+		conv = r.insert(customerContact, businessContact)
+	}
+
+	return cloneConversation(conv), nil
+}
+
+func (r *conversationRepository) RecordMessage(ctx context.Context, conversationID int, at time.Time, preview, direction string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	conv, ok := r.conversations[conversationID]
+	if !ok {
+		return nil
+	}
+
+	// Messages can be recorded out of order, so only a newer message replaces the preview, but
+	// any message counts as activity
+	if conv.LastMessageAt == nil || !at.Before(*conv.LastMessageAt) {
+		conv.LastMessageAt = &at
+		conv.LastMessagePreview = &preview
+		conv.LastMessageDirection = &direction
+	}
+	conv.UpdatedAt = time.Now()
+
+	return nil
+}
+
+func (r *conversationRepository) SwapContacts(ctx context.Context, id int) (*domain.Conversation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	conv, ok := r.conversations[id]
+	if !ok {
+		return nil, fmt.Errorf("conversation %d: %w", id, domain.ErrNotFound)
+	}
+	if r.findExact(conv.BusinessContact, conv.CustomerContact) != nil {
+		return nil, fmt.Errorf("conversation with the swapped contacts of %d already exists: %w", id, domain.ErrConflict)
+	}
+
+	conv.CustomerContact, conv.BusinessContact = conv.BusinessContact, conv.CustomerContact
+	conv.UpdatedAt = time.Now()
+
+	return cloneConversation(conv), nil
+}
+
+func (r *conversationRepository) Delete(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.conversations[id]; !ok {
+		return fmt.Errorf("conversation %d: %w", id, domain.ErrNotFound)
+	}
+	delete(r.conversations, id)
+
+	return nil
+}
+
+func (r *conversationRepository) List(ctx context.Context, query *domain.ConversationQuery) ([]domain.Conversation, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []*domain.Conversation
+	for _, conv := range r.conversations {
+		if conversationMatches(conv, query) {
+			matched = append(matched, conv)
+		}
+	}
+
+	sortConversations(matched, query.SortBy, query.SortOrder == "asc")
+
+	total := len(matched)
+	var conversations []domain.Conversation
+	for _, conv := range page(matched, query.Limit, query.Offset) {
+		conversations = append(conversations, *cloneConversation(conv))
+	}
+
+	return conversations, total, nil
+}
+
+func (r *conversationRepository) ListByCustomerContacts(ctx context.Context, customerContacts []string, businessContact string) ([]domain.Conversation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []*domain.Conversation
+	for _, conv := range r.conversations {
+		if slices.Contains(customerContacts, conv.CustomerContact) && (businessContact == "" || conv.BusinessContact == businessContact) {
+			matched = append(matched, conv)
+		}
+	}
+	sortConversations(matched, "id", true)
+
+	conversations := []domain.Conversation{}
+	for _, conv := range matched {
+		conversations = append(conversations, *cloneConversation(conv))
+	}
+
+	return conversations, nil
+}
+
+// findExact returns the conversation stored with exactly these contacts
+func (r *conversationRepository) findExact(customerContact, businessContact string) *domain.Conversation {
+	for _, conv := range r.conversations {
+		if conv.CustomerContact == customerContact && conv.BusinessContact == businessContact {
+			return conv
+		}
+	}
+	return nil
+}
+
+// insert stores a new conversation; the caller must hold the write lock
+func (r *conversationRepository) insert(customerContact, businessContact string) *domain.Conversation {
+	now := time.Now()
+	conv := &domain.Conversation{
+		ID:              r.nextID,
+		CustomerContact: customerContact,
+		BusinessContact: businessContact,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	r.conversations[conv.ID] = conv
+	r.nextID++
+	return conv
+}
+
+// conversationMatches applies the filters of a conversation query
+func conversationMatches(conv *domain.Conversation, query *domain.ConversationQuery) bool {
+	if !query.From.IsZero() && conv.UpdatedAt.Before(query.From) {
+		return false
+	}
+	if !query.To.IsZero() && conv.UpdatedAt.After(query.To) {
+		return false
+	}
+	if query.Search != "" && !containsFold(conv.CustomerContact, query.Search) && !containsFold(conv.BusinessContact, query.Search) {
+		return false
+	}
+	if query.BusinessEmail != "" && !containsFold(conv.BusinessContact, query.BusinessEmail) {
+		return false
+	}
+	if query.BusinessPhone != "" && !containsFold(conv.BusinessContact, query.BusinessPhone) {
+		return false
+	}
+	if query.Direction != "" && !hasLastDirection(conv, query.Direction) {
+		return false
+	}
+	if query.AwaitingReply && !hasLastDirection(conv, domain.MessageDirectionInbound) {
+		return false
+	}
+	return true
+}
+
+// hasLastDirection reports whether the conversation's last message has the direction
+func hasLastDirection(conv *domain.Conversation, direction string) bool {
+	return conv.LastMessageDirection != nil && *conv.LastMessageDirection == direction
+}
+
+// sortConversations orders conversations by a sort field, defaulting to updated_at.
+// Conversations without messages sort last when ordering by last_message_at.
+func sortConversations(conversations []*domain.Conversation, sortBy string, ascending bool) {
+	compare := func(a, b *domain.Conversation) int {
+		switch sortBy {
+		case "id":
+			return cmp.Compare(a.ID, b.ID)
+		case "created_at":
+			return a.CreatedAt.Compare(b.CreatedAt)
+		case "customer_contact":
+			return strings.Compare(a.CustomerContact, b.CustomerContact)
+		case "business_contact":
+			return strings.Compare(a.BusinessContact, b.BusinessContact)
+		case "last_message_at":
+			return compareTimes(a.LastMessageAt, b.LastMessageAt)
+		default:
+			return a.UpdatedAt.Compare(b.UpdatedAt)
+		}
+	}
+
+	sort.SliceStable(conversations, func(i, j int) bool {
+		a, b := conversations[i], conversations[j]
+		if sortBy == "last_message_at" && (a.LastMessageAt == nil) != (b.LastMessageAt == nil) {
+			return b.LastMessageAt == nil
+		}
+
+		c := compare(a, b)
+		if c == 0 {
+			c = cmp.Compare(a.ID, b.ID)
+		}
+		if ascending {
+			return c < 0
+		}
+		return c > 0
+	})
+}
+
+// page returns the items in the window given by limit and offset
+func page[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	items = items[offset:]
+	if limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+// containsFold reports whether substr is within s, ignoring case like ILIKE
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// compareTimes compares optional times; callers handle nil ordering
+func compareTimes(a, b *time.Time) int {
+	if a == nil || b == nil {
+		return 0
+	}
+	return a.Compare(*b)
+}
+
+// cloneConversation copies a conversation so callers cannot modify stored state
+func cloneConversation(conv *domain.Conversation) *domain.Conversation {
+	copied := *conv
+	copied.LastMessageAt = cloneTime(conv.LastMessageAt)
+	copied.LastMessagePreview = cloneString(conv.LastMessagePreview)
+	copied.LastMessageDirection = cloneString(conv.LastMessageDirection)
+	copied.Messages = nil
+	return &copied
+}
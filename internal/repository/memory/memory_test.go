@@ -0,0 +1,17 @@
+package memory
+
+import (
+	"testing"
+
+	"messaging-service/internal/repository/repositorytest"
+)
+
+func TestRepositoryConformance(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Repositories {
+		return repositorytest.Repositories{
+			Conversations: NewConversationRepository(),
+			Messages:      NewMessageRepository(),
+			Contacts:      NewContactRepository(),
+		}
+	})
+}
@@ -0,0 +1,361 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"messaging-service/internal/domain"
+)
+
+type messageRepository struct {
+	mu            sync.RWMutex
+	nextID        int
+	nextEventID   int
+	nextAttemptID int
+	messages      map[int]*domain.Message
+	events        map[int][]domain.MessageEvent
+	attempts      map[int][]domain.MessageAttempt
+}
+
+// NewMessageRepository creates a new in-memory message repository
+func NewMessageRepository() domain.MessageRepository {
+	return &messageRepository{
+		nextID:        1,
+		nextEventID:   1,
+		nextAttemptID: 1,
+		messages:      make(map[int]*domain.Message),
+		events:        make(map[int][]domain.MessageEvent),
+		attempts:      make(map[int][]domain.MessageAttempt),
+	}
+}
+
+func (r *messageRepository) Create(ctx context.Context, message *domain.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkProviderMessageID(message); err != nil {
+		return err
+	}
+
+	message.ID = r.nextID
+	r.nextID++
+	r.messages[message.ID] = cloneMessage(message)
+	r.recordEvent(message)
+
+	return nil
+}
+
+func (r *messageRepository) GetByID(ctx context.Context, id int) (*domain.Message, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	message, ok := r.messages[id]
+	if !ok {
+		return nil, fmt.Errorf("message %d: %w", id, domain.ErrNotFound)
+	}
+
+	return cloneMessage(message), nil
+}
+
+func (r *messageRepository) GetByConversationID(ctx context.Context, conversationID int) ([]domain.Message, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []*domain.Message
+	for _, message := range r.messages {
+		if message.ConversationID == conversationID {
+			matched = append(matched, message)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if c := matched[i].CreatedAt.Compare(matched[j].CreatedAt); c != 0 {
+			return c < 0
+		}
+		return matched[i].ID < matched[j].ID
+	})
+
+	return cloneMessages(matched), nil
+}
+
+func (r *messageRepository) ListByConversationIDs(ctx context.Context, conversationIDs []int, limit, offset int) ([]domain.Message, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []*domain.Message
+	for _, message := range r.messages {
+		if slices.Contains(conversationIDs, message.ConversationID) {
+			matched = append(matched, message)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if c := matched[i].Timestamp.Compare(matched[j].Timestamp); c != 0 {
+			return c < 0
+		}
+		return matched[i].ID < matched[j].ID
+	})
+
+	return cloneMessages(page(matched, limit, offset)), len(matched), nil
+}
+
+func (r *messageRepository) GetByProviderMessageID(ctx context.Context, providerMessageID string) (*domain.Message, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if message := r.findByProviderMessageID(providerMessageID); message != nil {
+		return cloneMessage(message), nil
+	}
+
+	return nil, fmt.Errorf("message with provider ID %s: %w", providerMessageID, domain.ErrNotFound)
+}
+
+func (r *messageRepository) Update(ctx context.Context, message *domain.Message) error {
+	if _, err := r.update(message, nil); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *messageRepository) UpdateIfStatus(ctx context.Context, message *domain.Message, expectedStatus string) (bool, error) {
+	return r.update(message, &expectedStatus)
+}
+
+// update writes the message's delivery state, optionally only while the stored status equals
+// expectedStatus, and records a status event whenever the status changes
+func (r *messageRepository) update(message *domain.Message, expectedStatus *string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.messages[message.ID]
+	if !ok || (expectedStatus != nil && stored.Status != *expectedStatus) {
+		return false, nil
+	}
+	if err := r.checkProviderMessageID(message); err != nil {
+		return false, err
+	}
+
+	previousStatus := stored.Status
+	updated := cloneMessage(message)
+	stored.Status = updated.Status
+	stored.ErrorCode = updated.ErrorCode
+	stored.ErrorMessage = updated.ErrorMessage
+	stored.MessagingProviderID = updated.MessagingProviderID
+	stored.Provider = updated.Provider
+	stored.SegmentCount = updated.SegmentCount
+	stored.SentAt = updated.SentAt
+	stored.UpdatedAt = time.Now()
+	switch {
+	case stored.Status != domain.MessageStatusFailed:
+		stored.FailedAt = nil
+	case stored.FailedAt == nil:
+		failedAt := stored.UpdatedAt
+		stored.FailedAt = &failedAt
+	}
+
+	if previousStatus != stored.Status {
+		r.recordEvent(stored)
+	}
+
+	return true, nil
+}
+
+func (r *messageRepository) GetEvents(ctx context.Context, messageID int) ([]domain.MessageEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var events []domain.MessageEvent
+	for _, event := range r.events[messageID] {
+		event.ErrorCode = cloneString(event.ErrorCode)
+		event.ErrorMessage = cloneString(event.ErrorMessage)
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+func (r *messageRepository) RecordAttempt(ctx context.Context, attempt *domain.MessageAttempt) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempt.ID = r.nextAttemptID
+	attempt.AttemptNumber = len(r.attempts[attempt.MessageID]) + 1
+	r.nextAttemptID++
+
+	stored := *attempt
+	stored.Provider = cloneString(attempt.Provider)
+	stored.ErrorClass = cloneString(attempt.ErrorClass)
+	stored.ErrorCode = cloneString(attempt.ErrorCode)
+	stored.ErrorMessage = cloneString(attempt.ErrorMessage)
+	r.attempts[attempt.MessageID] = append(r.attempts[attempt.MessageID], stored)
+
+	return nil
+}
+
+func (r *messageRepository) GetAttempts(ctx context.Context, messageID int) ([]domain.MessageAttempt, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var attempts []domain.MessageAttempt
+	for _, attempt := range r.attempts[messageID] {
+		attempt.Provider = cloneString(attempt.Provider)
+		attempt.ErrorClass = cloneString(attempt.ErrorClass)
+		attempt.ErrorCode = cloneString(attempt.ErrorCode)
+		attempt.ErrorMessage = cloneString(attempt.ErrorMessage)
+		attempts = append(attempts, attempt)
+	}
+
+	return attempts, nil
+}
+
+func (r *messageRepository) ListFailed(ctx context.Context, query *domain.DeadLetterQuery) ([]domain.Message, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []*domain.Message
+	for _, message := range r.messages {
+		if message.Status != domain.MessageStatusFailed || message.FailedAt == nil {
+			continue
+		}
+		if query.Provider != "" && (message.Provider == nil || *message.Provider != query.Provider) {
+			continue
+		}
+		if query.ErrorCode != "" && (message.ErrorCode == nil || *message.ErrorCode != query.ErrorCode) {
+			continue
+		}
+		if !query.From.IsZero() && message.FailedAt.Before(query.From) {
+			continue
+		}
+		if !query.To.IsZero() && !message.FailedAt.Before(query.To) {
+			continue
+		}
+		matched = append(matched, message)
+	}
+
+	// Most recently failed first
+	sort.Slice(matched, func(i, j int) bool {
+		if c := matched[i].FailedAt.Compare(*matched[j].FailedAt); c != 0 {
+			return c > 0
+		}
+		return cmp.Compare(matched[i].ID, matched[j].ID) > 0
+	})
+
+	return cloneMessages(page(matched, query.Limit, query.Offset)), len(matched), nil
+}
+
+func (r *messageRepository) Reschedule(ctx context.Context, id int, sendAt time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	message, ok := r.messages[id]
+	if !ok || message.Status != domain.MessageStatusScheduled {
+		return false, nil
+	}
+
+	message.SendAt = &sendAt
+	message.UpdatedAt = time.Now()
+
+	return true, nil
+}
+
+func (r *messageRepository) MoveConversation(ctx context.Context, fromConversationID, toConversationID int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	moved := 0
+	now := time.Now()
+	for _, message := range r.messages {
+		if message.ConversationID == fromConversationID {
+			message.ConversationID = toConversationID
+			message.UpdatedAt = now
+			moved++
+		}
+	}
+
+	return moved, nil
+}
+
+// checkProviderMessageID returns ErrConflict when another message has the message's provider ID
+func (r *messageRepository) checkProviderMessageID(message *domain.Message) error {
+	if message.MessagingProviderID == nil {
+		return nil
+	}
+
+	existing := r.findByProviderMessageID(*message.MessagingProviderID)
+	if existing != nil && existing.ID != message.ID {
+		return fmt.Errorf("message with provider ID %s already exists: %w", *message.MessagingProviderID, domain.ErrConflict)
+	}
+
+	return nil
+}
+
+func (r *messageRepository) findByProviderMessageID(providerMessageID string) *domain.Message {
+	for _, message := range r.messages {
+		if message.MessagingProviderID != nil && *message.MessagingProviderID == providerMessageID {
+			return message
+		}
+	}
+	return nil
+}
+
+// recordEvent appends the message's current status to its history; the caller must hold the write lock
+func (r *messageRepository) recordEvent(message *domain.Message) {
+	r.events[message.ID] = append(r.events[message.ID], domain.MessageEvent{
+		ID:           r.nextEventID,
+		MessageID:    message.ID,
+		Status:       message.Status,
+		ErrorCode:    cloneString(message.ErrorCode),
+		ErrorMessage: cloneString(message.ErrorMessage),
+		CreatedAt:    time.Now(),
+	})
+	r.nextEventID++
+}
+
+// cloneMessage copies a message so callers and the repository do not share state
+func cloneMessage(message *domain.Message) *domain.Message {
+	copied := *message
+	if message.Attachments != nil {
+		copied.Attachments = append([]string{}, message.Attachments...)
+	}
+	copied.FromName = cloneString(message.FromName)
+	copied.ToName = cloneString(message.ToName)
+	copied.ErrorCode = cloneString(message.ErrorCode)
+	copied.ErrorMessage = cloneString(message.ErrorMessage)
+	copied.MessagingProviderID = cloneString(message.MessagingProviderID)
+	copied.Provider = cloneString(message.Provider)
+	if message.SegmentCount != nil {
+		count := *message.SegmentCount
+		copied.SegmentCount = &count
+	}
+	copied.SentAt = cloneTime(message.SentAt)
+	copied.FailedAt = cloneTime(message.FailedAt)
+	copied.SendAt = cloneTime(message.SendAt)
+	return &copied
+}
+
+func cloneMessages(messages []*domain.Message) []domain.Message {
+	var copied []domain.Message
+	for _, message := range messages {
+		copied = append(copied, *cloneMessage(message))
+	}
+	return copied
+}
+
+func cloneString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	copied := *s
+	return &copied
+}
+
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	copied := *t
+	return &copied
+}
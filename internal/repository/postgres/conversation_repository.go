@@ -4,9 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
+
 	"messaging-service/internal/domain"
 
-	_ "github.com/lib/pq" // PostgreSQL driver
+	"github.com/lib/pq"
 )
 
 type conversationRepository struct {
@@ -22,96 +24,140 @@ func (r *conversationRepository) Create(ctx context.Context, customerContact, bu
 	query := `
 		INSERT INTO conversations (customer_contact, business_contact)
 		VALUES ($1, $2)
-		RETURNING id, customer_contact, business_contact, created_at, updated_at
-	`
+		RETURNING ` + conversationColumns
 
-	var conv domain.Conversation
-	err := r.db.QueryRowContext(ctx, query, customerContact, businessContact).Scan(
-		&conv.ID,
-		&conv.CustomerContact,
-		&conv.BusinessContact,
-		&conv.CreatedAt,
-		&conv.UpdatedAt,
-	)
+	conv, err := scanConversation(conn(ctx, r.db).QueryRowContext(ctx, query, customerContact, businessContact))
 
 	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("conversation between %s and %s already exists: %w", customerContact, businessContact, domain.ErrConflict)
+		}
 		return nil, fmt.Errorf("failed to create conversation: %w", err)
 	}
 
-	return &conv, nil
+	return conv, nil
 }
 
 func (r *conversationRepository) GetByID(ctx context.Context, id int) (*domain.Conversation, error) {
 	query := `
-		SELECT id, customer_contact, business_contact, created_at, updated_at
+		SELECT ` + conversationColumns + `
 		FROM conversations
 		WHERE id = $1
 	`
 
-	var conv domain.Conversation
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&conv.ID,
-		&conv.CustomerContact,
-		&conv.BusinessContact,
-		&conv.CreatedAt,
-		&conv.UpdatedAt,
-	)
+	conv, err := scanConversation(conn(ctx, r.db).QueryRowContext(ctx, query, id))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, nil
+			return nil, fmt.Errorf("conversation %d: %w", id, domain.ErrNotFound)
 		}
 		return nil, fmt.Errorf("failed to get conversation by ID: %w", err)
 	}
 
-	return &conv, nil
+	return conv, nil
 }
 
 func (r *conversationRepository) GetByContacts(ctx context.Context, customerContact, businessContact string) (*domain.Conversation, error) {
 	query := `
-		SELECT id, customer_contact, business_contact, created_at, updated_at
+		SELECT ` + conversationColumns + `
 		FROM conversations
-		WHERE (customer_contact = $1 AND business_contact = $2) OR (customer_contact = $2 AND business_contact = $1)
+		WHERE customer_contact = $1 AND business_contact = $2
 	`
 
-	var conv domain.Conversation
-	err := r.db.QueryRowContext(ctx, query, customerContact, businessContact).Scan(
-		&conv.ID,
-		&conv.CustomerContact,
-		&conv.BusinessContact,
-		&conv.CreatedAt,
-		&conv.UpdatedAt,
-	)
+	conv, err := scanConversation(conn(ctx, r.db).QueryRowContext(ctx, query, customerContact, businessContact))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, nil
+			return nil, fmt.Errorf("conversation between %s and %s: %w", customerContact, businessContact, domain.ErrNotFound)
 		}
 		return nil, fmt.Errorf("failed to get conversation by contacts: %w", err)
 	}
 
-	return &conv, nil
+	return conv, nil
 }
 
 func (r *conversationRepository) GetOrCreate(ctx context.Context, customerContact, businessContact string) (*domain.Conversation, error) {
-	// Try to get existing conversation
-	conv, err := r.GetByContacts(ctx, customerContact, businessContact)
+	// Insert atomically so concurrent first messages between the same pair cannot both create it
+	query := `
+		INSERT INTO conversations (customer_contact, business_contact)
+		VALUES ($1, $2)
+		ON CONFLICT (customer_contact, business_contact) DO NOTHING
+		RETURNING ` + conversationColumns
+
+	conv, err := scanConversation(conn(ctx, r.db).QueryRowContext(ctx, query, customerContact, businessContact))
+	if err == nil {
+		return conv, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to create conversation: %w", err)
+	}
+
+	// The conversation already exists. It is read in a separate statement because the insert's
+	// snapshot cannot see a row committed by a concurrent transaction it waited for.
+	return r.GetByContacts(ctx, customerContact, businessContact)
+}
+
+func (r *conversationRepository) RecordMessage(ctx context.Context, conversationID int, at time.Time, preview, direction string) error {
+	// Messages can be recorded out of order, so only a newer message replaces the preview, but
+	// any message counts as activity and bumps updated_at through the trigger
+	query := `
+		UPDATE conversations
+		SET last_message_at = CASE WHEN last_message_at IS NULL OR last_message_at <= $2 THEN $2 ELSE last_message_at END,
+			last_message_preview = CASE WHEN last_message_at IS NULL OR last_message_at <= $2 THEN $3 ELSE last_message_preview END,
+			last_message_direction = CASE WHEN last_message_at IS NULL OR last_message_at <= $2 THEN $4 ELSE last_message_direction END
+		WHERE id = $1
+	`
+
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, conversationID, at, preview, direction); err != nil {
+		return fmt.Errorf("failed to record conversation message: %w", err)
+	}
+
+	return nil
+}
+
+func (r *conversationRepository) SwapContacts(ctx context.Context, id int) (*domain.Conversation, error) {
+	// The right-hand sides see the values from before the update
+	query := `
+		UPDATE conversations
+		SET customer_contact = business_contact, business_contact = customer_contact
+		WHERE id = $1
+		RETURNING ` + conversationColumns
+
+	conv, err := scanConversation(conn(ctx, r.db).QueryRowContext(ctx, query, id))
 	if err != nil {
-		return nil, err
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("conversation %d: %w", id, domain.ErrNotFound)
+		}
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("conversation with the swapped contacts of %d already exists: %w", id, domain.ErrConflict)
+		}
+		return nil, fmt.Errorf("failed to swap conversation contacts: %w", err)
 	}
 
-	if conv != nil {
-		return conv, nil
+	return conv, nil
+}
+
+func (r *conversationRepository) Delete(ctx context.Context, id int) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM conversations WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete conversation: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("conversation %d: %w", id, domain.ErrNotFound)
 	}
 
-	// Create new conversation if it doesn't exist
-	return r.Create(ctx, customerContact, businessContact)
+	return nil
 }
 
 func (r *conversationRepository) List(ctx context.Context, query *domain.ConversationQuery) ([]domain.Conversation, int, error) {
 	// Build the base query
 	baseQuery := `
-		SELECT id, customer_contact, business_contact, created_at, updated_at
+		SELECT ` + conversationColumns + `
 		FROM conversations
 		WHERE 1=1
 	`
@@ -165,6 +211,20 @@ func (r *conversationRepository) List(ctx context.Context, query *domain.Convers
 		argIndex++
 	}
 
+	// Add last message direction filtering
+	if query.Direction != "" {
+		conditions = append(conditions, fmt.Sprintf("last_message_direction = $%d", argIndex))
+		args = append(args, query.Direction)
+		argIndex++
+	}
+
+	// The customer wrote last and has not been answered yet
+	if query.AwaitingReply {
+		conditions = append(conditions, fmt.Sprintf("last_message_direction = $%d", argIndex))
+		args = append(args, domain.MessageDirectionInbound)
+		argIndex++
+	}
+
 	// Add conditions to both queries
 	for _, condition := range conditions {
 		baseQuery += " AND " + condition
@@ -173,7 +233,7 @@ func (r *conversationRepository) List(ctx context.Context, query *domain.Convers
 
 	// Get total count for pagination
 	var total int
-	err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total)
+	err := conn(ctx, r.db).QueryRowContext(ctx, countQuery, args...).Scan(&total)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count conversations: %w", err)
 	}
@@ -189,19 +249,20 @@ func (r *conversationRepository) List(ctx context.Context, query *domain.Convers
 		// Validate sort field to prevent SQL injection
 		validSortFields := map[string]bool{
 			"id": true, "created_at": true, "updated_at": true,
-			"customer_contact": true, "business_contact": true,
+			"customer_contact": true, "business_contact": true, "last_message_at": true,
 		}
 		if validSortFields[query.SortBy] {
 			sortBy = query.SortBy
 		}
 	}
 
-	baseQuery += fmt.Sprintf(" ORDER BY %s %s", sortBy, sortOrder)
+	// Conversations without messages sort last in either direction
+	baseQuery += fmt.Sprintf(" ORDER BY %s %s NULLS LAST", sortBy, sortOrder)
 	baseQuery += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
 	args = append(args, query.Limit, query.Offset)
 
 	// Execute the query
-	rows, err := r.db.QueryContext(ctx, baseQuery, args...)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, baseQuery, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list conversations: %w", err)
 	}
@@ -209,18 +270,11 @@ func (r *conversationRepository) List(ctx context.Context, query *domain.Convers
 
 	var conversations []domain.Conversation
 	for rows.Next() {
-		var conv domain.Conversation
-		err := rows.Scan(
-			&conv.ID,
-			&conv.CustomerContact,
-			&conv.BusinessContact,
-			&conv.CreatedAt,
-			&conv.UpdatedAt,
-		)
+		conv, err := scanConversation(rows)
 		if err != nil {
 			return nil, 0, fmt.Errorf("failed to scan conversation: %w", err)
 		}
-		conversations = append(conversations, conv)
+		conversations = append(conversations, *conv)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -229,3 +283,57 @@ func (r *conversationRepository) List(ctx context.Context, query *domain.Convers
 
 	return conversations, total, nil
 }
+
+func (r *conversationRepository) ListByCustomerContacts(ctx context.Context, customerContacts []string, businessContact string) ([]domain.Conversation, error) {
+	where := " WHERE customer_contact = ANY($1)"
+	args := []interface{}{pq.Array(customerContacts)}
+	if businessContact != "" {
+		where += " AND business_contact = $2"
+		args = append(args, businessContact)
+	}
+
+	query := "SELECT " + conversationColumns + " FROM conversations" + where + " ORDER BY id ASC"
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conversations by customer contacts: %w", err)
+	}
+	defer rows.Close()
+
+	conversations := []domain.Conversation{}
+	for rows.Next() {
+		conv, err := scanConversation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan conversation: %w", err)
+		}
+		conversations = append(conversations, *conv)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating conversations: %w", err)
+	}
+
+	return conversations, nil
+}
+
+// conversationColumns lists the conversation columns in the order scanConversation expects them
+const conversationColumns = `id, customer_contact, business_contact, last_message_at, last_message_preview, last_message_direction, created_at, updated_at`
+
+// scanConversation scans a row selected with conversationColumns into a conversation
+func scanConversation(row rowScanner) (*domain.Conversation, error) {
+	var conv domain.Conversation
+	err := row.Scan(
+		&conv.ID,
+		&conv.CustomerContact,
+		&conv.BusinessContact,
+		&conv.LastMessageAt,
+		&conv.LastMessagePreview,
+		&conv.LastMessageDirection,
+		&conv.CreatedAt,
+		&conv.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &conv, nil
+}
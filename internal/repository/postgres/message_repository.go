@@ -5,9 +5,12 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
+
 	"messaging-service/internal/domain"
 
-	_ "github.com/lib/pq" // PostgreSQL driver
+	"github.com/lib/pq"
 )
 
 type messageRepository struct {
@@ -20,10 +23,21 @@ func NewMessageRepository(db *sql.DB) domain.MessageRepository {
 }
 
 func (r *messageRepository) Create(ctx context.Context, message *domain.Message) error {
+	return insertMessage(ctx, conn(ctx, r.db), message)
+}
+
+// insertMessage inserts a message and its initial status event using the given querier and sets its generated ID
+func insertMessage(ctx context.Context, q querier, message *domain.Message) error {
 	query := `
-		INSERT INTO messages (conversation_id, from_address, to_address, message_type, body, attachments, provider_message_id, status, timestamp, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-		RETURNING id
+		WITH inserted AS (
+			INSERT INTO messages (conversation_id, from_address, to_address, from_name, to_name, message_type, direction, body, attachments, provider_message_id, provider, segment_count, sent_at, send_at, status, timestamp, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+			RETURNING id, status, error_code, error_message
+		), event AS (
+			INSERT INTO message_events (message_id, status, error_code, error_message)
+			SELECT id, status, error_code, error_message FROM inserted
+		)
+		SELECT id FROM inserted
 	`
 
 	// Serialize attachments to JSON
@@ -32,14 +46,21 @@ func (r *messageRepository) Create(ctx context.Context, message *domain.Message)
 		return fmt.Errorf("failed to marshal attachments: %w", err)
 	}
 
-	err = r.db.QueryRowContext(ctx, query,
+	err = q.QueryRowContext(ctx, query,
 		message.ConversationID,
 		message.From,
 		message.To,
+		message.FromName,
+		message.ToName,
 		message.Type,
+		message.Direction,
 		message.Body,
 		attachmentsJSON,
 		message.MessagingProviderID,
+		message.Provider,
+		message.SegmentCount,
+		message.SentAt,
+		message.SendAt,
 		message.Status,
 		message.Timestamp,
 		message.CreatedAt,
@@ -47,6 +68,9 @@ func (r *messageRepository) Create(ctx context.Context, message *domain.Message)
 	).Scan(&message.ID)
 
 	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("message with provider ID %s already exists: %w", *message.MessagingProviderID, domain.ErrConflict)
+		}
 		return fmt.Errorf("failed to create message: %w", err)
 	}
 
@@ -55,65 +79,144 @@ func (r *messageRepository) Create(ctx context.Context, message *domain.Message)
 
 func (r *messageRepository) GetByID(ctx context.Context, id int) (*domain.Message, error) {
 	query := `
-		SELECT id, conversation_id, from_address, to_address, message_type, body, attachments, provider_message_id, status, error_code, error_message, timestamp, created_at, updated_at
+		SELECT ` + messageColumns + `
 		FROM messages
 		WHERE id = $1
 	`
 
-	var message domain.Message
-	var attachmentsJSON []byte
+	message, err := scanMessage(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("message %d: %w", id, domain.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get message by ID: %w", err)
+	}
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&message.ID,
-		&message.ConversationID,
-		&message.From,
-		&message.To,
-		&message.Type,
-		&message.Body,
-		&attachmentsJSON,
-		&message.MessagingProviderID,
-		&message.Status,
-		&message.ErrorCode,
-		&message.ErrorMessage,
-		&message.Timestamp,
-		&message.CreatedAt,
-		&message.UpdatedAt,
-	)
+	return message, nil
+}
 
+func (r *messageRepository) GetByProviderMessageID(ctx context.Context, providerMessageID string) (*domain.Message, error) {
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages
+		WHERE provider_message_id = $1
+	`
+
+	message, err := scanMessage(conn(ctx, r.db).QueryRowContext(ctx, query, providerMessageID))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, nil
+			return nil, fmt.Errorf("message with provider ID %s: %w", providerMessageID, domain.ErrNotFound)
 		}
-		return nil, fmt.Errorf("failed to get message by ID: %w", err)
+		return nil, fmt.Errorf("failed to get message by provider ID: %w", err)
 	}
 
-	// Deserialize attachments from JSON
-	if err := json.Unmarshal(attachmentsJSON, &message.Attachments); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal attachments: %w", err)
+	return message, nil
+}
+
+func (r *messageRepository) GetByConversationID(ctx context.Context, conversationID int) ([]domain.Message, error) {
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages
+		WHERE conversation_id = $1
+		ORDER BY created_at ASC
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get messages by conversation ID: %w", err)
 	}
+	defer rows.Close()
 
-	return &message, nil
+	var messages []domain.Message
+	for rows.Next() {
+		message, err := scanMessage(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+
+		messages = append(messages, *message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating messages: %w", err)
+	}
+
+	return messages, nil
 }
 
-func (r *messageRepository) GetByProviderMessageID(ctx context.Context, providerMessageID string) (*domain.Message, error) {
+func (r *messageRepository) ListByConversationIDs(ctx context.Context, conversationIDs []int, limit, offset int) ([]domain.Message, int, error) {
+	ids := make([]int64, 0, len(conversationIDs))
+	for _, id := range conversationIDs {
+		ids = append(ids, int64(id))
+	}
+
+	// Get total count for pagination
+	var total int
+	if err := conn(ctx, r.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM messages WHERE conversation_id = ANY($1)", pq.Array(ids)).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count messages by conversation IDs: %w", err)
+	}
+
 	query := `
-		SELECT id, conversation_id, from_address, to_address, message_type, body, attachments, provider_message_id, status, error_code, error_message, timestamp, created_at, updated_at
+		SELECT ` + messageColumns + `
 		FROM messages
-		WHERE provider_message_id = $1
+		WHERE conversation_id = ANY($1)
+		ORDER BY timestamp ASC, id ASC
+		LIMIT $2 OFFSET $3
 	`
 
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, pq.Array(ids), limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get messages by conversation IDs: %w", err)
+	}
+	defer rows.Close()
+
+	messages := []domain.Message{}
+	for rows.Next() {
+		message, err := scanMessage(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan message: %w", err)
+		}
+
+		messages = append(messages, *message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating messages: %w", err)
+	}
+
+	return messages, total, nil
+}
+
+// messageColumns lists the message columns in the order scanMessage expects them
+const messageColumns = `id, conversation_id, from_address, to_address, from_name, to_name, message_type, direction, body, attachments, provider_message_id, provider, segment_count, sent_at, send_at, failed_at, status, error_code, error_message, timestamp, created_at, updated_at`
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanMessage scans a row selected with messageColumns into a message
+func scanMessage(row rowScanner) (*domain.Message, error) {
 	var message domain.Message
 	var attachmentsJSON []byte
 
-	err := r.db.QueryRowContext(ctx, query, providerMessageID).Scan(
+	err := row.Scan(
 		&message.ID,
 		&message.ConversationID,
 		&message.From,
 		&message.To,
+		&message.FromName,
+		&message.ToName,
 		&message.Type,
+		&message.Direction,
 		&message.Body,
 		&attachmentsJSON,
 		&message.MessagingProviderID,
+		&message.Provider,
+		&message.SegmentCount,
+		&message.SentAt,
+		&message.SendAt,
+		&message.FailedAt,
 		&message.Status,
 		&message.ErrorCode,
 		&message.ErrorMessage,
@@ -121,12 +224,8 @@ func (r *messageRepository) GetByProviderMessageID(ctx context.Context, provider
 		&message.CreatedAt,
 		&message.UpdatedAt,
 	)
-
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("failed to get message by provider ID: %w", err)
+		return nil, err
 	}
 
 	// Deserialize attachments from JSON
@@ -137,77 +236,275 @@ func (r *messageRepository) GetByProviderMessageID(ctx context.Context, provider
 	return &message, nil
 }
 
-func (r *messageRepository) GetByConversationID(ctx context.Context, conversationID int) ([]domain.Message, error) {
+func (r *messageRepository) Update(ctx context.Context, message *domain.Message) error {
+	if _, err := r.update(ctx, message, nil); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *messageRepository) UpdateIfStatus(ctx context.Context, message *domain.Message, expectedStatus string) (bool, error) {
+	return r.update(ctx, message, &expectedStatus)
+}
+
+// update writes the message's status fields, optionally only while the stored status equals expectedStatus.
+// A status event is recorded in the same statement whenever the status changes.
+func (r *messageRepository) update(ctx context.Context, message *domain.Message, expectedStatus *string) (bool, error) {
 	query := `
-		SELECT id, conversation_id, from_address, to_address, message_type, body, attachments, provider_message_id, status, error_code, error_message, timestamp, created_at, updated_at
-		FROM messages
-		WHERE conversation_id = $1
-		ORDER BY created_at ASC
+		WITH previous AS (
+			SELECT status FROM messages WHERE id = $4 FOR UPDATE
+		), updated AS (
+			UPDATE messages
+			SET status = $1, error_code = $2, error_message = $3,
+				provider_message_id = $6, provider = $7, segment_count = $8, sent_at = $9,
+				failed_at = CASE WHEN $10 THEN COALESCE(failed_at, CURRENT_TIMESTAMP) END,
+				updated_at = CURRENT_TIMESTAMP
+			WHERE id = $4 AND ($5::VARCHAR IS NULL OR status = $5)
+			RETURNING id, status, error_code, error_message
+		), event AS (
+			INSERT INTO message_events (message_id, status, error_code, error_message)
+			SELECT updated.id, updated.status, updated.error_code, updated.error_message
+			FROM updated, previous
+			WHERE previous.status IS DISTINCT FROM updated.status
+		)
+		SELECT COUNT(*) FROM updated
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, conversationID)
+	var updated int
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		message.Status,
+		message.ErrorCode,
+		message.ErrorMessage,
+		message.ID,
+		expectedStatus,
+		message.MessagingProviderID,
+		message.Provider,
+		message.SegmentCount,
+		message.SentAt,
+		message.Status == domain.MessageStatusFailed,
+	).Scan(&updated)
+
 	if err != nil {
-		return nil, fmt.Errorf("failed to get messages by conversation ID: %w", err)
+		if isUniqueViolation(err) {
+			return false, fmt.Errorf("message with provider ID %s already exists: %w", *message.MessagingProviderID, domain.ErrConflict)
+		}
+		return false, fmt.Errorf("failed to update message: %w", err)
+	}
+
+	return updated > 0, nil
+}
+
+func (r *messageRepository) GetEvents(ctx context.Context, messageID int) ([]domain.MessageEvent, error) {
+	query := `
+		SELECT id, message_id, status, error_code, error_message, created_at
+		FROM message_events
+		WHERE message_id = $1
+		ORDER BY created_at ASC, id ASC
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message events: %w", err)
 	}
 	defer rows.Close()
 
-	var messages []domain.Message
+	var events []domain.MessageEvent
 	for rows.Next() {
-		var message domain.Message
-		var attachmentsJSON []byte
-
+		var event domain.MessageEvent
 		err := rows.Scan(
-			&message.ID,
-			&message.ConversationID,
-			&message.From,
-			&message.To,
-			&message.Type,
-			&message.Body,
-			&attachmentsJSON,
-			&message.MessagingProviderID,
-			&message.Status,
-			&message.ErrorCode,
-			&message.ErrorMessage,
-			&message.Timestamp,
-			&message.CreatedAt,
-			&message.UpdatedAt,
+			&event.ID,
+			&event.MessageID,
+			&event.Status,
+			&event.ErrorCode,
+			&event.ErrorMessage,
+			&event.CreatedAt,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan message: %w", err)
+			return nil, fmt.Errorf("failed to scan message event: %w", err)
 		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating message events: %w", err)
+	}
+
+	return events, nil
+}
+
+func (r *messageRepository) RecordAttempt(ctx context.Context, attempt *domain.MessageAttempt) error {
+	query := `
+		INSERT INTO message_attempts (message_id, attempt_number, succeeded, provider, error_class,
+			error_code, error_message, duration_ms, retry_delay_ms, started_at)
+		SELECT $1, COALESCE(MAX(attempt_number), 0) + 1, $2, $3, $4, $5, $6, $7, $8, $9
+		FROM message_attempts
+		WHERE message_id = $1
+		RETURNING id, attempt_number
+	`
 
-		// Deserialize attachments from JSON
-		if err := json.Unmarshal(attachmentsJSON, &message.Attachments); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal attachments: %w", err)
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		attempt.MessageID,
+		attempt.Succeeded,
+		attempt.Provider,
+		attempt.ErrorClass,
+		attempt.ErrorCode,
+		attempt.ErrorMessage,
+		attempt.DurationMs,
+		attempt.RetryDelayMs,
+		attempt.StartedAt,
+	).Scan(&attempt.ID, &attempt.AttemptNumber)
+
+	if err != nil {
+		return fmt.Errorf("failed to record message attempt: %w", err)
+	}
+
+	return nil
+}
+
+func (r *messageRepository) GetAttempts(ctx context.Context, messageID int) ([]domain.MessageAttempt, error) {
+	query := `
+		SELECT id, message_id, attempt_number, succeeded, provider, error_class,
+			error_code, error_message, duration_ms, retry_delay_ms, started_at
+		FROM message_attempts
+		WHERE message_id = $1
+		ORDER BY attempt_number ASC
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message attempts: %w", err)
+	}
+	defer rows.Close()
+
+	var attempts []domain.MessageAttempt
+	for rows.Next() {
+		var attempt domain.MessageAttempt
+		err := rows.Scan(
+			&attempt.ID,
+			&attempt.MessageID,
+			&attempt.AttemptNumber,
+			&attempt.Succeeded,
+			&attempt.Provider,
+			&attempt.ErrorClass,
+			&attempt.ErrorCode,
+			&attempt.ErrorMessage,
+			&attempt.DurationMs,
+			&attempt.RetryDelayMs,
+			&attempt.StartedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan message attempt: %w", err)
 		}
+		attempts = append(attempts, attempt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating message attempts: %w", err)
+	}
+
+	return attempts, nil
+}
+
+func (r *messageRepository) ListFailed(ctx context.Context, query *domain.DeadLetterQuery) ([]domain.Message, int, error) {
+	conditions := []string{fmt.Sprintf("status = '%s'", domain.MessageStatusFailed)}
+	var args []interface{}
+	argIndex := 1
+
+	// Add filters
+	if query.Provider != "" {
+		conditions = append(conditions, fmt.Sprintf("provider = $%d", argIndex))
+		args = append(args, query.Provider)
+		argIndex++
+	}
+
+	if query.ErrorCode != "" {
+		conditions = append(conditions, fmt.Sprintf("error_code = $%d", argIndex))
+		args = append(args, query.ErrorCode)
+		argIndex++
+	}
+
+	if !query.From.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("failed_at >= $%d", argIndex))
+		args = append(args, query.From)
+		argIndex++
+	}
 
-		messages = append(messages, message)
+	if !query.To.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("failed_at < $%d", argIndex))
+		args = append(args, query.To)
+		argIndex++
+	}
+
+	where := " WHERE " + strings.Join(conditions, " AND ")
+
+	// Get total count for pagination
+	var total int
+	if err := conn(ctx, r.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM messages"+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count failed messages: %w", err)
+	}
+
+	listQuery := "SELECT " + messageColumns + " FROM messages" + where +
+		fmt.Sprintf(" ORDER BY failed_at DESC, id DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	args = append(args, query.Limit, query.Offset)
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, listQuery, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list failed messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []domain.Message
+	for rows.Next() {
+		message, err := scanMessage(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan message: %w", err)
+		}
+		messages = append(messages, *message)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating messages: %w", err)
+		return nil, 0, fmt.Errorf("error iterating failed messages: %w", err)
 	}
 
-	return messages, nil
+	return messages, total, nil
 }
 
-func (r *messageRepository) Update(ctx context.Context, message *domain.Message) error {
+func (r *messageRepository) Reschedule(ctx context.Context, id int, sendAt time.Time) (bool, error) {
 	query := `
-		UPDATE messages 
-		SET status = $1, error_code = $2, error_message = $3, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $4
+		UPDATE messages
+		SET send_at = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = $3
 	`
 
-	_, err := r.db.ExecContext(ctx, query,
-		message.Status,
-		message.ErrorCode,
-		message.ErrorMessage,
-		message.ID,
-	)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id, sendAt, domain.MessageStatusScheduled)
+	if err != nil {
+		return false, fmt.Errorf("failed to reschedule message: %w", err)
+	}
 
+	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("failed to update message: %w", err)
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
 	}
 
-	return nil
+	return rowsAffected > 0, nil
+}
+
+func (r *messageRepository) MoveConversation(ctx context.Context, fromConversationID, toConversationID int) (int, error) {
+	query := `
+		UPDATE messages
+		SET conversation_id = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE conversation_id = $1
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, fromConversationID, toConversationID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to move messages: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
 }
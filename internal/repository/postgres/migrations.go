@@ -0,0 +1,15 @@
+package postgres
+
+import (
+	"embed"
+
+	"messaging-service/internal/migrate"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migrations returns the PostgreSQL schema migrations embedded in the binary
+func Migrations() ([]migrate.Migration, error) {
+	return migrate.Load(migrationFiles, "migrations")
+}
@@ -0,0 +1,5 @@
+-- Revert the initial schema
+
+DROP TABLE IF EXISTS messages;
+DROP TABLE IF EXISTS conversations;
+DROP FUNCTION IF EXISTS update_updated_at_column();
@@ -0,0 +1,7 @@
+-- Revert the transactional outbox
+
+DROP TABLE IF EXISTS outbox;
+
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS messages_status_check;
+ALTER TABLE messages ADD CONSTRAINT messages_status_check
+    CHECK (status IN ('pending', 'delivered', 'failed', 'bounced')) NOT VALID;
@@ -0,0 +1,22 @@
+-- Transactional outbox for outbound message delivery
+
+-- Allow outbound messages to be marked as sent once accepted by the provider. Existing rows
+-- are not validated since databases created before versioned migrations may already use
+-- statuses added later; 007 replaces the constraint with a validated one.
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS messages_status_check;
+ALTER TABLE messages ADD CONSTRAINT messages_status_check
+    CHECK (status IN ('pending', 'sent', 'delivered', 'failed', 'bounced')) NOT VALID;
+
+-- Create outbox table
+CREATE TABLE IF NOT EXISTS outbox (
+    id SERIAL PRIMARY KEY,
+    message_id INTEGER NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    available_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_available_at ON outbox(available_at);
+CREATE INDEX IF NOT EXISTS idx_outbox_message_id ON outbox(message_id);
@@ -0,0 +1,3 @@
+-- Revert message status history
+
+DROP TABLE IF EXISTS message_events;
@@ -0,0 +1,19 @@
+-- Status history for messages
+
+-- Create message events table
+CREATE TABLE IF NOT EXISTS message_events (
+    id SERIAL PRIMARY KEY,
+    message_id INTEGER NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL,
+    error_code VARCHAR(50),
+    error_message TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_events_message_id ON message_events(message_id);
+
+-- Seed the history of existing messages with their current status
+INSERT INTO message_events (message_id, status, error_code, error_message, created_at)
+SELECT m.id, m.status, m.error_code, m.error_message, m.updated_at
+FROM messages m
+WHERE NOT EXISTS (SELECT 1 FROM message_events e WHERE e.message_id = m.id);
@@ -0,0 +1,5 @@
+-- Revert provider send results
+
+ALTER TABLE messages DROP COLUMN IF EXISTS sent_at;
+ALTER TABLE messages DROP COLUMN IF EXISTS segment_count;
+ALTER TABLE messages DROP COLUMN IF EXISTS provider;
@@ -0,0 +1,5 @@
+-- Provider send results for outbound messages
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS provider VARCHAR(50);
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS segment_count INTEGER;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS sent_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,3 @@
+-- Revert delivery attempts
+
+DROP TABLE IF EXISTS message_attempts;
@@ -0,0 +1,17 @@
+-- Delivery attempts made against providers for outbound messages
+
+-- Create message attempts table
+CREATE TABLE IF NOT EXISTS message_attempts (
+    id SERIAL PRIMARY KEY,
+    message_id INTEGER NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    attempt_number INTEGER NOT NULL,
+    succeeded BOOLEAN NOT NULL,
+    provider VARCHAR(50),
+    error_class VARCHAR(20),
+    error_code VARCHAR(50),
+    error_message TEXT,
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    retry_delay_ms BIGINT NOT NULL DEFAULT 0,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    UNIQUE (message_id, attempt_number)
+);
@@ -0,0 +1,3 @@
+-- Revert failed message listing support
+
+DROP INDEX IF EXISTS idx_messages_failed;
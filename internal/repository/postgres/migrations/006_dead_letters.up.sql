@@ -0,0 +1,4 @@
+-- Support listing failed outbound messages
+
+-- Failed messages are listed most recently failed first
+CREATE INDEX IF NOT EXISTS idx_messages_failed ON messages(updated_at DESC) WHERE status = 'failed';
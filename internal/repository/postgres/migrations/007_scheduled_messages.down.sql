@@ -0,0 +1,9 @@
+-- Revert scheduled outbound messages
+
+DROP INDEX IF EXISTS idx_messages_scheduled;
+ALTER TABLE messages DROP COLUMN IF EXISTS send_at;
+
+-- Scheduled and cancelled messages may remain, so existing rows are not validated
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS messages_status_check;
+ALTER TABLE messages ADD CONSTRAINT messages_status_check
+    CHECK (status IN ('pending', 'sent', 'delivered', 'failed', 'bounced')) NOT VALID;
@@ -0,0 +1,11 @@
+-- Scheduled outbound messages
+
+-- Scheduled messages wait for their send time; cancelled ones are never sent
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS messages_status_check;
+ALTER TABLE messages ADD CONSTRAINT messages_status_check
+    CHECK (status IN ('scheduled', 'pending', 'sent', 'delivered', 'failed', 'bounced', 'cancelled'));
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS send_at TIMESTAMP WITH TIME ZONE;
+
+-- The scheduler only looks at messages still waiting to be sent
+CREATE INDEX IF NOT EXISTS idx_messages_scheduled ON messages(send_at) WHERE status = 'scheduled';
@@ -0,0 +1,3 @@
+-- Revert idempotency keys
+
+DROP TABLE IF EXISTS idempotency_keys;
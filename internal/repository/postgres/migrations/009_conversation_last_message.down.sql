@@ -0,0 +1,5 @@
+-- Revert conversation last message tracking
+
+DROP INDEX IF EXISTS idx_conversations_last_message_at;
+ALTER TABLE conversations DROP COLUMN IF EXISTS last_message_preview;
+ALTER TABLE conversations DROP COLUMN IF EXISTS last_message_at;
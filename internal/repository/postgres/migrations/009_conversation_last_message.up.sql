@@ -0,0 +1,16 @@
+-- Track the most recent message of each conversation
+
+ALTER TABLE conversations ADD COLUMN IF NOT EXISTS last_message_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE conversations ADD COLUMN IF NOT EXISTS last_message_preview TEXT;
+
+-- Backfill from existing messages
+UPDATE conversations c
+SET last_message_at = m.timestamp, last_message_preview = LEFT(m.body, 100)
+FROM (
+    SELECT DISTINCT ON (conversation_id) conversation_id, timestamp, body
+    FROM messages
+    ORDER BY conversation_id, timestamp DESC, id DESC
+) m
+WHERE m.conversation_id = c.id AND c.last_message_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_conversations_last_message_at ON conversations(last_message_at);
@@ -0,0 +1,4 @@
+-- Revert unique provider message IDs; provider message IDs cleared from duplicates are not restored
+
+CREATE INDEX IF NOT EXISTS idx_messages_provider_id ON messages(provider_message_id);
+DROP INDEX IF EXISTS idx_messages_provider_message_id_unique;
@@ -0,0 +1,16 @@
+-- Provider message IDs identify a single message, which makes inbound webhook deduplication race-free
+
+-- Webhook deliveries that raced before this index existed may have stored the same provider
+-- message ID on several messages. The earliest message keeps the ID and later copies lose it,
+-- so that the unique index can be built.
+UPDATE messages
+SET provider_message_id = NULL
+WHERE provider_message_id IS NOT NULL
+    AND EXISTS (
+        SELECT 1 FROM messages earlier
+        WHERE earlier.provider_message_id = messages.provider_message_id
+            AND earlier.id < messages.id
+    );
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_messages_provider_message_id_unique ON messages(provider_message_id);
+DROP INDEX IF EXISTS idx_messages_provider_id;
@@ -0,0 +1,4 @@
+-- Revert message display names
+
+ALTER TABLE messages DROP COLUMN IF EXISTS to_name;
+ALTER TABLE messages DROP COLUMN IF EXISTS from_name;
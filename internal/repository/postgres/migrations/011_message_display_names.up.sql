@@ -0,0 +1,4 @@
+-- Display names given with message addresses, kept apart from the canonical addresses
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS from_name TEXT;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS to_name TEXT;
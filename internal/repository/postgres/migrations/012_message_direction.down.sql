@@ -0,0 +1,6 @@
+-- Revert message direction
+
+DROP INDEX IF EXISTS idx_conversations_last_message_direction;
+ALTER TABLE conversations DROP COLUMN IF EXISTS last_message_direction;
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS messages_direction_check;
+ALTER TABLE messages DROP COLUMN IF EXISTS direction;
@@ -0,0 +1,32 @@
+-- Record whether a message was received from or sent to the customer
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS direction VARCHAR(10);
+
+-- Inbound messages are stored as delivered and never pass through another status or a provider
+UPDATE messages m
+SET direction = CASE
+    WHEN m.status <> 'delivered'
+        OR EXISTS (SELECT 1 FROM message_attempts a WHERE a.message_id = m.id)
+        OR EXISTS (SELECT 1 FROM message_events e WHERE e.message_id = m.id AND e.status <> 'delivered')
+    THEN 'outbound'
+    ELSE 'inbound'
+END
+WHERE m.direction IS NULL;
+
+ALTER TABLE messages ALTER COLUMN direction SET NOT NULL;
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS messages_direction_check;
+ALTER TABLE messages ADD CONSTRAINT messages_direction_check CHECK (direction IN ('inbound', 'outbound'));
+
+-- Track the direction of the most recent message of each conversation
+ALTER TABLE conversations ADD COLUMN IF NOT EXISTS last_message_direction VARCHAR(10);
+
+UPDATE conversations c
+SET last_message_direction = m.direction
+FROM (
+    SELECT DISTINCT ON (conversation_id) conversation_id, direction
+    FROM messages
+    ORDER BY conversation_id, timestamp DESC, id DESC
+) m
+WHERE m.conversation_id = c.id AND c.last_message_direction IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_conversations_last_message_direction ON conversations(last_message_direction);
@@ -0,0 +1,26 @@
+-- Contacts group the phone numbers and email addresses a customer uses across channels
+
+CREATE TABLE IF NOT EXISTS contacts (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- An address identifies at most one contact
+CREATE TABLE IF NOT EXISTS contact_identities (
+    id SERIAL PRIMARY KEY,
+    contact_id INTEGER NOT NULL REFERENCES contacts(id) ON DELETE CASCADE,
+    type VARCHAR(10) NOT NULL CHECK (type IN ('phone', 'email')),
+    address VARCHAR(255) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_contact_identities_contact_id ON contact_identities(contact_id);
+
+-- Timelines look conversations up by customer contact alone
+CREATE INDEX IF NOT EXISTS idx_conversations_customer_contact ON conversations(customer_contact);
+
+DROP TRIGGER IF EXISTS update_contacts_updated_at ON contacts;
+CREATE TRIGGER update_contacts_updated_at BEFORE UPDATE ON contacts
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
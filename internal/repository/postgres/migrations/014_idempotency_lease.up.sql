@@ -0,0 +1,8 @@
+-- Let a request abandoned while holding an idempotency key release it after a short lease
+
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP WITH TIME ZONE;
+
+-- Keys left in progress by earlier versions are abandoned
+UPDATE idempotency_keys SET locked_until = created_at WHERE locked_until IS NULL;
+
+ALTER TABLE idempotency_keys ALTER COLUMN locked_until SET NOT NULL;
@@ -0,0 +1,5 @@
+-- Revert message failure times
+
+DROP INDEX IF EXISTS idx_messages_failed;
+CREATE INDEX IF NOT EXISTS idx_messages_failed ON messages(updated_at DESC) WHERE status = 'failed';
+ALTER TABLE messages DROP COLUMN IF EXISTS failed_at;
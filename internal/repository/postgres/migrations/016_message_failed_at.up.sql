@@ -0,0 +1,16 @@
+-- Record when a message failed, so that listing failed messages does not depend on updated_at,
+-- which later maintenance such as moving messages between conversations also changes
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS failed_at TIMESTAMP WITH TIME ZONE;
+
+-- The most recent failed status event is the failure time; updated_at is the best guess without one
+UPDATE messages
+SET failed_at = COALESCE(
+    (SELECT MAX(e.created_at) FROM message_events e WHERE e.message_id = messages.id AND e.status = 'failed'),
+    updated_at
+)
+WHERE status = 'failed';
+
+-- Failed messages are listed most recently failed first
+DROP INDEX IF EXISTS idx_messages_failed;
+CREATE INDEX IF NOT EXISTS idx_messages_failed ON messages(failed_at DESC) WHERE status = 'failed';
@@ -0,0 +1,205 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"messaging-service/internal/domain"
+)
+
+type outboxRepository struct {
+	db *sql.DB
+}
+
+// NewOutboxRepository creates a new outbox repository
+func NewOutboxRepository(db *sql.DB) domain.OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+// outboxColumns lists the outbox columns in the order scanOutboxEntry expects them
+const outboxColumns = `id, message_id, attempts, available_at, last_error, created_at, updated_at`
+
+// scanOutboxEntry scans a row selected with outboxColumns into an outbox entry
+func scanOutboxEntry(row rowScanner) (*domain.OutboxEntry, error) {
+	var entry domain.OutboxEntry
+	err := row.Scan(
+		&entry.ID,
+		&entry.MessageID,
+		&entry.Attempts,
+		&entry.AvailableAt,
+		&entry.LastError,
+		&entry.CreatedAt,
+		&entry.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// insertOutboxEntry creates an outbox entry for a message using the given querier
+func insertOutboxEntry(ctx context.Context, q querier, messageID int, availableAt time.Time) (*domain.OutboxEntry, error) {
+	query := `
+		INSERT INTO outbox (message_id, available_at)
+		VALUES ($1, $2)
+		RETURNING ` + outboxColumns
+
+	entry, err := scanOutboxEntry(q.QueryRowContext(ctx, query, messageID, availableAt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create outbox entry: %w", err)
+	}
+	return entry, nil
+}
+
+func (r *outboxRepository) Enqueue(ctx context.Context, message *domain.Message, availableAt time.Time) (*domain.OutboxEntry, error) {
+	var entry *domain.OutboxEntry
+	err := inTransaction(ctx, r.db, func(tx *sql.Tx) error {
+		if err := insertMessage(ctx, tx, message); err != nil {
+			return err
+		}
+
+		var err error
+		entry, err = insertOutboxEntry(ctx, tx, message.ID, availableAt)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (r *outboxRepository) ClaimDue(ctx context.Context, limit int, leaseDuration time.Duration) ([]domain.OutboxEntry, error) {
+	// SKIP LOCKED lets several dispatchers drain the outbox concurrently without claiming the same entry
+	query := `
+		UPDATE outbox
+		SET attempts = attempts + 1, available_at = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id IN (
+			SELECT id FROM outbox
+			WHERE available_at <= CURRENT_TIMESTAMP
+			ORDER BY available_at ASC
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + outboxColumns
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, limit, time.Now().Add(leaseDuration))
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []domain.OutboxEntry
+	for rows.Next() {
+		entry, err := scanOutboxEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan outbox entry: %w", err)
+		}
+		entries = append(entries, *entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating outbox entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (r *outboxRepository) Release(ctx context.Context, id int, availableAt time.Time, lastError string) error {
+	query := `
+		UPDATE outbox
+		SET available_at = $1, last_error = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+	`
+
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, availableAt, lastError, id); err != nil {
+		return fmt.Errorf("failed to release outbox entry: %w", err)
+	}
+
+	return nil
+}
+
+func (r *outboxRepository) Delete(ctx context.Context, id int) error {
+	if _, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM outbox WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete outbox entry: %w", err)
+	}
+
+	return nil
+}
+
+func (r *outboxRepository) Requeue(ctx context.Context, message *domain.Message, availableAt time.Time) (*domain.OutboxEntry, error) {
+	resetQuery := `
+		UPDATE messages
+		SET status = $2, error_code = NULL, error_message = NULL, provider = NULL, failed_at = NULL,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = $3
+		RETURNING updated_at
+	`
+
+	var entry *domain.OutboxEntry
+	var updatedAt time.Time
+	err := inTransaction(ctx, r.db, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, resetQuery, message.ID, domain.MessageStatusPending, domain.MessageStatusFailed).Scan(&updatedAt)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("message %d is not failed: %w", message.ID, domain.ErrConflict)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to reset message: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `INSERT INTO message_events (message_id, status) VALUES ($1, $2)`,
+			message.ID, domain.MessageStatusPending); err != nil {
+			return fmt.Errorf("failed to record message event: %w", err)
+		}
+
+		entry, err = insertOutboxEntry(ctx, tx, message.ID, availableAt)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	message.Status = domain.MessageStatusPending
+	message.ErrorCode = nil
+	message.ErrorMessage = nil
+	message.Provider = nil
+	message.UpdatedAt = updatedAt
+
+	return entry, nil
+}
+
+func (r *outboxRepository) EnqueueScheduled(ctx context.Context, limit int) (int, error) {
+	// SKIP LOCKED lets several schedulers run without moving the same message twice, and the
+	// status condition keeps cancelled messages from being sent
+	query := `
+		WITH due AS (
+			UPDATE messages
+			SET status = $2, updated_at = CURRENT_TIMESTAMP
+			WHERE id IN (
+				SELECT id FROM messages
+				WHERE status = $3 AND send_at <= CURRENT_TIMESTAMP
+				ORDER BY send_at ASC
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, status
+		), event AS (
+			INSERT INTO message_events (message_id, status)
+			SELECT id, status FROM due
+		), entries AS (
+			INSERT INTO outbox (message_id)
+			SELECT id FROM due
+		)
+		SELECT COUNT(*) FROM due
+	`
+
+	var count int
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, limit, domain.MessageStatusPending, domain.MessageStatusScheduled).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue scheduled messages: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"messaging-service/internal/domain"
+)
+
+// txKey is the context key of the transaction started by WithinTransaction
+type txKey struct{}
+
+type transactor struct {
+	db *sql.DB
+}
+
+// NewTransactor creates a transactor whose transactions are joined by all repositories
+// sharing the same database
+func NewTransactor(db *sql.DB) domain.Transactor {
+	return &transactor{db: db}
+}
+
+func (t *transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return inTransaction(ctx, t.db, func(tx *sql.Tx) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// inTransaction runs fn in the transaction carried by ctx, or in a new transaction that is
+// committed when fn succeeds and rolled back otherwise
+func inTransaction(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(tx)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// conn returns the transaction carried by ctx, or db when there is none
+func conn(ctx context.Context, db *sql.DB) querier {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
@@ -0,0 +1,834 @@
+// Package repositorytest provides a conformance test suite that every implementation of the
+// conversation, message and contact repositories must pass, so that backends stay interchangeable.
+package repositorytest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"messaging-service/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Repositories are the repositories under test, backed by the same empty store
+type Repositories struct {
+	Conversations domain.ConversationRepository
+	Messages      domain.MessageRepository
+	Contacts      domain.ContactRepository
+}
+
+// Factory returns empty repositories for a single test
+type Factory func(t *testing.T) Repositories
+
+// Run runs the conformance suite against the repositories returned by newRepositories
+func Run(t *testing.T, newRepositories Factory) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, repos Repositories)
+	}{
+		{"ConversationCreateAndGet", testConversationCreateAndGet},
+		{"ConversationGetOrCreate", testConversationGetOrCreate},
+		{"ConversationRecordMessage", testConversationRecordMessage},
+		{"ConversationSwapContacts", testConversationSwapContacts},
+		{"ConversationDelete", testConversationDelete},
+		{"ConversationListFilters", testConversationListFilters},
+		{"ConversationListSortingAndPagination", testConversationListSortingAndPagination},
+		{"ConversationListByCustomerContacts", testConversationListByCustomerContacts},
+		{"MessageCreateAndGet", testMessageCreateAndGet},
+		{"MessageDisplayNames", testMessageDisplayNames},
+		{"MessageDirection", testMessageDirection},
+		{"MessageProviderIDUniqueness", testMessageProviderIDUniqueness},
+		{"MessageGetByConversationID", testMessageGetByConversationID},
+		{"MessageListByConversationIDs", testMessageListByConversationIDs},
+		{"MessageUpdateRecordsEvents", testMessageUpdateRecordsEvents},
+		{"MessageUpdateIfStatus", testMessageUpdateIfStatus},
+		{"MessageAttempts", testMessageAttempts},
+		{"MessageListFailed", testMessageListFailed},
+		{"MessageReschedule", testMessageReschedule},
+		{"MessageMoveConversation", testMessageMoveConversation},
+		{"ContactCreateAndGet", testContactCreateAndGet},
+		{"ContactMoveIdentities", testContactMoveIdentities},
+		{"ContactDelete", testContactDelete},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newRepositories(t))
+		})
+	}
+}
+
+// now returns the current time at the precision every backend stores
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+// createMessage stores a message in a new or existing conversation between the contacts
+func createMessage(t *testing.T, repos Repositories, message domain.Message) *domain.Message {
+	t.Helper()
+
+	conv, err := repos.Conversations.GetOrCreate(context.Background(), message.From, message.To)
+	require.NoError(t, err)
+
+	message.ConversationID = conv.ID
+	if message.Type == "" {
+		message.Type = domain.MessageTypeSMS
+	}
+	if message.Direction == "" {
+		message.Direction = domain.MessageDirectionOutbound
+	}
+	if message.Status == "" {
+		message.Status = domain.MessageStatusPending
+	}
+	if message.Timestamp.IsZero() {
+		message.Timestamp = now()
+	}
+	if message.CreatedAt.IsZero() {
+		message.CreatedAt = now()
+		message.UpdatedAt = message.CreatedAt
+	}
+
+	require.NoError(t, repos.Messages.Create(context.Background(), &message))
+	return &message
+}
+
+func conversationIDs(conversations []domain.Conversation) []int {
+	ids := []int{}
+	for _, conv := range conversations {
+		ids = append(ids, conv.ID)
+	}
+	return ids
+}
+
+func messageIDs(messages []domain.Message) []int {
+	ids := []int{}
+	for _, message := range messages {
+		ids = append(ids, message.ID)
+	}
+	return ids
+}
+
+func testConversationCreateAndGet(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	created, err := repos.Conversations.Create(ctx, "+12016661234", "+18045551234")
+	require.NoError(t, err)
+	assert.NotZero(t, created.ID)
+	assert.Equal(t, "+12016661234", created.CustomerContact)
+	assert.Equal(t, "+18045551234", created.BusinessContact)
+	assert.Nil(t, created.LastMessageAt)
+	assert.False(t, created.CreatedAt.IsZero())
+
+	_, err = repos.Conversations.Create(ctx, "+12016661234", "+18045551234")
+	assert.ErrorIs(t, err, domain.ErrConflict)
+
+	byID, err := repos.Conversations.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, byID.ID)
+	assert.Equal(t, created.BusinessContact, byID.BusinessContact)
+
+	byContacts, err := repos.Conversations.GetByContacts(ctx, "+12016661234", "+18045551234")
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, byContacts.ID)
+
+	// The contacts are matched in their roles
+	_, err = repos.Conversations.GetByContacts(ctx, "+18045551234", "+12016661234")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	_, err = repos.Conversations.GetByID(ctx, created.ID+1000)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	_, err = repos.Conversations.GetByContacts(ctx, "+12016661234", "+10000000000")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func testConversationGetOrCreate(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	first, err := repos.Conversations.GetOrCreate(ctx, "contact@gmail.com", "user@usehatchapp.com")
+	require.NoError(t, err)
+
+	second, err := repos.Conversations.GetOrCreate(ctx, "contact@gmail.com", "user@usehatchapp.com")
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, second.ID)
+
+	other, err := repos.Conversations.GetOrCreate(ctx, "other@gmail.com", "user@usehatchapp.com")
+	require.NoError(t, err)
+	assert.NotEqual(t, first.ID, other.ID)
+}
+
+func testConversationRecordMessage(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	conv, err := repos.Conversations.Create(ctx, "+12016661234", "+18045551234")
+	require.NoError(t, err)
+
+	latest := now()
+	require.NoError(t, repos.Conversations.RecordMessage(ctx, conv.ID, latest, "Latest", domain.MessageDirectionInbound))
+	recorded, err := repos.Conversations.GetByID(ctx, conv.ID)
+	require.NoError(t, err)
+	assert.False(t, recorded.UpdatedAt.Before(conv.UpdatedAt))
+
+	// An older message arriving late does not replace the preview, but still counts as activity
+	time.Sleep(time.Millisecond)
+	require.NoError(t, repos.Conversations.RecordMessage(ctx, conv.ID, latest.Add(-time.Minute), "Older", domain.MessageDirectionOutbound))
+
+	updated, err := repos.Conversations.GetByID(ctx, conv.ID)
+	require.NoError(t, err)
+	require.NotNil(t, updated.LastMessageAt)
+	assert.True(t, latest.Equal(*updated.LastMessageAt))
+	assert.Equal(t, "Latest", *updated.LastMessagePreview)
+	assert.Equal(t, domain.MessageDirectionInbound, *updated.LastMessageDirection)
+	assert.True(t, updated.UpdatedAt.After(recorded.UpdatedAt))
+
+	// Recording against a missing conversation is not an error
+	assert.NoError(t, repos.Conversations.RecordMessage(ctx, conv.ID+1000, latest, "Missing", domain.MessageDirectionInbound))
+}
+
+func testConversationSwapContacts(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	conv, err := repos.Conversations.Create(ctx, "+12016661234", "+18045551234")
+	require.NoError(t, err)
+
+	swapped, err := repos.Conversations.SwapContacts(ctx, conv.ID)
+	require.NoError(t, err)
+	assert.Equal(t, conv.ID, swapped.ID)
+	assert.Equal(t, "+18045551234", swapped.CustomerContact)
+	assert.Equal(t, "+12016661234", swapped.BusinessContact)
+
+	stored, err := repos.Conversations.GetByContacts(ctx, "+18045551234", "+12016661234")
+	require.NoError(t, err)
+	assert.Equal(t, conv.ID, stored.ID)
+
+	// Swapping into the contacts of another conversation conflicts
+	_, err = repos.Conversations.Create(ctx, "+12016661234", "+18045551234")
+	require.NoError(t, err)
+	_, err = repos.Conversations.SwapContacts(ctx, conv.ID)
+	assert.ErrorIs(t, err, domain.ErrConflict)
+
+	_, err = repos.Conversations.SwapContacts(ctx, conv.ID+1000)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func testConversationDelete(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	conv, err := repos.Conversations.Create(ctx, "+12016661234", "+18045551234")
+	require.NoError(t, err)
+
+	require.NoError(t, repos.Conversations.Delete(ctx, conv.ID))
+	_, err = repos.Conversations.GetByID(ctx, conv.ID)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	assert.ErrorIs(t, repos.Conversations.Delete(ctx, conv.ID), domain.ErrNotFound)
+}
+
+func testConversationListFilters(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	sms, err := repos.Conversations.Create(ctx, "+12016661234", "+18045551234")
+	require.NoError(t, err)
+	email, err := repos.Conversations.Create(ctx, "contact@gmail.com", "Sales@UseHatchApp.com")
+	require.NoError(t, err)
+	other, err := repos.Conversations.Create(ctx, "friend@gmail.com", "support@example.com")
+	require.NoError(t, err)
+	require.NoError(t, repos.Conversations.RecordMessage(ctx, sms.ID, now(), "Question", domain.MessageDirectionInbound))
+	require.NoError(t, repos.Conversations.RecordMessage(ctx, email.ID, now(), "Answer", domain.MessageDirectionOutbound))
+
+	tests := []struct {
+		name     string
+		query    domain.ConversationQuery
+		expected []int
+	}{
+		{"no filters", domain.ConversationQuery{}, []int{sms.ID, email.ID, other.ID}},
+		{"search either contact ignoring case", domain.ConversationQuery{Search: "GMAIL"}, []int{email.ID, other.ID}},
+		{"business email", domain.ConversationQuery{BusinessEmail: "usehatchapp"}, []int{email.ID}},
+		{"business phone", domain.ConversationQuery{BusinessPhone: "804555"}, []int{sms.ID}},
+		{"last message outbound", domain.ConversationQuery{Direction: domain.MessageDirectionOutbound}, []int{email.ID}},
+		{"awaiting reply", domain.ConversationQuery{AwaitingReply: true}, []int{sms.ID}},
+		{"awaiting reply with business filter", domain.ConversationQuery{AwaitingReply: true, BusinessEmail: "usehatchapp"}, []int{}},
+		{"updated after", domain.ConversationQuery{From: time.Now().Add(time.Hour)}, []int{}},
+		{"updated before", domain.ConversationQuery{To: time.Now().Add(-time.Hour)}, []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := tt.query
+			query.Limit = 10
+			query.SortBy = "id"
+			query.SortOrder = "asc"
+
+			conversations, total, err := repos.Conversations.List(ctx, &query)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, conversationIDs(conversations))
+			assert.Equal(t, len(tt.expected), total)
+		})
+	}
+}
+
+func testConversationListSortingAndPagination(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	var ids []int
+	for _, contact := range []string{"+13333333333", "+11111111111", "+12222222222"} {
+		conv, err := repos.Conversations.Create(ctx, contact, "+18045551234")
+		require.NoError(t, err)
+		ids = append(ids, conv.ID)
+	}
+	// Only the first two conversations have messages, the second one more recently
+	require.NoError(t, repos.Conversations.RecordMessage(ctx, ids[0], now().Add(-time.Minute), "Earlier", domain.MessageDirectionOutbound))
+	require.NoError(t, repos.Conversations.RecordMessage(ctx, ids[1], now(), "Later", domain.MessageDirectionOutbound))
+
+	tests := []struct {
+		name     string
+		query    domain.ConversationQuery
+		expected []int
+		total    int
+	}{
+		{"by contact ascending", domain.ConversationQuery{SortBy: "customer_contact", SortOrder: "asc", Limit: 10}, []int{ids[1], ids[2], ids[0]}, 3},
+		{"by contact descending", domain.ConversationQuery{SortBy: "customer_contact", SortOrder: "desc", Limit: 10}, []int{ids[0], ids[2], ids[1]}, 3},
+		{"by last message with missing last", domain.ConversationQuery{SortBy: "last_message_at", SortOrder: "desc", Limit: 10}, []int{ids[1], ids[0], ids[2]}, 3},
+		{"by last message ascending with missing last", domain.ConversationQuery{SortBy: "last_message_at", SortOrder: "asc", Limit: 10}, []int{ids[0], ids[1], ids[2]}, 3},
+		{"unknown field sorts by updated_at", domain.ConversationQuery{SortBy: "body; DROP TABLE", SortOrder: "desc", Limit: 1}, []int{ids[1]}, 3},
+		{"limit and offset", domain.ConversationQuery{SortBy: "id", SortOrder: "asc", Limit: 1, Offset: 1}, []int{ids[1]}, 3},
+		{"offset past the end", domain.ConversationQuery{SortBy: "id", SortOrder: "asc", Limit: 10, Offset: 5}, []int{}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conversations, total, err := repos.Conversations.List(ctx, &tt.query)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, conversationIDs(conversations))
+			assert.Equal(t, tt.total, total)
+		})
+	}
+}
+
+func testConversationListByCustomerContacts(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	sms, err := repos.Conversations.Create(ctx, "+18045551234", "+12016661234")
+	require.NoError(t, err)
+	email, err := repos.Conversations.Create(ctx, "contact@gmail.com", "user@usehatchapp.com")
+	require.NoError(t, err)
+	// The customer's number appearing as the business contact does not match
+	_, err = repos.Conversations.Create(ctx, "+12016661234", "+18045551234")
+	require.NoError(t, err)
+
+	other, err := repos.Conversations.Create(ctx, "+18045551234", "+12016660000")
+	require.NoError(t, err)
+
+	conversations, err := repos.Conversations.ListByCustomerContacts(ctx, []string{"contact@gmail.com", "+18045551234"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, []int{sms.ID, email.ID, other.ID}, conversationIDs(conversations))
+
+	// Only conversations with the business contact
+	conversations, err = repos.Conversations.ListByCustomerContacts(ctx, []string{"contact@gmail.com", "+18045551234"}, "+12016661234")
+	require.NoError(t, err)
+	assert.Equal(t, []int{sms.ID}, conversationIDs(conversations))
+
+	conversations, err = repos.Conversations.ListByCustomerContacts(ctx, nil, "")
+	require.NoError(t, err)
+	assert.Empty(t, conversations)
+}
+
+func testMessageCreateAndGet(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	sendAt := now().Add(time.Hour)
+	created := createMessage(t, repos, domain.Message{
+		From:        "+12016661234",
+		To:          "+18045551234",
+		Type:        domain.MessageTypeMMS,
+		Body:        "Hello",
+		Attachments: []string{"https://example.com/image.png"},
+		Status:      domain.MessageStatusScheduled,
+		SendAt:      &sendAt,
+	})
+	assert.NotZero(t, created.ID)
+
+	stored, err := repos.Messages.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, created.ConversationID, stored.ConversationID)
+	assert.Equal(t, "+12016661234", stored.From)
+	assert.Equal(t, "+18045551234", stored.To)
+	assert.Equal(t, domain.MessageTypeMMS, stored.Type)
+	assert.Equal(t, "Hello", stored.Body)
+	assert.Equal(t, []string{"https://example.com/image.png"}, stored.Attachments)
+	assert.Equal(t, domain.MessageStatusScheduled, stored.Status)
+	require.NotNil(t, stored.SendAt)
+	assert.True(t, sendAt.Equal(*stored.SendAt))
+	assert.True(t, created.Timestamp.Equal(stored.Timestamp))
+	assert.Nil(t, stored.MessagingProviderID)
+
+	// The initial status is the first event
+	events, err := repos.Messages.GetEvents(ctx, created.ID)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, domain.MessageStatusScheduled, events[0].Status)
+
+	_, err = repos.Messages.GetByID(ctx, created.ID+1000)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func testMessageDirection(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	received := createMessage(t, repos, domain.Message{
+		From:      "+18045551234",
+		To:        "+12016661234",
+		Direction: domain.MessageDirectionInbound,
+		Status:    domain.MessageStatusDelivered,
+		Body:      "Question",
+	})
+	sent := createMessage(t, repos, domain.Message{
+		From:      "+12016661234",
+		To:        "+18045551234",
+		Direction: domain.MessageDirectionOutbound,
+		Body:      "Answer",
+	})
+
+	stored, err := repos.Messages.GetByID(ctx, received.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.MessageDirectionInbound, stored.Direction)
+
+	stored, err = repos.Messages.GetByID(ctx, sent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.MessageDirectionOutbound, stored.Direction)
+}
+
+func testMessageDisplayNames(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	named := createMessage(t, repos, domain.Message{
+		From:     "jane@example.com",
+		To:       "support@usehatchapp.com",
+		FromName: strPtr("Jane Doe"),
+		Type:     domain.MessageTypeEmail,
+		Body:     "Hello",
+	})
+	stored, err := repos.Messages.GetByID(ctx, named.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "jane@example.com", stored.From)
+	require.NotNil(t, stored.FromName)
+	assert.Equal(t, "Jane Doe", *stored.FromName)
+	assert.Nil(t, stored.ToName)
+}
+
+func testMessageProviderIDUniqueness(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	created := createMessage(t, repos, domain.Message{
+		From:                "+18045551234",
+		To:                  "+12016661234",
+		Body:                "Inbound",
+		Status:              domain.MessageStatusDelivered,
+		MessagingProviderID: strPtr("provider-1"),
+	})
+
+	found, err := repos.Messages.GetByProviderMessageID(ctx, "provider-1")
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, found.ID)
+
+	_, err = repos.Messages.GetByProviderMessageID(ctx, "provider-2")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	duplicate := &domain.Message{
+		ConversationID:      created.ConversationID,
+		From:                "+18045551234",
+		To:                  "+12016661234",
+		Type:                domain.MessageTypeSMS,
+		Direction:           domain.MessageDirectionInbound,
+		Body:                "Inbound again",
+		Status:              domain.MessageStatusDelivered,
+		MessagingProviderID: strPtr("provider-1"),
+		Timestamp:           now(),
+	}
+	assert.ErrorIs(t, repos.Messages.Create(ctx, duplicate), domain.ErrConflict)
+
+	// Messages without a provider ID do not conflict, nor does assigning an unused ID
+	first := createMessage(t, repos, domain.Message{From: "+12016661234", To: "+18045551234", Body: "One"})
+	second := createMessage(t, repos, domain.Message{From: "+12016661234", To: "+18045551234", Body: "Two"})
+
+	first.MessagingProviderID = strPtr("provider-2")
+	require.NoError(t, repos.Messages.Update(ctx, first))
+
+	second.MessagingProviderID = strPtr("provider-2")
+	assert.ErrorIs(t, repos.Messages.Update(ctx, second), domain.ErrConflict)
+}
+
+func testMessageGetByConversationID(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	base := now()
+	later := createMessage(t, repos, domain.Message{From: "+12016661234", To: "+18045551234", Body: "Later", CreatedAt: base, UpdatedAt: base})
+	earlier := createMessage(t, repos, domain.Message{From: "+12016661234", To: "+18045551234", Body: "Earlier", CreatedAt: base.Add(-time.Minute), UpdatedAt: base})
+	createMessage(t, repos, domain.Message{From: "+12016661234", To: "+19999999999", Body: "Elsewhere"})
+
+	messages, err := repos.Messages.GetByConversationID(ctx, later.ConversationID)
+	require.NoError(t, err)
+	assert.Equal(t, []int{earlier.ID, later.ID}, messageIDs(messages))
+
+	messages, err = repos.Messages.GetByConversationID(ctx, later.ConversationID+1000)
+	require.NoError(t, err)
+	assert.Empty(t, messages)
+}
+
+func testMessageListByConversationIDs(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	base := now()
+	sms := createMessage(t, repos, domain.Message{From: "+18045551234", To: "+12016661234", Body: "Text", Timestamp: base})
+	email := createMessage(t, repos, domain.Message{From: "contact@gmail.com", To: "user@usehatchapp.com", Type: domain.MessageTypeEmail, Body: "Email", Timestamp: base.Add(-time.Minute)})
+	reply := createMessage(t, repos, domain.Message{From: "+18045551234", To: "+12016661234", Body: "Reply", Timestamp: base.Add(time.Minute)})
+	createMessage(t, repos, domain.Message{From: "+13125550000", To: "+12016661234", Body: "Elsewhere"})
+
+	// Messages of all conversations are interleaved by timestamp
+	conversations := []int{sms.ConversationID, email.ConversationID}
+	messages, total, err := repos.Messages.ListByConversationIDs(ctx, conversations, 50, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []int{email.ID, sms.ID, reply.ID}, messageIDs(messages))
+	assert.Equal(t, 3, total)
+
+	// Pages follow the same order, and the total counts every page
+	messages, total, err = repos.Messages.ListByConversationIDs(ctx, conversations, 2, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []int{sms.ID, reply.ID}, messageIDs(messages))
+	assert.Equal(t, 3, total)
+
+	messages, total, err = repos.Messages.ListByConversationIDs(ctx, nil, 50, 0)
+	require.NoError(t, err)
+	assert.Empty(t, messages)
+	assert.Zero(t, total)
+}
+
+func testMessageUpdateRecordsEvents(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	message := createMessage(t, repos, domain.Message{From: "+12016661234", To: "+18045551234", Body: "Hello"})
+
+	sentAt := now()
+	message.Status = domain.MessageStatusSent
+	message.MessagingProviderID = strPtr("provider-1")
+	message.Provider = strPtr("twilio")
+	segments := 2
+	message.SegmentCount = &segments
+	message.SentAt = &sentAt
+	message.Body = "Ignored"
+	require.NoError(t, repos.Messages.Update(ctx, message))
+
+	// Saving again without a status change adds no event
+	require.NoError(t, repos.Messages.Update(ctx, message))
+
+	message.Status = domain.MessageStatusFailed
+	message.ErrorCode = strPtr("30003")
+	message.ErrorMessage = strPtr("Unreachable")
+	require.NoError(t, repos.Messages.Update(ctx, message))
+
+	stored, err := repos.Messages.GetByID(ctx, message.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.MessageStatusFailed, stored.Status)
+	assert.Equal(t, "provider-1", *stored.MessagingProviderID)
+	assert.Equal(t, "twilio", *stored.Provider)
+	assert.Equal(t, 2, *stored.SegmentCount)
+	assert.True(t, sentAt.Equal(*stored.SentAt))
+	assert.Equal(t, "30003", *stored.ErrorCode)
+	// Only delivery state is updated
+	assert.Equal(t, "Hello", stored.Body)
+
+	events, err := repos.Messages.GetEvents(ctx, message.ID)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Equal(t, domain.MessageStatusPending, events[0].Status)
+	assert.Equal(t, domain.MessageStatusSent, events[1].Status)
+	assert.Equal(t, domain.MessageStatusFailed, events[2].Status)
+	assert.Equal(t, "30003", *events[2].ErrorCode)
+}
+
+func testMessageUpdateIfStatus(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	message := createMessage(t, repos, domain.Message{From: "+12016661234", To: "+18045551234", Body: "Hello"})
+
+	message.Status = domain.MessageStatusDelivered
+	updated, err := repos.Messages.UpdateIfStatus(ctx, message, domain.MessageStatusSent)
+	require.NoError(t, err)
+	assert.False(t, updated)
+
+	stored, err := repos.Messages.GetByID(ctx, message.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.MessageStatusPending, stored.Status)
+
+	updated, err = repos.Messages.UpdateIfStatus(ctx, message, domain.MessageStatusPending)
+	require.NoError(t, err)
+	assert.True(t, updated)
+
+	stored, err = repos.Messages.GetByID(ctx, message.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.MessageStatusDelivered, stored.Status)
+
+	// Updating a missing message changes nothing
+	missing := *message
+	missing.ID = message.ID + 1000
+	updated, err = repos.Messages.UpdateIfStatus(ctx, &missing, domain.MessageStatusDelivered)
+	require.NoError(t, err)
+	assert.False(t, updated)
+	assert.NoError(t, repos.Messages.Update(ctx, &missing))
+}
+
+func testMessageAttempts(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	message := createMessage(t, repos, domain.Message{From: "+12016661234", To: "+18045551234", Body: "Hello"})
+	other := createMessage(t, repos, domain.Message{From: "+12016661234", To: "+18045551234", Body: "Other"})
+
+	for i, succeeded := range []bool{false, true} {
+		attempt := &domain.MessageAttempt{
+			MessageID:  message.ID,
+			Succeeded:  succeeded,
+			Provider:   strPtr("twilio"),
+			DurationMs: 12,
+			StartedAt:  now(),
+		}
+		if !succeeded {
+			attempt.ErrorClass = strPtr("transient")
+			attempt.ErrorCode = strPtr("503")
+		}
+		require.NoError(t, repos.Messages.RecordAttempt(ctx, attempt))
+		assert.NotZero(t, attempt.ID)
+		assert.Equal(t, i+1, attempt.AttemptNumber)
+	}
+
+	// Numbering is per message
+	otherAttempt := &domain.MessageAttempt{MessageID: other.ID, Succeeded: true, StartedAt: now()}
+	require.NoError(t, repos.Messages.RecordAttempt(ctx, otherAttempt))
+	assert.Equal(t, 1, otherAttempt.AttemptNumber)
+
+	attempts, err := repos.Messages.GetAttempts(ctx, message.ID)
+	require.NoError(t, err)
+	require.Len(t, attempts, 2)
+	assert.Equal(t, 1, attempts[0].AttemptNumber)
+	assert.False(t, attempts[0].Succeeded)
+	assert.Equal(t, "503", *attempts[0].ErrorCode)
+	assert.Equal(t, int64(12), attempts[0].DurationMs)
+	assert.True(t, attempts[1].Succeeded)
+}
+
+func testMessageListFailed(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	fail := func(body, provider, errorCode string) *domain.Message {
+		message := createMessage(t, repos, domain.Message{From: "+12016661234", To: "+18045551234", Body: body})
+		message.Status = domain.MessageStatusFailed
+		message.Provider = strPtr(provider)
+		message.ErrorCode = strPtr(errorCode)
+		require.NoError(t, repos.Messages.Update(ctx, message))
+		return message
+	}
+
+	first := fail("First", "twilio", "30003")
+	second := fail("Second", "twilio", "30005")
+	third := fail("Third", "sendgrid", "30003")
+	createMessage(t, repos, domain.Message{From: "+12016661234", To: "+18045551234", Body: "Pending"})
+
+	// Only failed messages have a failure time
+	stored, err := repos.Messages.GetByID(ctx, first.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, stored.FailedAt)
+	recovered := fail("Recovered", "twilio", "30003")
+	recovered.Status = domain.MessageStatusPending
+	require.NoError(t, repos.Messages.Update(ctx, recovered))
+	stored, err = repos.Messages.GetByID(ctx, recovered.ID)
+	require.NoError(t, err)
+	assert.Nil(t, stored.FailedAt)
+
+	tests := []struct {
+		name     string
+		query    domain.DeadLetterQuery
+		expected []int
+		total    int
+	}{
+		{"most recently failed first", domain.DeadLetterQuery{Limit: 10}, []int{third.ID, second.ID, first.ID}, 3},
+		{"by provider", domain.DeadLetterQuery{Provider: "twilio", Limit: 10}, []int{second.ID, first.ID}, 2},
+		{"by error code", domain.DeadLetterQuery{ErrorCode: "30003", Limit: 10}, []int{third.ID, first.ID}, 2},
+		{"failed after", domain.DeadLetterQuery{From: time.Now().Add(time.Hour), Limit: 10}, []int{}, 0},
+		{"failed before", domain.DeadLetterQuery{To: time.Now().Add(-time.Hour), Limit: 10}, []int{}, 0},
+		{"limit and offset", domain.DeadLetterQuery{Limit: 1, Offset: 1}, []int{second.ID}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages, total, err := repos.Messages.ListFailed(ctx, &tt.query)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, messageIDs(messages))
+			assert.Equal(t, tt.total, total)
+		})
+	}
+}
+
+func testMessageReschedule(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	sendAt := now().Add(time.Hour)
+	scheduled := createMessage(t, repos, domain.Message{From: "+12016661234", To: "+18045551234", Body: "Later", Status: domain.MessageStatusScheduled, SendAt: &sendAt})
+	pending := createMessage(t, repos, domain.Message{From: "+12016661234", To: "+18045551234", Body: "Now"})
+
+	newSendAt := sendAt.Add(time.Hour)
+	rescheduled, err := repos.Messages.Reschedule(ctx, scheduled.ID, newSendAt)
+	require.NoError(t, err)
+	assert.True(t, rescheduled)
+
+	stored, err := repos.Messages.GetByID(ctx, scheduled.ID)
+	require.NoError(t, err)
+	assert.True(t, newSendAt.Equal(*stored.SendAt))
+
+	rescheduled, err = repos.Messages.Reschedule(ctx, pending.ID, newSendAt)
+	require.NoError(t, err)
+	assert.False(t, rescheduled)
+}
+
+func testMessageMoveConversation(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	first := createMessage(t, repos, domain.Message{From: "+12016661234", To: "+18045551234", Body: "First"})
+	second := createMessage(t, repos, domain.Message{From: "+12016661234", To: "+18045551234", Body: "Second"})
+	target, err := repos.Conversations.Create(ctx, "+18045551234", "+12016661234")
+	require.NoError(t, err)
+
+	first.Status = domain.MessageStatusFailed
+	require.NoError(t, repos.Messages.Update(ctx, first))
+	failed, err := repos.Messages.GetByID(ctx, first.ID)
+	require.NoError(t, err)
+	require.NotNil(t, failed.FailedAt)
+
+	moved, err := repos.Messages.MoveConversation(ctx, first.ConversationID, target.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, moved)
+
+	// Moving a failed message does not change when it failed
+	stored, err := repos.Messages.GetByID(ctx, first.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.FailedAt)
+	assert.True(t, failed.FailedAt.Equal(*stored.FailedAt))
+	listed, _, err := repos.Messages.ListFailed(ctx, &domain.DeadLetterQuery{To: failed.FailedAt.Add(time.Millisecond), Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []int{first.ID}, messageIDs(listed))
+
+	messages, err := repos.Messages.GetByConversationID(ctx, target.ID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int{first.ID, second.ID}, messageIDs(messages))
+
+	messages, err = repos.Messages.GetByConversationID(ctx, first.ConversationID)
+	require.NoError(t, err)
+	assert.Empty(t, messages)
+}
+
+func testContactCreateAndGet(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	created := &domain.Contact{
+		Name: strPtr("Jane Doe"),
+		Identities: []domain.ContactIdentity{
+			{Type: domain.ContactIdentityTypePhone, Address: "+18045551234"},
+			{Type: domain.ContactIdentityTypeEmail, Address: "jane@gmail.com"},
+		},
+	}
+	require.NoError(t, repos.Contacts.Create(ctx, created))
+	assert.NotZero(t, created.ID)
+	for _, identity := range created.Identities {
+		assert.NotZero(t, identity.ID)
+		assert.Equal(t, created.ID, identity.ContactID)
+	}
+
+	stored, err := repos.Contacts.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Jane Doe", *stored.Name)
+	assert.Equal(t, []string{"+18045551234", "jane@gmail.com"}, stored.Addresses())
+	assert.Equal(t, domain.ContactIdentityTypeEmail, stored.Identities[1].Type)
+
+	found, err := repos.Contacts.GetByAddress(ctx, "jane@gmail.com")
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, found.ID)
+
+	// An address belongs to one contact only
+	err = repos.Contacts.Create(ctx, &domain.Contact{Identities: []domain.ContactIdentity{
+		{Type: domain.ContactIdentityTypePhone, Address: "+13125550000"},
+		{Type: domain.ContactIdentityTypePhone, Address: "+18045551234"},
+	}})
+	assert.ErrorIs(t, err, domain.ErrConflict)
+	_, err = repos.Contacts.GetByAddress(ctx, "+13125550000")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	// Contacts can be created without identities
+	empty := &domain.Contact{}
+	require.NoError(t, repos.Contacts.Create(ctx, empty))
+	stored, err = repos.Contacts.GetByID(ctx, empty.ID)
+	require.NoError(t, err)
+	assert.Nil(t, stored.Name)
+	assert.Empty(t, stored.Identities)
+
+	_, err = repos.Contacts.GetByID(ctx, created.ID+1000)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func testContactMoveIdentities(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	from := &domain.Contact{Identities: []domain.ContactIdentity{
+		{Type: domain.ContactIdentityTypePhone, Address: "+18045551234"},
+		{Type: domain.ContactIdentityTypeEmail, Address: "jane@gmail.com"},
+		{Type: domain.ContactIdentityTypeEmail, Address: "jane@example.com"},
+	}}
+	require.NoError(t, repos.Contacts.Create(ctx, from))
+	to := &domain.Contact{Identities: []domain.ContactIdentity{
+		{Type: domain.ContactIdentityTypePhone, Address: "+13125550000"},
+	}}
+	require.NoError(t, repos.Contacts.Create(ctx, to))
+
+	// Addresses the source contact does not have are ignored
+	moved, err := repos.Contacts.MoveIdentities(ctx, from.ID, to.ID, []string{"jane@gmail.com", "+18045551234", "+13125550000", "other@gmail.com"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, moved)
+
+	stored, err := repos.Contacts.GetByID(ctx, from.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"jane@example.com"}, stored.Addresses())
+
+	stored, err = repos.Contacts.GetByID(ctx, to.ID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"+13125550000", "+18045551234", "jane@gmail.com"}, stored.Addresses())
+
+	found, err := repos.Contacts.GetByAddress(ctx, "+18045551234")
+	require.NoError(t, err)
+	assert.Equal(t, to.ID, found.ID)
+}
+
+func testContactDelete(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	created := &domain.Contact{Identities: []domain.ContactIdentity{
+		{Type: domain.ContactIdentityTypePhone, Address: "+18045551234"},
+	}}
+	require.NoError(t, repos.Contacts.Create(ctx, created))
+
+	require.NoError(t, repos.Contacts.Delete(ctx, created.ID))
+	_, err := repos.Contacts.GetByID(ctx, created.ID)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	// The identities of a deleted contact are free again
+	_, err = repos.Contacts.GetByAddress(ctx, "+18045551234")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	require.NoError(t, repos.Contacts.Create(ctx, &domain.Contact{Identities: []domain.ContactIdentity{
+		{Type: domain.ContactIdentityTypePhone, Address: "+18045551234"},
+	}}))
+
+	assert.ErrorIs(t, repos.Contacts.Delete(ctx, created.ID), domain.ErrNotFound)
+}
@@ -0,0 +1,193 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"messaging-service/internal/domain"
+)
+
+type contactRepository struct {
+	db *sql.DB
+}
+
+// NewContactRepository creates a new contact repository
+func NewContactRepository(db *sql.DB) domain.ContactRepository {
+	return &contactRepository{db: db}
+}
+
+func (r *contactRepository) Create(ctx context.Context, contact *domain.Contact) error {
+	contactQuery := `
+		INSERT INTO contacts (name, created_at, updated_at)
+		VALUES (?1, ?2, ?2)
+		RETURNING id, created_at, updated_at
+	`
+
+	identityQuery := `
+		INSERT INTO contact_identities (contact_id, type, address, created_at)
+		VALUES (?, ?, ?, ?)
+		RETURNING id, created_at
+	`
+
+	now := timestamp(time.Now())
+	return inTransaction(ctx, r.db, func(tx *sql.Tx) error {
+		if err := tx.QueryRowContext(ctx, contactQuery, contact.Name, now).Scan(&contact.ID, &contact.CreatedAt, &contact.UpdatedAt); err != nil {
+			return fmt.Errorf("failed to create contact: %w", err)
+		}
+
+		for i := range contact.Identities {
+			identity := &contact.Identities[i]
+			identity.ContactID = contact.ID
+			err := tx.QueryRowContext(ctx, identityQuery, contact.ID, identity.Type, identity.Address, now).Scan(&identity.ID, &identity.CreatedAt)
+			if err != nil {
+				if isUniqueViolation(err) {
+					return fmt.Errorf("contact identity %s already exists: %w", identity.Address, domain.ErrConflict)
+				}
+				return fmt.Errorf("failed to create contact identity: %w", err)
+			}
+		}
+		if contact.Identities == nil {
+			contact.Identities = []domain.ContactIdentity{}
+		}
+
+		return nil
+	})
+}
+
+func (r *contactRepository) GetByID(ctx context.Context, id int) (*domain.Contact, error) {
+	query := `
+		SELECT id, name, created_at, updated_at
+		FROM contacts
+		WHERE id = ?
+	`
+
+	var contact domain.Contact
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(&contact.ID, &contact.Name, &contact.CreatedAt, &contact.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("contact %d: %w", id, domain.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get contact by ID: %w", err)
+	}
+
+	identities, err := r.getIdentities(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	contact.Identities = identities
+
+	return &contact, nil
+}
+
+func (r *contactRepository) GetByAddress(ctx context.Context, address string) (*domain.Contact, error) {
+	var contactID int
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT contact_id FROM contact_identities WHERE address = ?`, address).Scan(&contactID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("contact with identity %s: %w", address, domain.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get contact by address: %w", err)
+	}
+
+	return r.GetByID(ctx, contactID)
+}
+
+func (r *contactRepository) MoveIdentities(ctx context.Context, fromContactID, toContactID int, addresses []string) (int, error) {
+	if len(addresses) == 0 {
+		return 0, nil
+	}
+
+	moveQuery := `
+		UPDATE contact_identities
+		SET contact_id = ?
+		WHERE contact_id = ? AND address IN (` + placeholders(len(addresses)) + `)
+	`
+
+	touchQuery := `
+		UPDATE contacts
+		SET updated_at = ?
+		WHERE id IN (?, ?)
+	`
+
+	args := []interface{}{toContactID, fromContactID}
+	for _, address := range addresses {
+		args = append(args, address)
+	}
+
+	moved := 0
+	err := inTransaction(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, moveQuery, args...)
+		if err != nil {
+			return fmt.Errorf("failed to move contact identities: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return nil
+		}
+		moved = int(rowsAffected)
+
+		if _, err := tx.ExecContext(ctx, touchQuery, timestamp(time.Now()), fromContactID, toContactID); err != nil {
+			return fmt.Errorf("failed to update contacts: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return moved, nil
+}
+
+func (r *contactRepository) Delete(ctx context.Context, id int) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM contacts WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete contact: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("contact %d: %w", id, domain.ErrNotFound)
+	}
+
+	return nil
+}
+
+// getIdentities returns the identities of a contact in the order they were added
+func (r *contactRepository) getIdentities(ctx context.Context, contactID int) ([]domain.ContactIdentity, error) {
+	query := `
+		SELECT id, contact_id, type, address, created_at
+		FROM contact_identities
+		WHERE contact_id = ?
+		ORDER BY id ASC
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, contactID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact identities: %w", err)
+	}
+	defer rows.Close()
+
+	identities := []domain.ContactIdentity{}
+	for rows.Next() {
+		var identity domain.ContactIdentity
+		if err := rows.Scan(&identity.ID, &identity.ContactID, &identity.Type, &identity.Address, &identity.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan contact identity: %w", err)
+		}
+		identities = append(identities, identity)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating contact identities: %w", err)
+	}
+
+	return identities, nil
+}
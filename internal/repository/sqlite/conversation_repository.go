@@ -0,0 +1,313 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"messaging-service/internal/domain"
+)
+
+type conversationRepository struct {
+	db *sql.DB
+}
+
+// NewConversationRepository creates a new conversation repository
+func NewConversationRepository(db *sql.DB) domain.ConversationRepository {
+	return &conversationRepository{db: db}
+}
+
+func (r *conversationRepository) Create(ctx context.Context, customerContact, businessContact string) (*domain.Conversation, error) {
+	query := `
+		INSERT INTO conversations (customer_contact, business_contact, created_at, updated_at)
+		VALUES (?1, ?2, ?3, ?3)
+		RETURNING ` + conversationColumns
+
+	conv, err := scanConversation(conn(ctx, r.db).QueryRowContext(ctx, query, customerContact, businessContact, timestamp(time.Now())))
+
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("conversation between %s and %s already exists: %w", customerContact, businessContact, domain.ErrConflict)
+		}
+		return nil, fmt.Errorf("failed to create conversation: %w", err)
+	}
+
+	return conv, nil
+}
+
+func (r *conversationRepository) GetByID(ctx context.Context, id int) (*domain.Conversation, error) {
+	query := `
+		SELECT ` + conversationColumns + `
+		FROM conversations
+		WHERE id = ?
+	`
+
+	conv, err := scanConversation(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("conversation %d: %w", id, domain.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get conversation by ID: %w", err)
+	}
+
+	return conv, nil
+}
+
+func (r *conversationRepository) GetByContacts(ctx context.Context, customerContact, businessContact string) (*domain.Conversation, error) {
+	query := `
+		SELECT ` + conversationColumns + `
+		FROM conversations
+		WHERE customer_contact = ? AND business_contact = ?
+	`
+
+	conv, err := scanConversation(conn(ctx, r.db).QueryRowContext(ctx, query, customerContact, businessContact))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("conversation between %s and %s: %w", customerContact, businessContact, domain.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get conversation by contacts: %w", err)
+	}
+
+	return conv, nil
+}
+
+func (r *conversationRepository) GetOrCreate(ctx context.Context, customerContact, businessContact string) (*domain.Conversation, error) {
+	query := `
+		INSERT INTO conversations (customer_contact, business_contact, created_at, updated_at)
+		VALUES (?1, ?2, ?3, ?3)
+		ON CONFLICT (customer_contact, business_contact) DO NOTHING
+		RETURNING ` + conversationColumns
+
+	conv, err := scanConversation(conn(ctx, r.db).QueryRowContext(ctx, query, customerContact, businessContact, timestamp(time.Now())))
+	if err == nil {
+		return conv, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to create conversation: %w", err)
+	}
+
+	// The conversation already exists
+	return r.GetByContacts(ctx, customerContact, businessContact)
+}
+
+func (r *conversationRepository) RecordMessage(ctx context.Context, conversationID int, at time.Time, preview, direction string) error {
+	// Messages can be recorded out of order, so only a newer message replaces the preview, but
+	// any message counts as activity and bumps updated_at
+	query := `
+		UPDATE conversations
+		SET last_message_at = CASE WHEN last_message_at IS NULL OR last_message_at <= ?2 THEN ?2 ELSE last_message_at END,
+			last_message_preview = CASE WHEN last_message_at IS NULL OR last_message_at <= ?2 THEN ?3 ELSE last_message_preview END,
+			last_message_direction = CASE WHEN last_message_at IS NULL OR last_message_at <= ?2 THEN ?4 ELSE last_message_direction END,
+			updated_at = ?5
+		WHERE id = ?1
+	`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, conversationID, timestamp(at), preview, direction, timestamp(time.Now()))
+	if err != nil {
+		return fmt.Errorf("failed to record conversation message: %w", err)
+	}
+
+	return nil
+}
+
+func (r *conversationRepository) SwapContacts(ctx context.Context, id int) (*domain.Conversation, error) {
+	// The right-hand sides see the values from before the update
+	query := `
+		UPDATE conversations
+		SET customer_contact = business_contact, business_contact = customer_contact, updated_at = ?
+		WHERE id = ?
+		RETURNING ` + conversationColumns
+
+	conv, err := scanConversation(conn(ctx, r.db).QueryRowContext(ctx, query, timestamp(time.Now()), id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("conversation %d: %w", id, domain.ErrNotFound)
+		}
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("conversation with the swapped contacts of %d already exists: %w", id, domain.ErrConflict)
+		}
+		return nil, fmt.Errorf("failed to swap conversation contacts: %w", err)
+	}
+
+	return conv, nil
+}
+
+func (r *conversationRepository) Delete(ctx context.Context, id int) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM conversations WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete conversation: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("conversation %d: %w", id, domain.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *conversationRepository) List(ctx context.Context, query *domain.ConversationQuery) ([]domain.Conversation, int, error) {
+	var conditions []string
+	var args []interface{}
+
+	// Add filters
+	if !query.From.IsZero() {
+		conditions = append(conditions, "updated_at >= ?")
+		args = append(args, timestamp(query.From))
+	}
+
+	if !query.To.IsZero() {
+		conditions = append(conditions, "updated_at <= ?")
+		args = append(args, timestamp(query.To))
+	}
+
+	// LIKE ignores case for ASCII text, matching ILIKE for contacts
+	if query.Search != "" {
+		conditions = append(conditions, "(customer_contact LIKE ? OR business_contact LIKE ?)")
+		args = append(args, "%"+query.Search+"%", "%"+query.Search+"%")
+	}
+
+	if query.BusinessEmail != "" {
+		conditions = append(conditions, "business_contact LIKE ?")
+		args = append(args, "%"+query.BusinessEmail+"%")
+	}
+
+	if query.BusinessPhone != "" {
+		conditions = append(conditions, "business_contact LIKE ?")
+		args = append(args, "%"+query.BusinessPhone+"%")
+	}
+
+	if query.Direction != "" {
+		conditions = append(conditions, "last_message_direction = ?")
+		args = append(args, query.Direction)
+	}
+
+	// The customer wrote last and has not been answered yet
+	if query.AwaitingReply {
+		conditions = append(conditions, "last_message_direction = ?")
+		args = append(args, domain.MessageDirectionInbound)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// Get total count for pagination
+	var total int
+	if err := conn(ctx, r.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM conversations"+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count conversations: %w", err)
+	}
+
+	// Add sorting and pagination
+	sortOrder := "DESC"
+	if query.SortOrder == "asc" {
+		sortOrder = "ASC"
+	}
+
+	sortBy := "updated_at"
+	if query.SortBy != "" {
+		// Validate sort field to prevent SQL injection
+		validSortFields := map[string]bool{
+			"id": true, "created_at": true, "updated_at": true,
+			"customer_contact": true, "business_contact": true, "last_message_at": true,
+		}
+		if validSortFields[query.SortBy] {
+			sortBy = query.SortBy
+		}
+	}
+
+	// Conversations without messages sort last in either direction
+	listQuery := "SELECT " + conversationColumns + " FROM conversations" + where +
+		fmt.Sprintf(" ORDER BY %s %s NULLS LAST LIMIT ? OFFSET ?", sortBy, sortOrder)
+	args = append(args, query.Limit, query.Offset)
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, listQuery, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list conversations: %w", err)
+	}
+	defer rows.Close()
+
+	var conversations []domain.Conversation
+	for rows.Next() {
+		conv, err := scanConversation(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan conversation: %w", err)
+		}
+		conversations = append(conversations, *conv)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating conversations: %w", err)
+	}
+
+	return conversations, total, nil
+}
+
+func (r *conversationRepository) ListByCustomerContacts(ctx context.Context, customerContacts []string, businessContact string) ([]domain.Conversation, error) {
+	conversations := []domain.Conversation{}
+	if len(customerContacts) == 0 {
+		return conversations, nil
+	}
+
+	where := " WHERE customer_contact IN (" + placeholders(len(customerContacts)) + ")"
+	args := make([]interface{}, 0, len(customerContacts)+1)
+	for _, customerContact := range customerContacts {
+		args = append(args, customerContact)
+	}
+	if businessContact != "" {
+		where += " AND business_contact = ?"
+		args = append(args, businessContact)
+	}
+
+	query := "SELECT " + conversationColumns + " FROM conversations" + where + " ORDER BY id ASC"
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conversations by customer contacts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		conv, err := scanConversation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan conversation: %w", err)
+		}
+		conversations = append(conversations, *conv)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating conversations: %w", err)
+	}
+
+	return conversations, nil
+}
+
+// conversationColumns lists the conversation columns in the order scanConversation expects them
+const conversationColumns = `id, customer_contact, business_contact, last_message_at, last_message_preview, last_message_direction, created_at, updated_at`
+
+// scanConversation scans a row selected with conversationColumns into a conversation
+func scanConversation(row rowScanner) (*domain.Conversation, error) {
+	var conv domain.Conversation
+	err := row.Scan(
+		&conv.ID,
+		&conv.CustomerContact,
+		&conv.BusinessContact,
+		&conv.LastMessageAt,
+		&conv.LastMessagePreview,
+		&conv.LastMessageDirection,
+		&conv.CreatedAt,
+		&conv.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &conv, nil
+}
@@ -0,0 +1,55 @@
+// Package sqlite provides SQLite implementations of the repositories for single-node
+// deployments and local development. They follow the semantics of the PostgreSQL
+// repositories; timestamps are set by the application because SQLite has no triggers
+// maintaining updated_at and CURRENT_TIMESTAMP only has second precision.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// querier is implemented by both *sql.DB and *sql.Tx so statements can run inside or outside a transaction
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// isUniqueViolation reports whether err was caused by a unique constraint
+func isUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+}
+
+// placeholders returns n comma-separated parameter placeholders for an IN list
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// timestampFormat stores times with a fixed width in UTC, so that comparing the stored text
+// orders them chronologically
+const timestampFormat = "2006-01-02 15:04:05.000000000-07:00"
+
+// timestamp formats t for storage
+func timestamp(t time.Time) string {
+	return t.UTC().Format(timestampFormat)
+}
+
+// nullableTimestamp formats t for storage, or returns nil when t is nil
+func nullableTimestamp(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return timestamp(*t)
+}
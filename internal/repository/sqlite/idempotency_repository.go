@@ -0,0 +1,134 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"messaging-service/internal/domain"
+)
+
+type idempotencyRepository struct {
+	db *sql.DB
+}
+
+// NewIdempotencyRepository creates a new idempotency key repository
+func NewIdempotencyRepository(db *sql.DB) domain.IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+func (r *idempotencyRepository) Reserve(ctx context.Context, key, fingerprint string, lockedUntil, expiresAt time.Time) (*domain.IdempotencyRecord, bool, error) {
+	// Expired keys, and keys whose request was abandoned before completing, are taken over
+	// as if they had never been used
+	query := `
+		INSERT INTO idempotency_keys (key, fingerprint, created_at, locked_until, expires_at)
+		VALUES (?1, ?2, ?3, ?4, ?5)
+		ON CONFLICT (key) DO UPDATE
+		SET fingerprint = excluded.fingerprint, status_code = NULL, response_body = NULL,
+			created_at = excluded.created_at, locked_until = excluded.locked_until, expires_at = excluded.expires_at
+		WHERE idempotency_keys.expires_at <= ?3
+			OR (idempotency_keys.status_code IS NULL AND idempotency_keys.locked_until <= ?3)
+		RETURNING key, fingerprint, status_code, response_body, locked_until, created_at, expires_at
+	`
+
+	record, err := scanIdempotencyRecord(conn(ctx, r.db).QueryRowContext(ctx, query, key, fingerprint, timestamp(time.Now()), timestamp(lockedUntil), timestamp(expiresAt)))
+	if err == nil {
+		return record, true, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	// The key is held by an unexpired record
+	query = `
+		SELECT key, fingerprint, status_code, response_body, locked_until, created_at, expires_at
+		FROM idempotency_keys
+		WHERE key = ?
+	`
+
+	record, err = scanIdempotencyRecord(conn(ctx, r.db).QueryRowContext(ctx, query, key))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	return record, false, nil
+}
+
+func (r *idempotencyRepository) Complete(ctx context.Context, key string, lockedUntil time.Time, statusCode int, responseBody []byte) error {
+	// A takeover moves locked_until forward, so it identifies the request holding the key
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = ?, response_body = ?
+		WHERE key = ? AND locked_until = ? AND status_code IS NULL
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, statusCode, responseBody, key, timestamp(lockedUntil))
+	if err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("idempotency key %s is held by another request: %w", key, domain.ErrConflict)
+	}
+
+	return nil
+}
+
+func (r *idempotencyRepository) Release(ctx context.Context, key string, lockedUntil time.Time) error {
+	query := `
+		DELETE FROM idempotency_keys
+		WHERE key = ? AND locked_until = ? AND status_code IS NULL
+	`
+
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, key, timestamp(lockedUntil)); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+func (r *idempotencyRepository) DeleteExpired(ctx context.Context, limit int) (int, error) {
+	query := `
+		DELETE FROM idempotency_keys
+		WHERE key IN (
+			SELECT key FROM idempotency_keys
+			WHERE expires_at <= ?
+			LIMIT ?
+		)
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, timestamp(time.Now()), limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+// scanIdempotencyRecord scans an idempotency key row
+func scanIdempotencyRecord(row rowScanner) (*domain.IdempotencyRecord, error) {
+	var record domain.IdempotencyRecord
+	err := row.Scan(
+		&record.Key,
+		&record.Fingerprint,
+		&record.StatusCode,
+		&record.ResponseBody,
+		&record.LockedUntil,
+		&record.CreatedAt,
+		&record.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
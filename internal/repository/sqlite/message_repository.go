@@ -0,0 +1,502 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"messaging-service/internal/domain"
+)
+
+type messageRepository struct {
+	db *sql.DB
+}
+
+// NewMessageRepository creates a new message repository
+func NewMessageRepository(db *sql.DB) domain.MessageRepository {
+	return &messageRepository{db: db}
+}
+
+func (r *messageRepository) Create(ctx context.Context, message *domain.Message) error {
+	return inTransaction(ctx, r.db, func(tx *sql.Tx) error {
+		return insertMessage(ctx, tx, message)
+	})
+}
+
+// insertMessage inserts a message and its initial status event using the given querier and sets its generated ID
+func insertMessage(ctx context.Context, q querier, message *domain.Message) error {
+	query := `
+		INSERT INTO messages (conversation_id, from_address, to_address, from_name, to_name, message_type, direction, body, attachments, provider_message_id, provider, segment_count, sent_at, send_at, status, timestamp, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`
+
+	// Serialize attachments to JSON
+	attachmentsJSON, err := json.Marshal(message.Attachments)
+	if err != nil {
+		return fmt.Errorf("failed to marshal attachments: %w", err)
+	}
+
+	err = q.QueryRowContext(ctx, query,
+		message.ConversationID,
+		message.From,
+		message.To,
+		message.FromName,
+		message.ToName,
+		message.Type,
+		message.Direction,
+		message.Body,
+		string(attachmentsJSON),
+		message.MessagingProviderID,
+		message.Provider,
+		message.SegmentCount,
+		nullableTimestamp(message.SentAt),
+		nullableTimestamp(message.SendAt),
+		message.Status,
+		timestamp(message.Timestamp),
+		timestamp(message.CreatedAt),
+		timestamp(message.UpdatedAt),
+	).Scan(&message.ID)
+
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("message with provider ID %s already exists: %w", *message.MessagingProviderID, domain.ErrConflict)
+		}
+		return fmt.Errorf("failed to create message: %w", err)
+	}
+
+	return insertEvent(ctx, q, message.ID, message.Status, message.ErrorCode, message.ErrorMessage)
+}
+
+// insertEvent records a status of a message in its history using the given querier
+func insertEvent(ctx context.Context, q querier, messageID int, status string, errorCode, errorMessage *string) error {
+	query := `
+		INSERT INTO message_events (message_id, status, error_code, error_message, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	if _, err := q.ExecContext(ctx, query, messageID, status, errorCode, errorMessage, timestamp(time.Now())); err != nil {
+		return fmt.Errorf("failed to record message event: %w", err)
+	}
+
+	return nil
+}
+
+func (r *messageRepository) GetByID(ctx context.Context, id int) (*domain.Message, error) {
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages
+		WHERE id = ?
+	`
+
+	message, err := scanMessage(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("message %d: %w", id, domain.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get message by ID: %w", err)
+	}
+
+	return message, nil
+}
+
+func (r *messageRepository) GetByProviderMessageID(ctx context.Context, providerMessageID string) (*domain.Message, error) {
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages
+		WHERE provider_message_id = ?
+	`
+
+	message, err := scanMessage(conn(ctx, r.db).QueryRowContext(ctx, query, providerMessageID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("message with provider ID %s: %w", providerMessageID, domain.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get message by provider ID: %w", err)
+	}
+
+	return message, nil
+}
+
+func (r *messageRepository) GetByConversationID(ctx context.Context, conversationID int) ([]domain.Message, error) {
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages
+		WHERE conversation_id = ?
+		ORDER BY created_at ASC, id ASC
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get messages by conversation ID: %w", err)
+	}
+	defer rows.Close()
+
+	return scanMessages(rows)
+}
+
+func (r *messageRepository) ListByConversationIDs(ctx context.Context, conversationIDs []int, limit, offset int) ([]domain.Message, int, error) {
+	if len(conversationIDs) == 0 {
+		return []domain.Message{}, 0, nil
+	}
+
+	where := " WHERE conversation_id IN (" + placeholders(len(conversationIDs)) + ")"
+	args := make([]interface{}, 0, len(conversationIDs)+2)
+	for _, id := range conversationIDs {
+		args = append(args, id)
+	}
+
+	// Get total count for pagination
+	var total int
+	if err := conn(ctx, r.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM messages"+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count messages by conversation IDs: %w", err)
+	}
+
+	listQuery := "SELECT " + messageColumns + " FROM messages" + where + " ORDER BY timestamp ASC, id ASC LIMIT ? OFFSET ?"
+	args = append(args, limit, offset)
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, listQuery, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get messages by conversation IDs: %w", err)
+	}
+	defer rows.Close()
+
+	messages, err := scanMessages(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return messages, total, nil
+}
+
+// messageColumns lists the message columns in the order scanMessage expects them
+const messageColumns = `id, conversation_id, from_address, to_address, from_name, to_name, message_type, direction, body, attachments, provider_message_id, provider, segment_count, sent_at, send_at, failed_at, status, error_code, error_message, timestamp, created_at, updated_at`
+
+// scanMessage scans a row selected with messageColumns into a message
+func scanMessage(row rowScanner) (*domain.Message, error) {
+	var message domain.Message
+	var attachmentsJSON []byte
+
+	err := row.Scan(
+		&message.ID,
+		&message.ConversationID,
+		&message.From,
+		&message.To,
+		&message.FromName,
+		&message.ToName,
+		&message.Type,
+		&message.Direction,
+		&message.Body,
+		&attachmentsJSON,
+		&message.MessagingProviderID,
+		&message.Provider,
+		&message.SegmentCount,
+		&message.SentAt,
+		&message.SendAt,
+		&message.FailedAt,
+		&message.Status,
+		&message.ErrorCode,
+		&message.ErrorMessage,
+		&message.Timestamp,
+		&message.CreatedAt,
+		&message.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Deserialize attachments from JSON
+	if err := json.Unmarshal(attachmentsJSON, &message.Attachments); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal attachments: %w", err)
+	}
+
+	return &message, nil
+}
+
+// scanMessages scans all rows selected with messageColumns
+func scanMessages(rows *sql.Rows) ([]domain.Message, error) {
+	var messages []domain.Message
+	for rows.Next() {
+		message, err := scanMessage(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+		messages = append(messages, *message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+func (r *messageRepository) Update(ctx context.Context, message *domain.Message) error {
+	if _, err := r.update(ctx, message, nil); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *messageRepository) UpdateIfStatus(ctx context.Context, message *domain.Message, expectedStatus string) (bool, error) {
+	return r.update(ctx, message, &expectedStatus)
+}
+
+// update writes the message's status fields, optionally only while the stored status equals expectedStatus.
+// A status event is recorded in the same transaction whenever the status changes.
+func (r *messageRepository) update(ctx context.Context, message *domain.Message, expectedStatus *string) (bool, error) {
+	query := `
+		UPDATE messages
+		SET status = ?1, error_code = ?2, error_message = ?3,
+			provider_message_id = ?4, provider = ?5, segment_count = ?6, sent_at = ?7,
+			failed_at = CASE WHEN ?10 THEN COALESCE(failed_at, ?8) END,
+			updated_at = ?8
+		WHERE id = ?9
+	`
+
+	updated := false
+	err := inTransaction(ctx, r.db, func(tx *sql.Tx) error {
+		var previousStatus string
+		err := tx.QueryRowContext(ctx, `SELECT status FROM messages WHERE id = ?`, message.ID).Scan(&previousStatus)
+		if err == sql.ErrNoRows || (err == nil && expectedStatus != nil && previousStatus != *expectedStatus) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get message status: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, query,
+			message.Status,
+			message.ErrorCode,
+			message.ErrorMessage,
+			message.MessagingProviderID,
+			message.Provider,
+			message.SegmentCount,
+			nullableTimestamp(message.SentAt),
+			timestamp(time.Now()),
+			message.ID,
+			message.Status == domain.MessageStatusFailed,
+		)
+		if err != nil {
+			if isUniqueViolation(err) {
+				return fmt.Errorf("message with provider ID %s already exists: %w", *message.MessagingProviderID, domain.ErrConflict)
+			}
+			return fmt.Errorf("failed to update message: %w", err)
+		}
+		updated = true
+
+		if previousStatus == message.Status {
+			return nil
+		}
+		return insertEvent(ctx, tx, message.ID, message.Status, message.ErrorCode, message.ErrorMessage)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return updated, nil
+}
+
+func (r *messageRepository) GetEvents(ctx context.Context, messageID int) ([]domain.MessageEvent, error) {
+	query := `
+		SELECT id, message_id, status, error_code, error_message, created_at
+		FROM message_events
+		WHERE message_id = ?
+		ORDER BY created_at ASC, id ASC
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []domain.MessageEvent
+	for rows.Next() {
+		var event domain.MessageEvent
+		err := rows.Scan(
+			&event.ID,
+			&event.MessageID,
+			&event.Status,
+			&event.ErrorCode,
+			&event.ErrorMessage,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan message event: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating message events: %w", err)
+	}
+
+	return events, nil
+}
+
+func (r *messageRepository) RecordAttempt(ctx context.Context, attempt *domain.MessageAttempt) error {
+	query := `
+		INSERT INTO message_attempts (message_id, attempt_number, succeeded, provider, error_class,
+			error_code, error_message, duration_ms, retry_delay_ms, started_at)
+		SELECT ?1, COALESCE(MAX(attempt_number), 0) + 1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9
+		FROM message_attempts
+		WHERE message_id = ?1
+		RETURNING id, attempt_number
+	`
+
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		attempt.MessageID,
+		attempt.Succeeded,
+		attempt.Provider,
+		attempt.ErrorClass,
+		attempt.ErrorCode,
+		attempt.ErrorMessage,
+		attempt.DurationMs,
+		attempt.RetryDelayMs,
+		timestamp(attempt.StartedAt),
+	).Scan(&attempt.ID, &attempt.AttemptNumber)
+
+	if err != nil {
+		return fmt.Errorf("failed to record message attempt: %w", err)
+	}
+
+	return nil
+}
+
+func (r *messageRepository) GetAttempts(ctx context.Context, messageID int) ([]domain.MessageAttempt, error) {
+	query := `
+		SELECT id, message_id, attempt_number, succeeded, provider, error_class,
+			error_code, error_message, duration_ms, retry_delay_ms, started_at
+		FROM message_attempts
+		WHERE message_id = ?
+		ORDER BY attempt_number ASC
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message attempts: %w", err)
+	}
+	defer rows.Close()
+
+	var attempts []domain.MessageAttempt
+	for rows.Next() {
+		var attempt domain.MessageAttempt
+		err := rows.Scan(
+			&attempt.ID,
+			&attempt.MessageID,
+			&attempt.AttemptNumber,
+			&attempt.Succeeded,
+			&attempt.Provider,
+			&attempt.ErrorClass,
+			&attempt.ErrorCode,
+			&attempt.ErrorMessage,
+			&attempt.DurationMs,
+			&attempt.RetryDelayMs,
+			&attempt.StartedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan message attempt: %w", err)
+		}
+		attempts = append(attempts, attempt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating message attempts: %w", err)
+	}
+
+	return attempts, nil
+}
+
+func (r *messageRepository) ListFailed(ctx context.Context, query *domain.DeadLetterQuery) ([]domain.Message, int, error) {
+	conditions := []string{"status = ?"}
+	args := []interface{}{domain.MessageStatusFailed}
+
+	// Add filters
+	if query.Provider != "" {
+		conditions = append(conditions, "provider = ?")
+		args = append(args, query.Provider)
+	}
+
+	if query.ErrorCode != "" {
+		conditions = append(conditions, "error_code = ?")
+		args = append(args, query.ErrorCode)
+	}
+
+	if !query.From.IsZero() {
+		conditions = append(conditions, "failed_at >= ?")
+		args = append(args, timestamp(query.From))
+	}
+
+	if !query.To.IsZero() {
+		conditions = append(conditions, "failed_at < ?")
+		args = append(args, timestamp(query.To))
+	}
+
+	where := " WHERE " + strings.Join(conditions, " AND ")
+
+	// Get total count for pagination
+	var total int
+	if err := conn(ctx, r.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM messages"+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count failed messages: %w", err)
+	}
+
+	listQuery := "SELECT " + messageColumns + " FROM messages" + where + " ORDER BY failed_at DESC, id DESC LIMIT ? OFFSET ?"
+	args = append(args, query.Limit, query.Offset)
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, listQuery, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list failed messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages, err := scanMessages(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return messages, total, nil
+}
+
+func (r *messageRepository) Reschedule(ctx context.Context, id int, sendAt time.Time) (bool, error) {
+	query := `
+		UPDATE messages
+		SET send_at = ?, updated_at = ?
+		WHERE id = ? AND status = ?
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, timestamp(sendAt), timestamp(time.Now()), id, domain.MessageStatusScheduled)
+	if err != nil {
+		return false, fmt.Errorf("failed to reschedule message: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+func (r *messageRepository) MoveConversation(ctx context.Context, fromConversationID, toConversationID int) (int, error) {
+	query := `
+		UPDATE messages
+		SET conversation_id = ?, updated_at = ?
+		WHERE conversation_id = ?
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, toConversationID, timestamp(time.Now()), fromConversationID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to move messages: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
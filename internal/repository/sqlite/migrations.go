@@ -0,0 +1,15 @@
+package sqlite
+
+import (
+	"embed"
+
+	"messaging-service/internal/migrate"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migrations returns the SQLite schema migrations embedded in the binary
+func Migrations() ([]migrate.Migration, error) {
+	return migrate.Load(migrationFiles, "migrations")
+}
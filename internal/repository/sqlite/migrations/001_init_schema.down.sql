@@ -0,0 +1,8 @@
+-- Revert the initial schema
+
+DROP TABLE IF EXISTS idempotency_keys;
+DROP TABLE IF EXISTS outbox;
+DROP TABLE IF EXISTS message_attempts;
+DROP TABLE IF EXISTS message_events;
+DROP TABLE IF EXISTS messages;
+DROP TABLE IF EXISTS conversations;
@@ -0,0 +1,99 @@
+-- Database schema for messaging service on SQLite, equivalent to the PostgreSQL schema.
+-- Timestamps are stored as fixed-width UTC text written by the application.
+
+-- Create conversations table
+CREATE TABLE IF NOT EXISTS conversations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    customer_contact TEXT NOT NULL,
+    business_contact TEXT NOT NULL,
+    last_message_at TIMESTAMP,
+    last_message_preview TEXT,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    UNIQUE(customer_contact, business_contact)
+);
+
+-- Create messages table
+CREATE TABLE IF NOT EXISTS messages (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    conversation_id INTEGER NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    from_address TEXT NOT NULL,
+    to_address TEXT NOT NULL,
+    message_type TEXT NOT NULL CHECK (message_type IN ('sms', 'mms', 'email')),
+    body TEXT NOT NULL,
+    attachments TEXT NOT NULL DEFAULT '[]',
+    provider_message_id TEXT,
+    provider TEXT,
+    segment_count INTEGER,
+    sent_at TIMESTAMP,
+    send_at TIMESTAMP,
+    status TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('scheduled', 'pending', 'sent', 'delivered', 'failed', 'bounced', 'cancelled')),
+    error_code TEXT,
+    error_message TEXT,
+    timestamp TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_conversations_last_message_at ON conversations(last_message_at);
+CREATE INDEX IF NOT EXISTS idx_messages_conversation_id ON messages(conversation_id);
+CREATE INDEX IF NOT EXISTS idx_messages_timestamp ON messages(timestamp);
+CREATE INDEX IF NOT EXISTS idx_messages_type ON messages(message_type);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_messages_provider_message_id_unique ON messages(provider_message_id);
+CREATE INDEX IF NOT EXISTS idx_messages_failed ON messages(updated_at DESC) WHERE status = 'failed';
+CREATE INDEX IF NOT EXISTS idx_messages_scheduled ON messages(send_at) WHERE status = 'scheduled';
+
+-- Create message events table
+CREATE TABLE IF NOT EXISTS message_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    message_id INTEGER NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    status TEXT NOT NULL,
+    error_code TEXT,
+    error_message TEXT,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_events_message_id ON message_events(message_id);
+
+-- Create message attempts table
+CREATE TABLE IF NOT EXISTS message_attempts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    message_id INTEGER NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    attempt_number INTEGER NOT NULL,
+    succeeded BOOLEAN NOT NULL,
+    provider TEXT,
+    error_class TEXT,
+    error_code TEXT,
+    error_message TEXT,
+    duration_ms INTEGER NOT NULL DEFAULT 0,
+    retry_delay_ms INTEGER NOT NULL DEFAULT 0,
+    started_at TIMESTAMP NOT NULL,
+    UNIQUE (message_id, attempt_number)
+);
+
+-- Create outbox table
+CREATE TABLE IF NOT EXISTS outbox (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    message_id INTEGER NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    available_at TIMESTAMP NOT NULL,
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_available_at ON outbox(available_at);
+CREATE INDEX IF NOT EXISTS idx_outbox_message_id ON outbox(message_id);
+
+-- Create idempotency keys table; status_code is NULL while the first request is in flight
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key TEXT PRIMARY KEY,
+    fingerprint TEXT NOT NULL,
+    status_code INTEGER,
+    response_body BLOB,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
@@ -0,0 +1,4 @@
+-- Revert message display names
+
+ALTER TABLE messages DROP COLUMN to_name;
+ALTER TABLE messages DROP COLUMN from_name;
@@ -0,0 +1,4 @@
+-- Display names given with message addresses, kept apart from the canonical addresses
+
+ALTER TABLE messages ADD COLUMN from_name TEXT;
+ALTER TABLE messages ADD COLUMN to_name TEXT;
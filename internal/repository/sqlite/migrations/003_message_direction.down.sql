@@ -0,0 +1,5 @@
+-- Revert message direction
+
+DROP INDEX IF EXISTS idx_conversations_last_message_direction;
+ALTER TABLE conversations DROP COLUMN last_message_direction;
+ALTER TABLE messages DROP COLUMN direction;
@@ -0,0 +1,24 @@
+-- Record whether a message was received from or sent to the customer
+
+ALTER TABLE messages ADD COLUMN direction TEXT NOT NULL DEFAULT 'inbound'
+    CHECK (direction IN ('inbound', 'outbound'));
+
+-- Inbound messages are stored as delivered and never pass through another status or a provider
+UPDATE messages
+SET direction = 'outbound'
+WHERE status <> 'delivered'
+    OR EXISTS (SELECT 1 FROM message_attempts a WHERE a.message_id = messages.id)
+    OR EXISTS (SELECT 1 FROM message_events e WHERE e.message_id = messages.id AND e.status <> 'delivered');
+
+-- Track the direction of the most recent message of each conversation
+ALTER TABLE conversations ADD COLUMN last_message_direction TEXT;
+
+UPDATE conversations
+SET last_message_direction = (
+    SELECT m.direction FROM messages m
+    WHERE m.conversation_id = conversations.id
+    ORDER BY m.timestamp DESC, m.id DESC
+    LIMIT 1
+);
+
+CREATE INDEX IF NOT EXISTS idx_conversations_last_message_direction ON conversations(last_message_direction);
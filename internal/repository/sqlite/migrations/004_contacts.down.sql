@@ -0,0 +1,5 @@
+-- Revert contacts
+
+DROP INDEX IF EXISTS idx_conversations_customer_contact;
+DROP TABLE IF EXISTS contact_identities;
+DROP TABLE IF EXISTS contacts;
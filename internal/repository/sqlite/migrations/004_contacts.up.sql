@@ -0,0 +1,22 @@
+-- Contacts group the phone numbers and email addresses a customer uses across channels
+
+CREATE TABLE IF NOT EXISTS contacts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- An address identifies at most one contact
+CREATE TABLE IF NOT EXISTS contact_identities (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    contact_id INTEGER NOT NULL REFERENCES contacts(id) ON DELETE CASCADE,
+    type TEXT NOT NULL CHECK (type IN ('phone', 'email')),
+    address TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_contact_identities_contact_id ON contact_identities(contact_id);
+
+-- Timelines look conversations up by customer contact alone
+CREATE INDEX IF NOT EXISTS idx_conversations_customer_contact ON conversations(customer_contact);
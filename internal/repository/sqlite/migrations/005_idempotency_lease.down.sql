@@ -0,0 +1,3 @@
+-- Revert idempotency key leases
+
+ALTER TABLE idempotency_keys DROP COLUMN locked_until;
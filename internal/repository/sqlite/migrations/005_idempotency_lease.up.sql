@@ -0,0 +1,6 @@
+-- Let a request abandoned while holding an idempotency key release it after a short lease
+
+ALTER TABLE idempotency_keys ADD COLUMN locked_until TIMESTAMP;
+
+-- Keys left in progress by earlier versions are abandoned
+UPDATE idempotency_keys SET locked_until = created_at WHERE locked_until IS NULL;
@@ -0,0 +1,224 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"messaging-service/internal/domain"
+)
+
+type outboxRepository struct {
+	db *sql.DB
+}
+
+// NewOutboxRepository creates a new outbox repository
+func NewOutboxRepository(db *sql.DB) domain.OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+// outboxColumns lists the outbox columns in the order scanOutboxEntry expects them
+const outboxColumns = `id, message_id, attempts, available_at, last_error, created_at, updated_at`
+
+// scanOutboxEntry scans a row selected with outboxColumns into an outbox entry
+func scanOutboxEntry(row rowScanner) (*domain.OutboxEntry, error) {
+	var entry domain.OutboxEntry
+	err := row.Scan(
+		&entry.ID,
+		&entry.MessageID,
+		&entry.Attempts,
+		&entry.AvailableAt,
+		&entry.LastError,
+		&entry.CreatedAt,
+		&entry.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// insertOutboxEntry creates an outbox entry for a message using the given querier
+func insertOutboxEntry(ctx context.Context, q querier, messageID int, availableAt time.Time) (*domain.OutboxEntry, error) {
+	query := `
+		INSERT INTO outbox (message_id, available_at, created_at, updated_at)
+		VALUES (?1, ?2, ?3, ?3)
+		RETURNING ` + outboxColumns
+
+	entry, err := scanOutboxEntry(q.QueryRowContext(ctx, query, messageID, timestamp(availableAt), timestamp(time.Now())))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create outbox entry: %w", err)
+	}
+	return entry, nil
+}
+
+func (r *outboxRepository) Enqueue(ctx context.Context, message *domain.Message, availableAt time.Time) (*domain.OutboxEntry, error) {
+	var entry *domain.OutboxEntry
+	err := inTransaction(ctx, r.db, func(tx *sql.Tx) error {
+		if err := insertMessage(ctx, tx, message); err != nil {
+			return err
+		}
+
+		var err error
+		entry, err = insertOutboxEntry(ctx, tx, message.ID, availableAt)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (r *outboxRepository) ClaimDue(ctx context.Context, limit int, leaseDuration time.Duration) ([]domain.OutboxEntry, error) {
+	// SQLite runs one write at a time, so concurrent dispatchers cannot claim the same entry
+	query := `
+		UPDATE outbox
+		SET attempts = attempts + 1, available_at = ?2, updated_at = ?3
+		WHERE id IN (
+			SELECT id FROM outbox
+			WHERE available_at <= ?3
+			ORDER BY available_at ASC
+			LIMIT ?1
+		)
+		RETURNING ` + outboxColumns
+
+	now := time.Now()
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, limit, timestamp(now.Add(leaseDuration)), timestamp(now))
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []domain.OutboxEntry
+	for rows.Next() {
+		entry, err := scanOutboxEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan outbox entry: %w", err)
+		}
+		entries = append(entries, *entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating outbox entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (r *outboxRepository) Release(ctx context.Context, id int, availableAt time.Time, lastError string) error {
+	query := `
+		UPDATE outbox
+		SET available_at = ?, last_error = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, timestamp(availableAt), lastError, timestamp(time.Now()), id); err != nil {
+		return fmt.Errorf("failed to release outbox entry: %w", err)
+	}
+
+	return nil
+}
+
+func (r *outboxRepository) Delete(ctx context.Context, id int) error {
+	if _, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM outbox WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete outbox entry: %w", err)
+	}
+
+	return nil
+}
+
+func (r *outboxRepository) Requeue(ctx context.Context, message *domain.Message, availableAt time.Time) (*domain.OutboxEntry, error) {
+	resetQuery := `
+		UPDATE messages
+		SET status = ?, error_code = NULL, error_message = NULL, provider = NULL, failed_at = NULL, updated_at = ?
+		WHERE id = ? AND status = ?
+		RETURNING updated_at
+	`
+
+	var entry *domain.OutboxEntry
+	var updatedAt time.Time
+	err := inTransaction(ctx, r.db, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, resetQuery, domain.MessageStatusPending, timestamp(time.Now()), message.ID, domain.MessageStatusFailed).Scan(&updatedAt)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("message %d is not failed: %w", message.ID, domain.ErrConflict)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to reset message: %w", err)
+		}
+
+		if err := insertEvent(ctx, tx, message.ID, domain.MessageStatusPending, nil, nil); err != nil {
+			return err
+		}
+
+		entry, err = insertOutboxEntry(ctx, tx, message.ID, availableAt)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	message.Status = domain.MessageStatusPending
+	message.ErrorCode = nil
+	message.ErrorMessage = nil
+	message.Provider = nil
+	message.UpdatedAt = updatedAt
+
+	return entry, nil
+}
+
+func (r *outboxRepository) EnqueueScheduled(ctx context.Context, limit int) (int, error) {
+	// The status condition keeps cancelled messages from being sent
+	query := `
+		UPDATE messages
+		SET status = ?1, updated_at = ?3
+		WHERE id IN (
+			SELECT id FROM messages
+			WHERE status = ?2 AND send_at <= ?3
+			ORDER BY send_at ASC
+			LIMIT ?4
+		)
+		RETURNING id
+	`
+
+	count := 0
+	err := inTransaction(ctx, r.db, func(tx *sql.Tx) error {
+		now := time.Now()
+		rows, err := tx.QueryContext(ctx, query, domain.MessageStatusPending, domain.MessageStatusScheduled, timestamp(now), limit)
+		if err != nil {
+			return fmt.Errorf("failed to move scheduled messages: %w", err)
+		}
+
+		var ids []int
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan scheduled message: %w", err)
+			}
+			ids = append(ids, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating scheduled messages: %w", err)
+		}
+
+		for _, id := range ids {
+			if err := insertEvent(ctx, tx, id, domain.MessageStatusPending, nil, nil); err != nil {
+				return err
+			}
+			if _, err := insertOutboxEntry(ctx, tx, id, now); err != nil {
+				return err
+			}
+		}
+
+		count = len(ids)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue scheduled messages: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,310 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"messaging-service/internal/domain"
+	"messaging-service/internal/migrate"
+	"messaging-service/internal/repository/repositorytest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// openTestDB opens a migrated database in a temporary file
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := "file:" + filepath.Join(t.TempDir(), "test.db") + "?_foreign_keys=on&_busy_timeout=5000&_txlock=immediate"
+	db, err := sql.Open("sqlite3", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	migrations, err := Migrations()
+	require.NoError(t, err)
+	_, err = migrate.NewMigrator(db, migrate.SQLite, migrations, zap.NewNop()).Up(context.Background())
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestRepositoryConformance(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Repositories {
+		db := openTestDB(t)
+		return repositorytest.Repositories{
+			Conversations: NewConversationRepository(db),
+			Messages:      NewMessageRepository(db),
+			Contacts:      NewContactRepository(db),
+		}
+	})
+}
+
+func TestMigrations_DownAndUp(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+
+	migrations, err := Migrations()
+	require.NoError(t, err)
+	migrator := migrate.NewMigrator(db, migrate.SQLite, migrations, zap.NewNop())
+
+	reverted, err := migrator.Down(ctx, len(migrations))
+	require.NoError(t, err)
+	assert.Equal(t, len(migrations), reverted)
+
+	applied, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, len(migrations), applied)
+
+	applied, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, applied)
+}
+
+// newTestMessage creates a conversation and returns an unsaved outbound message in it
+func newTestMessage(t *testing.T, db *sql.DB, status string) *domain.Message {
+	t.Helper()
+
+	conv, err := NewConversationRepository(db).GetOrCreate(context.Background(), "+12016661234", "+18045551234")
+	require.NoError(t, err)
+
+	now := time.Now()
+	return &domain.Message{
+		ConversationID: conv.ID,
+		From:           "+18045551234",
+		To:             "+12016661234",
+		Type:           domain.MessageTypeSMS,
+		Direction:      domain.MessageDirectionOutbound,
+		Body:           "Hello",
+		Status:         status,
+		Timestamp:      now,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+func TestOutboxRepository_EnqueueClaimAndDelete(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	outbox := NewOutboxRepository(db)
+
+	message := newTestMessage(t, db, domain.MessageStatusPending)
+	entry, err := outbox.Enqueue(ctx, message, time.Now().Add(-time.Second))
+	require.NoError(t, err)
+	assert.NotZero(t, message.ID)
+	assert.Equal(t, message.ID, entry.MessageID)
+
+	claimed, err := outbox.ClaimDue(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, 1, claimed[0].Attempts)
+	assert.True(t, claimed[0].AvailableAt.After(time.Now()))
+
+	// A claimed entry is leased to its dispatcher
+	claimed, err = outbox.ClaimDue(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	require.NoError(t, outbox.Release(ctx, entry.ID, time.Now().Add(-time.Second), "timeout"))
+	claimed, err = outbox.ClaimDue(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, 2, claimed[0].Attempts)
+	assert.Equal(t, "timeout", *claimed[0].LastError)
+
+	require.NoError(t, outbox.Delete(ctx, entry.ID))
+	require.NoError(t, outbox.Release(ctx, entry.ID, time.Now().Add(-time.Second), ""))
+	claimed, err = outbox.ClaimDue(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+}
+
+func TestOutboxRepository_Requeue(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	outbox := NewOutboxRepository(db)
+	messages := NewMessageRepository(db)
+
+	message := newTestMessage(t, db, domain.MessageStatusFailed)
+	message.ErrorCode = stringPtr("30003")
+	require.NoError(t, messages.Create(ctx, message))
+
+	entry, err := outbox.Requeue(ctx, message, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, message.ID, entry.MessageID)
+	assert.Equal(t, domain.MessageStatusPending, message.Status)
+
+	stored, err := messages.GetByID(ctx, message.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.MessageStatusPending, stored.Status)
+	assert.Nil(t, stored.ErrorCode)
+
+	events, err := messages.GetEvents(ctx, message.ID)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, domain.MessageStatusPending, events[1].Status)
+
+	// Only failed messages can be requeued
+	_, err = outbox.Requeue(ctx, message, time.Now())
+	assert.True(t, errors.Is(err, domain.ErrConflict))
+}
+
+func TestOutboxRepository_EnqueueScheduled(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	outbox := NewOutboxRepository(db)
+	messages := NewMessageRepository(db)
+
+	due := newTestMessage(t, db, domain.MessageStatusScheduled)
+	sendAt := time.Now().Add(-time.Minute)
+	due.SendAt = &sendAt
+	require.NoError(t, messages.Create(ctx, due))
+
+	later := newTestMessage(t, db, domain.MessageStatusScheduled)
+	laterSendAt := time.Now().Add(time.Hour)
+	later.SendAt = &laterSendAt
+	require.NoError(t, messages.Create(ctx, later))
+
+	count, err := outbox.EnqueueScheduled(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	stored, err := messages.GetByID(ctx, due.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.MessageStatusPending, stored.Status)
+
+	claimed, err := outbox.ClaimDue(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, due.ID, claimed[0].MessageID)
+
+	count, err = outbox.EnqueueScheduled(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestTransactor_RollsBackAllRepositories(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	conversations := NewConversationRepository(db)
+
+	err := NewTransactor(db).WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := conversations.Create(ctx, "+12016661234", "+18045551234"); err != nil {
+			return err
+		}
+		return errors.New("abort")
+	})
+	require.Error(t, err)
+
+	_, err = conversations.GetByContacts(ctx, "+12016661234", "+18045551234")
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+}
+
+func TestIdempotencyRepository_ReserveAndComplete(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	repo := NewIdempotencyRepository(db)
+	lockedUntil := time.Now().Add(time.Minute)
+
+	record, reserved, err := repo.Reserve(ctx, "key-1", "fingerprint-1", lockedUntil, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.True(t, reserved)
+	assert.Nil(t, record.StatusCode)
+
+	require.NoError(t, repo.Complete(ctx, "key-1", record.LockedUntil, 202, []byte(`{"id":1}`)))
+
+	record, reserved, err = repo.Reserve(ctx, "key-1", "fingerprint-2", lockedUntil, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.False(t, reserved)
+	assert.Equal(t, "fingerprint-1", record.Fingerprint)
+	assert.Equal(t, 202, *record.StatusCode)
+	assert.Equal(t, []byte(`{"id":1}`), record.ResponseBody)
+
+	// Expired keys can be reserved again
+	_, reserved, err = repo.Reserve(ctx, "key-2", "fingerprint-1", lockedUntil, time.Now().Add(-time.Second))
+	require.NoError(t, err)
+	assert.True(t, reserved)
+
+	record, reserved, err = repo.Reserve(ctx, "key-2", "fingerprint-2", lockedUntil, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.True(t, reserved)
+	assert.Equal(t, "fingerprint-2", record.Fingerprint)
+}
+
+func TestIdempotencyRepository_AbandonedKeys(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	repo := NewIdempotencyRepository(db)
+
+	// A key still locked by its request is not taken over
+	record, _, err := repo.Reserve(ctx, "key-1", "fingerprint-1", time.Now().Add(time.Minute), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	_, reserved, err := repo.Reserve(ctx, "key-1", "fingerprint-1", time.Now().Add(time.Minute), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.False(t, reserved)
+
+	// Released keys can be reserved again right away
+	require.NoError(t, repo.Release(ctx, "key-1", record.LockedUntil))
+	_, reserved, err = repo.Reserve(ctx, "key-1", "fingerprint-1", time.Now().Add(time.Minute), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.True(t, reserved)
+
+	// So can keys whose lock has lapsed
+	abandoned, _, err := repo.Reserve(ctx, "key-2", "fingerprint-1", time.Now().Add(-time.Second), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	retry, reserved, err := repo.Reserve(ctx, "key-2", "fingerprint-1", time.Now().Add(time.Minute), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.True(t, reserved)
+
+	// The abandoned request can neither complete nor release a key taken over by a retry
+	err = repo.Complete(ctx, "key-2", abandoned.LockedUntil, 500, []byte(`{}`))
+	assert.True(t, errors.Is(err, domain.ErrConflict))
+	require.NoError(t, repo.Release(ctx, "key-2", abandoned.LockedUntil))
+	require.NoError(t, repo.Complete(ctx, "key-2", retry.LockedUntil, 200, []byte(`{"id":2}`)))
+	record, reserved, err = repo.Reserve(ctx, "key-2", "fingerprint-1", time.Now().Add(time.Minute), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.False(t, reserved)
+	assert.Equal(t, []byte(`{"id":2}`), record.ResponseBody)
+
+	// Completed keys are kept until they expire, however old their lock
+	record, _, err = repo.Reserve(ctx, "key-3", "fingerprint-1", time.Now().Add(-time.Second), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.NoError(t, repo.Complete(ctx, "key-3", record.LockedUntil, 200, []byte(`{}`)))
+	require.NoError(t, repo.Release(ctx, "key-3", record.LockedUntil))
+	_, reserved, err = repo.Reserve(ctx, "key-3", "fingerprint-1", time.Now().Add(time.Minute), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.False(t, reserved)
+}
+
+func TestIdempotencyRepository_DeleteExpired(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	repo := NewIdempotencyRepository(db)
+
+	for _, key := range []string{"expired-1", "expired-2", "expired-3"} {
+		_, _, err := repo.Reserve(ctx, key, "fingerprint", time.Now().Add(time.Minute), time.Now().Add(-time.Second))
+		require.NoError(t, err)
+	}
+	_, _, err := repo.Reserve(ctx, "live", "fingerprint", time.Now().Add(time.Minute), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	deleted, err := repo.DeleteExpired(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+	deleted, err = repo.DeleteExpired(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	var remaining int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM idempotency_keys`).Scan(&remaining))
+	assert.Equal(t, 1, remaining)
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
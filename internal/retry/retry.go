@@ -0,0 +1,241 @@
+// Package retry implements retry policies with exponential backoff, jitter, error
+// classification and a total retry budget.
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"messaging-service/internal/domain"
+)
+
+// Jitter selects how backoff delays are randomized
+type Jitter string
+
+const (
+	// JitterNone uses the exponential delay as is
+	JitterNone Jitter = "none"
+	// JitterFull picks a random delay between zero and the exponential delay
+	JitterFull Jitter = "full"
+	// JitterDecorrelated picks a random delay between the base delay and the previous delay times the multiplier
+	JitterDecorrelated Jitter = "decorrelated"
+)
+
+// Class is the retry category of an error
+type Class int
+
+const (
+	// ClassPermanent errors are never retried
+	ClassPermanent Class = iota
+	// ClassTransient errors (5xx, timeouts, connection resets) are retried with backoff
+	ClassTransient
+	// ClassRateLimited errors (429) are retried after the provider's Retry-After delay
+	ClassRateLimited
+)
+
+func (c Class) String() string {
+	switch c {
+	case ClassTransient:
+		return "transient"
+	case ClassRateLimited:
+		return "rate_limited"
+	default:
+		return "permanent"
+	}
+}
+
+// ParseClass parses a class name as returned by Class.String
+func ParseClass(name string) (Class, bool) {
+	switch name {
+	case "permanent":
+		return ClassPermanent, true
+	case "transient":
+		return ClassTransient, true
+	case "rate_limited":
+		return ClassRateLimited, true
+	default:
+		return ClassPermanent, false
+	}
+}
+
+// Policy describes when and how often an operation is retried
+type Policy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+	Multiplier float64
+	Jitter     Jitter
+	// Budget bounds the total time spent on all attempts and delays; zero means no limit
+	Budget time.Duration
+	// StatusClasses overrides the class of provider errors by status code
+	StatusClasses map[int]Class
+}
+
+// DefaultPolicy returns the default retry policy
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxRetries: 3,
+		BaseDelay:  time.Second,
+		MaxDelay:   time.Minute,
+		Multiplier: 2.0,
+		Jitter:     JitterFull,
+		Budget:     2 * time.Minute,
+	}
+}
+
+// Attempt describes one call of the retried operation
+type Attempt struct {
+	// Number is the 1-based attempt number
+	Number    int
+	StartedAt time.Time
+	Duration  time.Duration
+	Err       error
+	Class     Class
+	// Delay is the wait before the next attempt, zero when no retry follows
+	Delay time.Duration
+}
+
+// randFloat returns a random number in [0, 1); replaced in tests
+var randFloat = rand.Float64
+
+// Classify determines the retry class of an error
+func (p Policy) Classify(err error) Class {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, domain.ErrCircuitOpen) {
+		return ClassPermanent
+	}
+
+	var providerErr *domain.ProviderError
+	if errors.As(err, &providerErr) {
+		if class, ok := p.StatusClasses[providerErr.Code]; ok {
+			return class
+		}
+		switch providerErr.Code {
+		case http.StatusTooManyRequests:
+			return ClassRateLimited
+		case http.StatusRequestTimeout, http.StatusInternalServerError, http.StatusBadGateway,
+			http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return ClassTransient
+		default:
+			return ClassPermanent
+		}
+	}
+
+	// Network failures that did not reach the provider are worth another try
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ClassTransient
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return ClassTransient
+	}
+
+	return ClassPermanent
+}
+
+// Backoff returns the delay before retry number retry (0-based) given the previous delay
+func (p Policy) Backoff(retry int, previous time.Duration) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	var delay time.Duration
+	switch p.Jitter {
+	case JitterDecorrelated:
+		if previous < p.BaseDelay {
+			previous = p.BaseDelay
+		}
+		upper := float64(previous) * multiplier
+		delay = p.BaseDelay + time.Duration(randFloat()*(upper-float64(p.BaseDelay)))
+	case JitterFull:
+		delay = time.Duration(randFloat() * p.exponential(retry, multiplier))
+	default:
+		delay = time.Duration(p.exponential(retry, multiplier))
+	}
+
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// exponential returns BaseDelay * multiplier^retry as a float to avoid overflow
+func (p Policy) exponential(retry int, multiplier float64) float64 {
+	delay := float64(p.BaseDelay) * math.Pow(multiplier, float64(retry))
+	if p.MaxDelay > 0 {
+		delay = math.Min(delay, float64(p.MaxDelay))
+	}
+	return delay
+}
+
+// Do calls operation until it succeeds, fails permanently, or the retries or budget are
+// exhausted, and returns the last error. onAttempt, if set, is called after every attempt.
+func (p Policy) Do(ctx context.Context, operation func() error, onAttempt func(Attempt)) error {
+	started := time.Now()
+	var delay time.Duration
+
+	for retry := 0; ; retry++ {
+		attemptStart := time.Now()
+		err := operation()
+		attempt := Attempt{
+			Number:    retry + 1,
+			StartedAt: attemptStart,
+			Duration:  time.Since(attemptStart),
+			Err:       err,
+		}
+
+		retrying := false
+		if err != nil && ctx.Err() == nil {
+			attempt.Class = p.Classify(err)
+			if attempt.Class != ClassPermanent && retry < p.MaxRetries {
+				next := p.nextDelay(err, attempt.Class, retry, delay)
+				// Give up early rather than overrun the budget
+				if p.Budget <= 0 || time.Since(started)+next <= p.Budget {
+					retrying = true
+					delay = next
+					attempt.Delay = next
+				}
+			}
+		}
+
+		if onAttempt != nil {
+			onAttempt(attempt)
+		}
+		if !retrying {
+			if err != nil && ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// nextDelay picks the wait before the next attempt, honoring Retry-After for rate limited errors
+func (p Policy) nextDelay(err error, class Class, retry int, previous time.Duration) time.Duration {
+	if class == ClassRateLimited {
+		if retryAfter := domain.GetRetryAfterSeconds(err); retryAfter > 0 {
+			delay := time.Duration(retryAfter) * time.Second
+			if p.MaxDelay > 0 && delay > p.MaxDelay {
+				delay = p.MaxDelay
+			}
+			return delay
+		}
+	}
+	return p.Backoff(retry, previous)
+}
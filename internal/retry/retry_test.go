@@ -0,0 +1,193 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"messaging-service/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixedRand makes jitter deterministic for the duration of a test
+func fixedRand(t *testing.T, value float64) {
+	t.Helper()
+	original := randFloat
+	randFloat = func() float64 { return value }
+	t.Cleanup(func() { randFloat = original })
+}
+
+func testPolicy() Policy {
+	return Policy{
+		MaxRetries: 3,
+		BaseDelay:  time.Millisecond,
+		MaxDelay:   10 * time.Millisecond,
+		Multiplier: 2,
+		Jitter:     JitterNone,
+	}
+}
+
+func TestPolicy_Classify(t *testing.T) {
+	policy := Policy{StatusClasses: map[int]Class{500: ClassPermanent}}
+
+	tests := []struct {
+		name     string
+		err      error
+		expected Class
+	}{
+		{"rate limited", &domain.ProviderError{Code: 429}, ClassRateLimited},
+		{"service unavailable", &domain.ProviderError{Code: 503}, ClassTransient},
+		{"request timeout", &domain.ProviderError{Code: 408}, ClassTransient},
+		{"bad request", &domain.ProviderError{Code: 400}, ClassPermanent},
+		{"unauthorized", &domain.ProviderError{Code: 401}, ClassPermanent},
+		{"status override", &domain.ProviderError{Code: 500}, ClassPermanent},
+		{"wrapped provider error", fmt.Errorf("send: %w", &domain.ProviderError{Code: 502}), ClassTransient},
+		{"connection reset", &net.OpError{Op: "read", Err: syscall.ECONNRESET}, ClassTransient},
+		{"connection refused", fmt.Errorf("dial: %w", syscall.ECONNREFUSED), ClassTransient},
+		{"timeout", context.DeadlineExceeded, ClassTransient},
+		{"cancelled", context.Canceled, ClassPermanent},
+		{"circuit open", domain.ErrCircuitOpen, ClassPermanent},
+		{"unknown error", errors.New("invalid attachment"), ClassPermanent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, policy.Classify(tt.err))
+		})
+	}
+}
+
+func TestPolicy_Backoff_HonorsMultiplier(t *testing.T) {
+	policy := Policy{BaseDelay: 100 * time.Millisecond, MaxDelay: 10 * time.Second, Multiplier: 3, Jitter: JitterNone}
+
+	assert.Equal(t, 100*time.Millisecond, policy.Backoff(0, 0))
+	assert.Equal(t, 300*time.Millisecond, policy.Backoff(1, 0))
+	assert.Equal(t, 900*time.Millisecond, policy.Backoff(2, 0))
+	assert.Equal(t, 10*time.Second, policy.Backoff(10, 0))
+}
+
+func TestPolicy_Backoff_FullJitter(t *testing.T) {
+	fixedRand(t, 0.5)
+	policy := Policy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second, Multiplier: 2, Jitter: JitterFull}
+
+	assert.Equal(t, 50*time.Millisecond, policy.Backoff(0, 0))
+	assert.Equal(t, 200*time.Millisecond, policy.Backoff(2, 0))
+	assert.Equal(t, 500*time.Millisecond, policy.Backoff(10, 0))
+}
+
+func TestPolicy_Backoff_DecorrelatedJitter(t *testing.T) {
+	fixedRand(t, 0.5)
+	policy := Policy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second, Multiplier: 3, Jitter: JitterDecorrelated}
+
+	// Between the base delay and three times the previous delay
+	assert.Equal(t, 200*time.Millisecond, policy.Backoff(0, 0))
+	assert.Equal(t, 350*time.Millisecond, policy.Backoff(1, 200*time.Millisecond))
+	assert.Equal(t, time.Second, policy.Backoff(2, 800*time.Millisecond))
+}
+
+func TestPolicy_Do_RetriesTransientErrors(t *testing.T) {
+	var attempts []Attempt
+	calls := 0
+
+	err := testPolicy().Do(context.Background(), func() error {
+		calls++
+		if calls < 3 {
+			return &domain.ProviderError{Code: 503, Message: "unavailable"}
+		}
+		return nil
+	}, func(attempt Attempt) {
+		attempts = append(attempts, attempt)
+	})
+
+	require.NoError(t, err)
+	require.Len(t, attempts, 3)
+	assert.Equal(t, ClassTransient, attempts[0].Class)
+	assert.Equal(t, time.Millisecond, attempts[0].Delay)
+	assert.Equal(t, 2*time.Millisecond, attempts[1].Delay)
+	assert.Equal(t, 3, attempts[2].Number)
+	assert.NoError(t, attempts[2].Err)
+	assert.Zero(t, attempts[2].Delay)
+}
+
+func TestPolicy_Do_StopsOnPermanentError(t *testing.T) {
+	calls := 0
+	err := testPolicy().Do(context.Background(), func() error {
+		calls++
+		return &domain.ProviderError{Code: 400, Message: "invalid number"}
+	}, nil)
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestPolicy_Do_ReturnsLastErrorAfterMaxRetries(t *testing.T) {
+	var attempts []Attempt
+	err := testPolicy().Do(context.Background(), func() error {
+		return &domain.ProviderError{Code: 500, Message: "boom"}
+	}, func(attempt Attempt) {
+		attempts = append(attempts, attempt)
+	})
+
+	var providerErr *domain.ProviderError
+	require.ErrorAs(t, err, &providerErr)
+	assert.Equal(t, 500, providerErr.Code)
+	require.Len(t, attempts, 4)
+	assert.Zero(t, attempts[3].Delay)
+}
+
+func TestPolicy_Do_HonorsRetryAfter(t *testing.T) {
+	policy := testPolicy()
+	policy.MaxRetries = 1
+	policy.MaxDelay = 5 * time.Millisecond
+
+	var attempts []Attempt
+	_ = policy.Do(context.Background(), func() error {
+		return &domain.ProviderError{Code: 429, Message: "slow down", RetryAfter: 60}
+	}, func(attempt Attempt) {
+		attempts = append(attempts, attempt)
+	})
+
+	// Retry-After is capped at the maximum delay
+	require.Len(t, attempts, 2)
+	assert.Equal(t, ClassRateLimited, attempts[0].Class)
+	assert.Equal(t, 5*time.Millisecond, attempts[0].Delay)
+}
+
+func TestPolicy_Do_StopsWhenBudgetExhausted(t *testing.T) {
+	policy := testPolicy()
+	policy.MaxRetries = 10
+	policy.BaseDelay = 20 * time.Millisecond
+	policy.MaxDelay = 20 * time.Millisecond
+	policy.Budget = 30 * time.Millisecond
+
+	// The second retry would end after the budget, so it is not attempted
+	calls := 0
+	err := policy.Do(context.Background(), func() error {
+		calls++
+		return &domain.ProviderError{Code: 503, Message: "unavailable"}
+	}, nil)
+
+	assert.Error(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestPolicy_Do_StopsWhenContextCancelled(t *testing.T) {
+	policy := testPolicy()
+	policy.BaseDelay = time.Second
+	policy.MaxDelay = time.Second
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := policy.Do(ctx, func() error {
+		return &domain.ProviderError{Code: 503, Message: "unavailable"}
+	}, nil)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -1,9 +1,6 @@
 package router
 
 import (
-	"net/http"
-	"time"
-
 	"messaging-service/internal/handler"
 	"messaging-service/internal/middleware"
 
@@ -27,15 +24,10 @@ func NewRouter() *Router {
 	return router
 }
 
-// SetupRoutes configures all routes with the given handler
-func (r *Router) SetupRoutes(messagingHandler *handler.MessagingHandler, logger *zap.Logger) {
+// SetupRoutes configures all routes with the given handlers
+func (r *Router) SetupRoutes(messagingHandler *handler.MessagingHandler, contactHandler *handler.ContactHandler, healthHandler *handler.HealthHandler, idempotency gin.HandlerFunc, logger *zap.Logger) {
 	// Health check endpoint
-	r.engine.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status":    "ok",
-			"timestamp": time.Now().UTC().Format(time.RFC3339),
-		})
-	})
+	r.engine.GET("/health", healthHandler.Health)
 
 	// Swagger documentation endpoint
 	r.engine.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -51,8 +43,13 @@ func (r *Router) SetupRoutes(messagingHandler *handler.MessagingHandler, logger
 		// Message endpoints
 		messages := api.Group("/messages")
 		{
-			messages.POST("/message", messagingHandler.SendSMS)
-			messages.POST("/email", messagingHandler.SendEmail)
+			messages.POST("/message", idempotency, messagingHandler.SendSMS)
+			messages.POST("/email", idempotency, messagingHandler.SendEmail)
+			messages.GET("/:id", messagingHandler.GetMessage)
+			messages.DELETE("/:id", messagingHandler.CancelMessage)
+			messages.PATCH("/:id", messagingHandler.RescheduleMessage)
+			messages.GET("/:id/events", messagingHandler.GetMessageEvents)
+			messages.GET("/:id/attempts", messagingHandler.GetMessageAttempts)
 		}
 
 		// Webhook endpoints
@@ -60,6 +57,8 @@ func (r *Router) SetupRoutes(messagingHandler *handler.MessagingHandler, logger
 		{
 			webhooks.POST("/message", messagingHandler.HandleInboundSMS)
 			webhooks.POST("/email", messagingHandler.HandleInboundEmail)
+			webhooks.POST("/message/status", messagingHandler.HandleSMSStatus)
+			webhooks.POST("/email/status", messagingHandler.HandleEmailStatus)
 		}
 
 		// Conversation endpoints
@@ -68,6 +67,24 @@ func (r *Router) SetupRoutes(messagingHandler *handler.MessagingHandler, logger
 			conversations.GET("", messagingHandler.GetConversations)
 			conversations.GET("/:id/messages", messagingHandler.GetConversationMessages)
 		}
+
+		// Contact endpoints
+		contacts := api.Group("/contacts")
+		{
+			contacts.POST("", contactHandler.CreateContact)
+			contacts.GET("", contactHandler.FindContact)
+			contacts.GET("/:id", contactHandler.GetContact)
+			contacts.POST("/:id/merge", contactHandler.MergeContacts)
+			contacts.POST("/:id/split", contactHandler.SplitContact)
+			contacts.GET("/:id/timeline", contactHandler.GetContactTimeline)
+		}
+
+		// Admin endpoints
+		admin := api.Group("/admin")
+		{
+			admin.GET("/dead-letters", messagingHandler.ListDeadLetters)
+			admin.POST("/dead-letters/replay", messagingHandler.ReplayDeadLetters)
+		}
 	}
 }
 
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"messaging-service/internal/contact"
+	"messaging-service/internal/domain"
+)
+
+type contactService struct {
+	contactRepo      domain.ContactRepository
+	conversationRepo domain.ConversationRepository
+	messageRepo      domain.MessageRepository
+	transactor       domain.Transactor
+	normalizer       *contact.Normalizer
+}
+
+// NewContactService creates a new contact service. Identities are stored in the canonical
+// form produced by normalizer, the same form conversations are keyed by.
+func NewContactService(
+	contactRepo domain.ContactRepository,
+	conversationRepo domain.ConversationRepository,
+	messageRepo domain.MessageRepository,
+	transactor domain.Transactor,
+	normalizer *contact.Normalizer,
+) domain.ContactService {
+	return &contactService{
+		contactRepo:      contactRepo,
+		conversationRepo: conversationRepo,
+		messageRepo:      messageRepo,
+		transactor:       transactor,
+		normalizer:       normalizer,
+	}
+}
+
+func (s *contactService) CreateContact(ctx context.Context, req *domain.CreateContactRequest) (*domain.Contact, error) {
+	var identities []domain.ContactIdentity
+	for _, phone := range req.Phones {
+		normalized, err := s.normalizer.NormalizePhone(phone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid phone: %w", err)
+		}
+		identities = appendIdentity(identities, domain.ContactIdentityTypePhone, normalized)
+	}
+	for _, email := range req.Emails {
+		parsed, err := s.normalizer.ParseEmail(email)
+		if err != nil {
+			return nil, fmt.Errorf("invalid email: %w", err)
+		}
+		identities = appendIdentity(identities, domain.ContactIdentityTypeEmail, parsed.Canonical)
+	}
+	if len(identities) == 0 {
+		return nil, fmt.Errorf("a contact needs at least one phone or email")
+	}
+
+	created := &domain.Contact{Name: contactName(req.Name), Identities: identities}
+	if err := s.contactRepo.Create(ctx, created); err != nil {
+		return nil, fmt.Errorf("failed to create contact: %w", err)
+	}
+
+	return created, nil
+}
+
+func (s *contactService) GetContact(ctx context.Context, id int) (*domain.Contact, error) {
+	found, err := s.contactRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact: %w", err)
+	}
+	return found, nil
+}
+
+func (s *contactService) FindContact(ctx context.Context, address string) (*domain.Contact, error) {
+	identity, err := s.identityFor(address)
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := s.contactRepo.GetByAddress(ctx, identity.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find contact: %w", err)
+	}
+	return found, nil
+}
+
+func (s *contactService) MergeContacts(ctx context.Context, targetID, sourceID int) (*domain.Contact, error) {
+	if targetID == sourceID {
+		return nil, fmt.Errorf("cannot merge contact %d into itself: %w", targetID, domain.ErrInvalidState)
+	}
+
+	var merged *domain.Contact
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.contactRepo.GetByID(ctx, targetID); err != nil {
+			return err
+		}
+		source, err := s.contactRepo.GetByID(ctx, sourceID)
+		if err != nil {
+			return err
+		}
+
+		if _, err := s.contactRepo.MoveIdentities(ctx, sourceID, targetID, source.Addresses()); err != nil {
+			return err
+		}
+		if err := s.contactRepo.Delete(ctx, sourceID); err != nil {
+			return err
+		}
+
+		merged, err = s.contactRepo.GetByID(ctx, targetID)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge contacts: %w", err)
+	}
+
+	return merged, nil
+}
+
+func (s *contactService) SplitContact(ctx context.Context, id int, req *domain.SplitContactRequest) (*domain.Contact, error) {
+	var addresses []string
+	for _, address := range req.Addresses {
+		identity, err := s.identityFor(address)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(addresses, identity.Address) {
+			addresses = append(addresses, identity.Address)
+		}
+	}
+
+	var split *domain.Contact
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		original, err := s.contactRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		owned := original.Addresses()
+		for _, address := range addresses {
+			if !slices.Contains(owned, address) {
+				return fmt.Errorf("%s is not an identity of contact %d: %w", address, id, domain.ErrInvalidState)
+			}
+		}
+		// A contact without identities could never be found again
+		if len(addresses) == len(owned) {
+			return fmt.Errorf("splitting would leave contact %d without identities: %w", id, domain.ErrInvalidState)
+		}
+
+		split = &domain.Contact{Name: contactName(req.Name)}
+		if err := s.contactRepo.Create(ctx, split); err != nil {
+			return err
+		}
+		if _, err := s.contactRepo.MoveIdentities(ctx, id, split.ID, addresses); err != nil {
+			return err
+		}
+
+		split, err = s.contactRepo.GetByID(ctx, split.ID)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to split contact: %w", err)
+	}
+
+	return split, nil
+}
+
+func (s *contactService) GetTimeline(ctx context.Context, id int, query *domain.ContactTimelineQuery) (*domain.GetContactTimelineResponse, error) {
+	found, err := s.contactRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact: %w", err)
+	}
+
+	// Business contacts are stored in the same canonical form as contact identities
+	var business string
+	if query.Business != "" {
+		identity, err := s.identityFor(query.Business)
+		if err != nil {
+			return nil, err
+		}
+		business = identity.Address
+	}
+
+	// The contact is the customer of every conversation held over one of its identities
+	conversations, err := s.conversationRepo.ListByCustomerContacts(ctx, found.Addresses(), business)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversations of contact %d: %w", id, err)
+	}
+
+	conversationIDs := make([]int, 0, len(conversations))
+	for _, conv := range conversations {
+		conversationIDs = append(conversationIDs, conv.ID)
+	}
+
+	messages, total, err := s.messageRepo.ListByConversationIDs(ctx, conversationIDs, query.Limit, query.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get messages of contact %d: %w", id, err)
+	}
+	if messages == nil {
+		messages = []domain.Message{}
+	}
+
+	return &domain.GetContactTimelineResponse{
+		Contact:  *found,
+		Messages: messages,
+		Total:    total,
+		Page:     (query.Offset / query.Limit) + 1,
+		PerPage:  query.Limit,
+		HasMore:  (query.Offset + query.Limit) < total,
+	}, nil
+}
+
+// identityFor converts an email address or phone number to a canonical identity
+func (s *contactService) identityFor(address string) (domain.ContactIdentity, error) {
+	if strings.Contains(address, "@") {
+		parsed, err := s.normalizer.ParseEmail(address)
+		if err != nil {
+			return domain.ContactIdentity{}, fmt.Errorf("invalid address: %w", err)
+		}
+		return domain.ContactIdentity{Type: domain.ContactIdentityTypeEmail, Address: parsed.Canonical}, nil
+	}
+
+	normalized, err := s.normalizer.NormalizePhone(address)
+	if err != nil {
+		return domain.ContactIdentity{}, fmt.Errorf("invalid address: %w", err)
+	}
+	return domain.ContactIdentity{Type: domain.ContactIdentityTypePhone, Address: normalized}, nil
+}
+
+// appendIdentity adds an identity unless one with the same address was already given
+func appendIdentity(identities []domain.ContactIdentity, identityType, address string) []domain.ContactIdentity {
+	for _, identity := range identities {
+		if identity.Address == address {
+			return identities
+		}
+	}
+	return append(identities, domain.ContactIdentity{Type: identityType, Address: address})
+}
+
+// contactName returns the trimmed name, or nil when no name was given
+func contactName(name *string) *string {
+	if name == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*name)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
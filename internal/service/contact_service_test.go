@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"messaging-service/internal/contact"
+	"messaging-service/internal/domain"
+	"messaging-service/internal/repository/memory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestContactService returns a contact service over empty in-memory repositories
+func newTestContactService() (domain.ContactService, domain.ConversationRepository, domain.MessageRepository) {
+	conversations := memory.NewConversationRepository()
+	messages := memory.NewMessageRepository()
+	contacts := memory.NewContactRepository()
+	service := NewContactService(contacts, conversations, messages, noopTransactor{}, contact.NewNormalizerWithConfig(contact.Config{DefaultRegion: contact.DefaultRegion, CanonicalizeEmail: true}))
+	return service, conversations, messages
+}
+
+func TestContactService_CreateContact_CanonicalizesIdentities(t *testing.T) {
+	service, _, _ := newTestContactService()
+	name := "  Jane Doe "
+
+	created, err := service.CreateContact(context.Background(), &domain.CreateContactRequest{
+		Name:   &name,
+		Phones: []string{"(804) 555-1234", "+1 804 555 1234"},
+		Emails: []string{"Jane <Jane.Doe+shop@gmail.com>"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Jane Doe", *created.Name)
+	assert.Equal(t, []string{"+18045551234", "janedoe@gmail.com"}, created.Addresses())
+	assert.Equal(t, domain.ContactIdentityTypePhone, created.Identities[0].Type)
+	assert.Equal(t, domain.ContactIdentityTypeEmail, created.Identities[1].Type)
+}
+
+func TestContactService_CreateContact_RejectsInvalidIdentities(t *testing.T) {
+	service, _, _ := newTestContactService()
+	ctx := context.Background()
+
+	_, err := service.CreateContact(ctx, &domain.CreateContactRequest{Phones: []string{"not a number"}})
+	assert.True(t, errors.Is(err, contact.ErrInvalidPhoneNumber))
+
+	_, err = service.CreateContact(ctx, &domain.CreateContactRequest{Emails: []string{"jane@localhost"}})
+	assert.True(t, errors.Is(err, contact.ErrInvalidEmailAddress))
+
+	_, err = service.CreateContact(ctx, &domain.CreateContactRequest{})
+	assert.Error(t, err)
+
+	// An address already belonging to a contact cannot be given to another
+	_, err = service.CreateContact(ctx, &domain.CreateContactRequest{Phones: []string{"+18045551234"}})
+	require.NoError(t, err)
+	_, err = service.CreateContact(ctx, &domain.CreateContactRequest{Phones: []string{"804-555-1234"}})
+	assert.True(t, errors.Is(err, domain.ErrConflict))
+}
+
+func TestContactService_FindContact(t *testing.T) {
+	service, _, _ := newTestContactService()
+	ctx := context.Background()
+
+	created, err := service.CreateContact(ctx, &domain.CreateContactRequest{Phones: []string{"+18045551234"}, Emails: []string{"jane@gmail.com"}})
+	require.NoError(t, err)
+
+	found, err := service.FindContact(ctx, "804.555.1234")
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, found.ID)
+
+	found, err = service.FindContact(ctx, "Jane@Gmail.com")
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, found.ID)
+
+	_, err = service.FindContact(ctx, "other@gmail.com")
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+}
+
+func TestContactService_MergeContacts(t *testing.T) {
+	service, _, _ := newTestContactService()
+	ctx := context.Background()
+
+	phone, err := service.CreateContact(ctx, &domain.CreateContactRequest{Phones: []string{"+18045551234"}})
+	require.NoError(t, err)
+	email, err := service.CreateContact(ctx, &domain.CreateContactRequest{Emails: []string{"jane@gmail.com"}})
+	require.NoError(t, err)
+
+	merged, err := service.MergeContacts(ctx, phone.ID, email.ID)
+	require.NoError(t, err)
+	assert.Equal(t, phone.ID, merged.ID)
+	assert.ElementsMatch(t, []string{"+18045551234", "jane@gmail.com"}, merged.Addresses())
+
+	_, err = service.GetContact(ctx, email.ID)
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+
+	_, err = service.MergeContacts(ctx, phone.ID, phone.ID)
+	assert.True(t, errors.Is(err, domain.ErrInvalidState))
+
+	_, err = service.MergeContacts(ctx, phone.ID, email.ID)
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+}
+
+func TestContactService_SplitContact(t *testing.T) {
+	service, _, _ := newTestContactService()
+	ctx := context.Background()
+
+	created, err := service.CreateContact(ctx, &domain.CreateContactRequest{Phones: []string{"+18045551234"}, Emails: []string{"jane@gmail.com"}})
+	require.NoError(t, err)
+
+	name := "Shared inbox"
+	split, err := service.SplitContact(ctx, created.ID, &domain.SplitContactRequest{Name: &name, Addresses: []string{"JANE@gmail.com"}})
+	require.NoError(t, err)
+	assert.NotEqual(t, created.ID, split.ID)
+	assert.Equal(t, "Shared inbox", *split.Name)
+	assert.Equal(t, []string{"jane@gmail.com"}, split.Addresses())
+
+	remaining, err := service.GetContact(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"+18045551234"}, remaining.Addresses())
+
+	// The contact must keep an identity, and can only give away its own
+	_, err = service.SplitContact(ctx, created.ID, &domain.SplitContactRequest{Addresses: []string{"+18045551234"}})
+	assert.True(t, errors.Is(err, domain.ErrInvalidState))
+	_, err = service.SplitContact(ctx, created.ID, &domain.SplitContactRequest{Addresses: []string{"jane@gmail.com"}})
+	assert.True(t, errors.Is(err, domain.ErrInvalidState))
+}
+
+func TestContactService_GetTimeline(t *testing.T) {
+	service, conversations, messages := newTestContactService()
+	ctx := context.Background()
+
+	// createMessage stores a message in the conversation between the customer and the business
+	createMessage := func(customer, business, messageType, direction, body string, at time.Time) {
+		conv, err := conversations.GetOrCreate(ctx, customer, business)
+		require.NoError(t, err)
+		from, to := business, customer
+		if direction == domain.MessageDirectionInbound {
+			from, to = customer, business
+		}
+		require.NoError(t, messages.Create(ctx, &domain.Message{
+			ConversationID: conv.ID,
+			From:           from,
+			To:             to,
+			Type:           messageType,
+			Direction:      direction,
+			Body:           body,
+			Status:         domain.MessageStatusDelivered,
+			Timestamp:      at,
+		}))
+	}
+
+	base := time.Now().UTC()
+	createMessage("+18045551234", "+12016661234", domain.MessageTypeSMS, domain.MessageDirectionInbound, "Text", base)
+	createMessage("jane@gmail.com", "user@usehatchapp.com", domain.MessageTypeEmail, domain.MessageDirectionOutbound, "Email reply", base.Add(2*time.Minute))
+	createMessage("+18045551234", "+12016661234", domain.MessageTypeMMS, domain.MessageDirectionInbound, "Picture", base.Add(time.Minute))
+	createMessage("+13125550000", "+12016661234", domain.MessageTypeSMS, domain.MessageDirectionInbound, "Someone else", base)
+	createMessage("+18045551234", "+12016660000", domain.MessageTypeSMS, domain.MessageDirectionInbound, "Other line", base.Add(3*time.Minute))
+
+	created, err := service.CreateContact(ctx, &domain.CreateContactRequest{Phones: []string{"+18045551234"}, Emails: []string{"jane@gmail.com"}})
+	require.NoError(t, err)
+
+	bodies := func(timeline *domain.GetContactTimelineResponse) []string {
+		var bodies []string
+		for _, message := range timeline.Messages {
+			bodies = append(bodies, message.Body)
+		}
+		return bodies
+	}
+
+	timeline, err := service.GetTimeline(ctx, created.ID, &domain.ContactTimelineQuery{Limit: 50})
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, timeline.Contact.ID)
+	assert.Equal(t, []string{"Text", "Picture", "Email reply", "Other line"}, bodies(timeline))
+	assert.Equal(t, 4, timeline.Total)
+	assert.False(t, timeline.HasMore)
+
+	// Pages are taken from the same order
+	timeline, err = service.GetTimeline(ctx, created.ID, &domain.ContactTimelineQuery{Limit: 2, Offset: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Email reply", "Other line"}, bodies(timeline))
+	assert.Equal(t, 2, timeline.Page)
+	assert.False(t, timeline.HasMore)
+
+	timeline, err = service.GetTimeline(ctx, created.ID, &domain.ContactTimelineQuery{Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Text"}, bodies(timeline))
+	assert.True(t, timeline.HasMore)
+
+	// Only messages with one business contact, given in any form
+	timeline, err = service.GetTimeline(ctx, created.ID, &domain.ContactTimelineQuery{Business: "(201) 666-1234", Limit: 50})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Text", "Picture"}, bodies(timeline))
+	assert.Equal(t, 2, timeline.Total)
+
+	_, err = service.GetTimeline(ctx, created.ID, &domain.ContactTimelineQuery{Business: "not an address", Limit: 50})
+	assert.True(t, errors.Is(err, contact.ErrInvalidPhoneNumber))
+
+	// A contact without conversations has an empty timeline
+	other, err := service.CreateContact(ctx, &domain.CreateContactRequest{Emails: []string{"nobody@example.com"}})
+	require.NoError(t, err)
+	timeline, err = service.GetTimeline(ctx, other.ID, &domain.ContactTimelineQuery{Limit: 50})
+	require.NoError(t, err)
+	assert.NotNil(t, timeline.Messages)
+	assert.Empty(t, timeline.Messages)
+
+	_, err = service.GetTimeline(ctx, other.ID+1000, &domain.ContactTimelineQuery{Limit: 50})
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"messaging-service/internal/contact"
+	"messaging-service/internal/domain"
+
+	"go.uber.org/zap"
+)
+
+// roleBackfillPageSize is the number of conversations read per page
+const roleBackfillPageSize = 500
+
+// RoleBackfillResult summarizes a conversation role backfill
+type RoleBackfillResult struct {
+	Scanned int `json:"scanned"`
+	// Swapped conversations had their contacts exchanged in place
+	Swapped int `json:"swapped"`
+	// Merged conversations were folded into the conversation that already had the correct roles
+	Merged int `json:"merged"`
+	// Unresolved conversations have both or neither contact registered as a business identity
+	Unresolved int `json:"unresolved"`
+}
+
+// ConversationRoleBackfill corrects conversations whose customer and business contacts are
+// stored the wrong way round. Conversations created before roles were derived from message
+// direction ordered their contacts lexicographically, so the business registry decides which
+// side is the business.
+type ConversationRoleBackfill struct {
+	conversationRepo domain.ConversationRepository
+	messageRepo      domain.MessageRepository
+	transactor       domain.Transactor
+	registry         *contact.BusinessRegistry
+	logger           *zap.Logger
+}
+
+// NewConversationRoleBackfill creates a new conversation role backfill
+func NewConversationRoleBackfill(
+	conversationRepo domain.ConversationRepository,
+	messageRepo domain.MessageRepository,
+	transactor domain.Transactor,
+	registry *contact.BusinessRegistry,
+	logger *zap.Logger,
+) *ConversationRoleBackfill {
+	return &ConversationRoleBackfill{
+		conversationRepo: conversationRepo,
+		messageRepo:      messageRepo,
+		transactor:       transactor,
+		registry:         registry,
+		logger:           logger,
+	}
+}
+
+// Run fixes every conversation whose customer contact is a business identity while its
+// business contact is not. With dryRun set, the conversations are only counted.
+func (b *ConversationRoleBackfill) Run(ctx context.Context, dryRun bool) (*RoleBackfillResult, error) {
+	if b.registry.IsEmpty() {
+		return nil, fmt.Errorf("no business identities are registered")
+	}
+
+	// Collect the reversed conversations first, since fixing them changes the pages
+	result := &RoleBackfillResult{}
+	var reversed []domain.Conversation
+	for offset := 0; ; offset += roleBackfillPageSize {
+		conversations, _, err := b.conversationRepo.List(ctx, &domain.ConversationQuery{
+			SortBy:    "id",
+			SortOrder: "asc",
+			Limit:     roleBackfillPageSize,
+			Offset:    offset,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list conversations: %w", err)
+		}
+
+		for _, conv := range conversations {
+			result.Scanned++
+			customerOwned, businessOwned := b.registry.Owns(conv.CustomerContact), b.registry.Owns(conv.BusinessContact)
+			switch {
+			case customerOwned && !businessOwned:
+				reversed = append(reversed, conv)
+			case customerOwned == businessOwned:
+				result.Unresolved++
+			}
+		}
+
+		if len(conversations) < roleBackfillPageSize {
+			break
+		}
+	}
+
+	for i := range reversed {
+		merged, err := b.fixConversation(ctx, &reversed[i], dryRun)
+		if err != nil {
+			return result, fmt.Errorf("failed to fix conversation %d: %w", reversed[i].ID, err)
+		}
+		if merged {
+			result.Merged++
+		} else {
+			result.Swapped++
+		}
+	}
+
+	return result, nil
+}
+
+// fixConversation swaps the contacts of a reversed conversation, or merges it into the
+// conversation that already has them in the correct roles. It reports whether it merged.
+func (b *ConversationRoleBackfill) fixConversation(ctx context.Context, conv *domain.Conversation, dryRun bool) (bool, error) {
+	merged := false
+	err := b.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		target, err := b.conversationRepo.GetByContacts(ctx, conv.BusinessContact, conv.CustomerContact)
+		if errors.Is(err, domain.ErrNotFound) {
+			if dryRun {
+				return nil
+			}
+			_, err := b.conversationRepo.SwapContacts(ctx, conv.ID)
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		merged = true
+		if dryRun {
+			return nil
+		}
+
+		if _, err := b.messageRepo.MoveConversation(ctx, conv.ID, target.ID); err != nil {
+			return err
+		}
+		if conv.LastMessageAt != nil && conv.LastMessagePreview != nil && conv.LastMessageDirection != nil {
+			if err := b.conversationRepo.RecordMessage(ctx, target.ID, *conv.LastMessageAt, *conv.LastMessagePreview, *conv.LastMessageDirection); err != nil {
+				return err
+			}
+		}
+		return b.conversationRepo.Delete(ctx, conv.ID)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	b.logger.Info("Fixed conversation roles",
+		zap.Int("conversation_id", conv.ID),
+		zap.String("customer_contact", conv.BusinessContact),
+		zap.String("business_contact", conv.CustomerContact),
+		zap.Bool("merged", merged),
+		zap.Bool("dry_run", dryRun),
+	)
+
+	return merged, nil
+}
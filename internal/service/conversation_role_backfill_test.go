@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"messaging-service/internal/contact"
+	"messaging-service/internal/domain"
+	"messaging-service/internal/repository/memory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestBusinessRegistry(t *testing.T) *contact.BusinessRegistry {
+	t.Helper()
+
+	registry, err := contact.NewBusinessRegistry(contact.NewNormalizer(contact.DefaultRegion), []string{"+12016661234"}, []string{"usehatchapp.com"}, nil)
+	require.NoError(t, err)
+	return registry
+}
+
+// createTestMessage stores a message in the conversation
+func createTestMessage(t *testing.T, messages domain.MessageRepository, conversations domain.ConversationRepository, conv *domain.Conversation, body string, at time.Time) {
+	t.Helper()
+
+	message := &domain.Message{
+		ConversationID: conv.ID,
+		From:           conv.CustomerContact,
+		To:             conv.BusinessContact,
+		Type:           domain.MessageTypeSMS,
+		Direction:      domain.MessageDirectionInbound,
+		Body:           body,
+		Status:         domain.MessageStatusDelivered,
+		Timestamp:      at,
+		CreatedAt:      at,
+		UpdatedAt:      at,
+	}
+	require.NoError(t, messages.Create(context.Background(), message))
+	require.NoError(t, conversations.RecordMessage(context.Background(), conv.ID, at, body, domain.MessageDirectionInbound))
+}
+
+func TestConversationRoleBackfill_Run(t *testing.T) {
+	ctx := context.Background()
+	conversations := memory.NewConversationRepository()
+	messages := memory.NewMessageRepository()
+	backfill := NewConversationRoleBackfill(conversations, messages, noopTransactor{}, newTestBusinessRegistry(t), zap.NewNop())
+
+	// Stored with the business number as the customer
+	reversed, err := conversations.Create(ctx, "+12016661234", "+18045551234")
+	require.NoError(t, err)
+	// Already correct
+	correct, err := conversations.Create(ctx, "contact@gmail.com", "user@usehatchapp.com")
+	require.NoError(t, err)
+	// Neither side is a business identity
+	_, err = conversations.Create(ctx, "+13125550000", "+18045551234")
+	require.NoError(t, err)
+
+	// A reversed conversation whose correct counterpart was created later is merged into it
+	legacy, err := conversations.Create(ctx, "+12016661234", "+17035550000")
+	require.NoError(t, err)
+	current, err := conversations.Create(ctx, "+17035550000", "+12016661234")
+	require.NoError(t, err)
+	now := time.Now().UTC()
+	createTestMessage(t, messages, conversations, legacy, "Newer legacy message", now)
+	createTestMessage(t, messages, conversations, current, "Older message", now.Add(-time.Hour))
+
+	// A dry run only counts
+	result, err := backfill.Run(ctx, true)
+	require.NoError(t, err)
+	assert.Equal(t, &RoleBackfillResult{Scanned: 5, Swapped: 1, Merged: 1, Unresolved: 1}, result)
+	_, err = conversations.GetByContacts(ctx, "+12016661234", "+18045551234")
+	require.NoError(t, err)
+
+	result, err = backfill.Run(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, &RoleBackfillResult{Scanned: 5, Swapped: 1, Merged: 1, Unresolved: 1}, result)
+
+	swapped, err := conversations.GetByID(ctx, reversed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "+18045551234", swapped.CustomerContact)
+	assert.Equal(t, "+12016661234", swapped.BusinessContact)
+
+	unchanged, err := conversations.GetByID(ctx, correct.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "contact@gmail.com", unchanged.CustomerContact)
+
+	_, err = conversations.GetByID(ctx, legacy.ID)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	merged, err := messages.GetByConversationID(ctx, current.ID)
+	require.NoError(t, err)
+	assert.Len(t, merged, 2)
+	target, err := conversations.GetByID(ctx, current.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Newer legacy message", *target.LastMessagePreview)
+
+	// Running again finds nothing left to fix
+	result, err = backfill.Run(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, &RoleBackfillResult{Scanned: 4, Unresolved: 1}, result)
+}
+
+func TestConversationRoleBackfill_RequiresRegistry(t *testing.T) {
+	registry, err := contact.NewBusinessRegistry(contact.NewNormalizer(contact.DefaultRegion), nil, nil, nil)
+	require.NoError(t, err)
+	backfill := NewConversationRoleBackfill(memory.NewConversationRepository(), memory.NewMessageRepository(), noopTransactor{}, registry, zap.NewNop())
+
+	_, err = backfill.Run(context.Background(), false)
+	assert.Error(t, err)
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"messaging-service/internal/domain"
-	"strings"
 )
 
 type conversationService struct {
@@ -23,35 +22,6 @@ func NewConversationService(
 	}
 }
 
-// normalizeContacts ensures consistent ordering of contacts for conversation grouping
-func (s *conversationService) normalizeContacts(customerContact, businessContact string) (string, string) {
-	// For phone numbers, normalize by removing any formatting
-	customer := strings.ReplaceAll(customerContact, "-", "")
-	customer = strings.ReplaceAll(customer, " ", "")
-	customer = strings.ReplaceAll(customer, "(", "")
-	customer = strings.ReplaceAll(customer, ")", "")
-
-	business := strings.ReplaceAll(businessContact, "-", "")
-	business = strings.ReplaceAll(business, " ", "")
-	business = strings.ReplaceAll(business, "(", "")
-	business = strings.ReplaceAll(business, ")", "")
-
-	// For email addresses, use as-is
-	if strings.Contains(customer, "@") && strings.Contains(business, "@") {
-		// For emails, sort alphabetically to ensure consistent ordering
-		if customer < business {
-			return customer, business
-		}
-		return business, customer
-	}
-
-	// For phone numbers, sort numerically
-	if customer < business {
-		return customer, business
-	}
-	return business, customer
-}
-
 func (s *conversationService) GetConversations(ctx context.Context, query *domain.ConversationQuery) (*domain.GetConversationsResponse, error) {
 	// Set default values if not provided
 	if query.Limit <= 0 {
@@ -96,22 +66,28 @@ func (s *conversationService) GetConversations(ctx context.Context, query *domai
 	}, nil
 }
 
-func (s *conversationService) GetConversationMessages(ctx context.Context, conversationID int) ([]domain.Message, error) {
+func (s *conversationService) GetConversationMessages(ctx context.Context, conversationID int, direction string) ([]domain.Message, error) {
 	// Verify conversation exists
-	conversation, err := s.conversationRepo.GetByID(ctx, conversationID)
-	if err != nil {
+	if _, err := s.conversationRepo.GetByID(ctx, conversationID); err != nil {
 		return nil, fmt.Errorf("failed to get conversation: %w", err)
 	}
 
-	if conversation == nil {
-		return nil, fmt.Errorf("conversation not found: %d", conversationID)
-	}
-
 	// Get messages for the conversation
 	messages, err := s.messageRepo.GetByConversationID(ctx, conversationID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get messages for conversation %d: %w", conversationID, err)
 	}
 
-	return messages, nil
+	if direction == "" {
+		return messages, nil
+	}
+
+	filtered := make([]domain.Message, 0, len(messages))
+	for _, message := range messages {
+		if message.Direction == direction {
+			filtered = append(filtered, message)
+		}
+	}
+
+	return filtered, nil
 }
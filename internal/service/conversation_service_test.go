@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"messaging-service/internal/domain"
+	"messaging-service/internal/repository/memory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConversationService_GetConversationMessages_FiltersByDirection(t *testing.T) {
+	ctx := context.Background()
+	conversations := memory.NewConversationRepository()
+	messages := memory.NewMessageRepository()
+	service := NewConversationService(conversations, messages)
+
+	conv, err := conversations.Create(ctx, "+18045551234", "+12016661234")
+	require.NoError(t, err)
+
+	at := time.Now().UTC()
+	for i, direction := range []string{domain.MessageDirectionInbound, domain.MessageDirectionOutbound, domain.MessageDirectionInbound} {
+		require.NoError(t, messages.Create(ctx, &domain.Message{
+			ConversationID: conv.ID,
+			From:           conv.CustomerContact,
+			To:             conv.BusinessContact,
+			Type:           domain.MessageTypeSMS,
+			Direction:      direction,
+			Body:           "Hello",
+			Status:         domain.MessageStatusDelivered,
+			Timestamp:      at.Add(time.Duration(i) * time.Second),
+		}))
+	}
+
+	all, err := service.GetConversationMessages(ctx, conv.ID, "")
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+
+	inbound, err := service.GetConversationMessages(ctx, conv.ID, domain.MessageDirectionInbound)
+	require.NoError(t, err)
+	require.Len(t, inbound, 2)
+	for _, message := range inbound {
+		assert.Equal(t, domain.MessageDirectionInbound, message.Direction)
+	}
+
+	_, err = service.GetConversationMessages(ctx, conv.ID+1, "")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"messaging-service/internal/domain"
+
+	"go.uber.org/zap"
+)
+
+// IdempotencyCleanerConfig holds idempotency key cleanup configuration
+type IdempotencyCleanerConfig struct {
+	Interval  time.Duration `json:"interval"`
+	BatchSize int           `json:"batch_size"`
+}
+
+// DefaultIdempotencyCleanerConfig returns default idempotency key cleanup configuration
+func DefaultIdempotencyCleanerConfig() IdempotencyCleanerConfig {
+	return IdempotencyCleanerConfig{
+		Interval:  10 * time.Minute,
+		BatchSize: 1000,
+	}
+}
+
+// IdempotencyCleaner periodically deletes idempotency keys whose responses are no longer replayed
+type IdempotencyCleaner struct {
+	idempotencyRepo domain.IdempotencyRepository
+	config          IdempotencyCleanerConfig
+	logger          *zap.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewIdempotencyCleaner creates a new idempotency key cleaner
+func NewIdempotencyCleaner(idempotencyRepo domain.IdempotencyRepository, config IdempotencyCleanerConfig, logger *zap.Logger) *IdempotencyCleaner {
+	return &IdempotencyCleaner{
+		idempotencyRepo: idempotencyRepo,
+		config:          config,
+		logger:          logger,
+	}
+}
+
+// Start begins deleting expired keys until Stop is called or ctx is cancelled
+func (c *IdempotencyCleaner) Start(ctx context.Context) {
+	ctx, c.cancel = context.WithCancel(ctx)
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+
+		ticker := time.NewTicker(c.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := c.DeleteExpired(ctx); err != nil && ctx.Err() == nil {
+					c.logger.Error("Failed to delete expired idempotency keys", zap.Error(err))
+				}
+			}
+		}
+	}()
+
+	c.logger.Info("Idempotency cleaner started", zap.Duration("interval", c.config.Interval))
+}
+
+// Stop stops the cleaner and waits for the current run to finish
+func (c *IdempotencyCleaner) Stop() {
+	if c.cancel == nil {
+		return
+	}
+	c.cancel()
+	c.wg.Wait()
+	c.logger.Info("Idempotency cleaner stopped")
+}
+
+// DeleteExpired deletes expired keys in batches until none are left, returning the number deleted
+func (c *IdempotencyCleaner) DeleteExpired(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		count, err := c.idempotencyRepo.DeleteExpired(ctx, c.config.BatchSize)
+		if err != nil {
+			return total, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+		}
+		total += count
+
+		if count < c.config.BatchSize || ctx.Err() != nil {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"messaging-service/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+type MockIdempotencyRepository struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyRepository) Reserve(ctx context.Context, key, fingerprint string, lockedUntil, expiresAt time.Time) (*domain.IdempotencyRecord, bool, error) {
+	args := m.Called(ctx, key, fingerprint, lockedUntil, expiresAt)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).(*domain.IdempotencyRecord), args.Bool(1), args.Error(2)
+}
+
+func (m *MockIdempotencyRepository) Complete(ctx context.Context, key string, lockedUntil time.Time, statusCode int, responseBody []byte) error {
+	args := m.Called(ctx, key, lockedUntil, statusCode, responseBody)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) Release(ctx context.Context, key string, lockedUntil time.Time) error {
+	args := m.Called(ctx, key, lockedUntil)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) DeleteExpired(ctx context.Context, limit int) (int, error) {
+	args := m.Called(ctx, limit)
+	return args.Int(0), args.Error(1)
+}
+
+func TestIdempotencyCleaner_DeleteExpired_DrainsFullBatches(t *testing.T) {
+	idempotencyRepo := &MockIdempotencyRepository{}
+	cleaner := NewIdempotencyCleaner(idempotencyRepo, IdempotencyCleanerConfig{BatchSize: 2}, zap.NewNop())
+
+	idempotencyRepo.On("DeleteExpired", mock.Anything, 2).Return(2, nil).Twice()
+	idempotencyRepo.On("DeleteExpired", mock.Anything, 2).Return(0, nil).Once()
+
+	count, err := cleaner.DeleteExpired(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, count)
+	idempotencyRepo.AssertExpectations(t)
+}
+
+func TestIdempotencyCleaner_DeleteExpired_ReturnsStorageError(t *testing.T) {
+	idempotencyRepo := &MockIdempotencyRepository{}
+	cleaner := NewIdempotencyCleaner(idempotencyRepo, DefaultIdempotencyCleanerConfig(), zap.NewNop())
+
+	idempotencyRepo.On("DeleteExpired", mock.Anything, 1000).Return(0, errors.New("connection refused"))
+
+	_, err := cleaner.DeleteExpired(context.Background())
+
+	assert.Error(t, err)
+}
@@ -2,47 +2,39 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"sort"
 	"strings"
 	"time"
 
+	"messaging-service/internal/contact"
 	"messaging-service/internal/domain"
+	"messaging-service/internal/retry"
 )
 
+// deliveryLease is how long a claimed outbox entry stays hidden from other dispatchers.
+// It must comfortably exceed a full round of provider retries.
+const deliveryLease = 5 * time.Minute
+
 type messagingService struct {
 	conversationRepo domain.ConversationRepository
 	messageRepo      domain.MessageRepository
+	outboxRepo       domain.OutboxRepository
+	transactor       domain.Transactor
 	smsProvider      domain.SMSProvider
 	emailProvider    domain.EmailProvider
-	retryConfig      RetryConfig
-}
-
-// RetryConfig holds retry configuration
-type RetryConfig struct {
-	MaxRetries int           `json:"max_retries"`
-	BaseDelay  time.Duration `json:"base_delay"`
-	MaxDelay   time.Duration `json:"max_delay"`
-	Multiplier float64       `json:"multiplier"`
-}
-
-// DefaultRetryConfig returns default retry configuration
-func DefaultRetryConfig() RetryConfig {
-	return RetryConfig{
-		MaxRetries: 3,
-		BaseDelay:  time.Second,
-		MaxDelay:   time.Minute,
-		Multiplier: 2.0,
-	}
+	retryPolicy      retry.Policy
+	contacts         *contact.Normalizer
 }
 
-// TestRetryConfig returns fast retry configuration for tests
-func TestRetryConfig() RetryConfig {
-	return RetryConfig{
+// TestRetryPolicy returns a fast retry policy for tests
+func TestRetryPolicy() retry.Policy {
+	return retry.Policy{
 		MaxRetries: 3,
 		BaseDelay:  time.Millisecond * 10,
 		MaxDelay:   time.Millisecond * 100,
 		Multiplier: 2.0,
+		Jitter:     retry.JitterNone,
 	}
 }
 
@@ -50,72 +42,392 @@ func TestRetryConfig() RetryConfig {
 func NewMessagingService(
 	conversationRepo domain.ConversationRepository,
 	messageRepo domain.MessageRepository,
+	outboxRepo domain.OutboxRepository,
+	transactor domain.Transactor,
 	smsProvider domain.SMSProvider,
 	emailProvider domain.EmailProvider,
 ) domain.MessagingService {
 	return &messagingService{
 		conversationRepo: conversationRepo,
 		messageRepo:      messageRepo,
+		outboxRepo:       outboxRepo,
+		transactor:       transactor,
 		smsProvider:      smsProvider,
 		emailProvider:    emailProvider,
-		retryConfig:      DefaultRetryConfig(),
+		retryPolicy:      retry.DefaultPolicy(),
+		contacts:         contact.NewNormalizer(contact.DefaultRegion),
 	}
 }
 
-// NewMessagingServiceWithConfig creates a messaging service with custom retry configuration
+// NewMessagingServiceWithConfig creates a messaging service with a custom retry policy and
+// contact normalizer
 func NewMessagingServiceWithConfig(
 	conversationRepo domain.ConversationRepository,
 	messageRepo domain.MessageRepository,
+	outboxRepo domain.OutboxRepository,
+	transactor domain.Transactor,
 	smsProvider domain.SMSProvider,
 	emailProvider domain.EmailProvider,
-	retryConfig RetryConfig,
+	retryPolicy retry.Policy,
+	contacts *contact.Normalizer,
 ) domain.MessagingService {
 	return &messagingService{
 		conversationRepo: conversationRepo,
 		messageRepo:      messageRepo,
+		outboxRepo:       outboxRepo,
+		transactor:       transactor,
 		smsProvider:      smsProvider,
 		emailProvider:    emailProvider,
-		retryConfig:      retryConfig,
+		retryPolicy:      retryPolicy,
+		contacts:         contacts,
 	}
 }
 
-func (s *messagingService) SendSMS(ctx context.Context, req *domain.SendSMSRequest) error {
+func (s *messagingService) SendSMS(ctx context.Context, req *domain.SendSMSRequest) (*domain.Message, error) {
 	// Validate request
 	if err := s.validateSMSRequest(req); err != nil {
-		return fmt.Errorf("invalid SMS request: %w", err)
+		return nil, fmt.Errorf("invalid SMS request: %w", err)
 	}
 
-	// Send message through provider with retry logic
-	if err := s.sendSMSMessageWithRetry(ctx, req); err != nil {
-		return fmt.Errorf("failed to send message through provider: %w", err)
+	// Persist the message before calling the provider so it survives failures
+	message := s.buildOutboundMessage(req.From, req.To, req.Type, req.Body, req.Attachments, req.Timestamp)
+	if isScheduled(req.SendAt) {
+		return message, s.scheduleOutboundMessage(ctx, message, *req.SendAt)
+	}
+	return message, s.sendOutboundMessage(ctx, message)
+}
+
+func (s *messagingService) SendEmail(ctx context.Context, req *domain.SendEmailRequest) (*domain.Message, error) {
+	// Validate request
+	from, to, err := s.validateEmailRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("invalid email request: %w", err)
+	}
+
+	// Persist the message before calling the provider so it survives failures
+	message := s.buildOutboundMessage(from.Address, to.Address, domain.MessageTypeEmail, req.Body, req.Attachments, req.Timestamp)
+	setDisplayNames(message, from, to)
+	if isScheduled(req.SendAt) {
+		return message, s.scheduleOutboundMessage(ctx, message, *req.SendAt)
+	}
+	return message, s.sendOutboundMessage(ctx, message)
+}
+
+// EnqueueSMS persists an SMS/MMS for background delivery and returns without calling the provider
+func (s *messagingService) EnqueueSMS(ctx context.Context, req *domain.SendSMSRequest) (*domain.Message, error) {
+	// Validate request
+	if err := s.validateSMSRequest(req); err != nil {
+		return nil, fmt.Errorf("invalid SMS request: %w", err)
 	}
 
-	// Create message record
 	message := s.buildOutboundMessage(req.From, req.To, req.Type, req.Body, req.Attachments, req.Timestamp)
-	if err := s.createMessageRecord(ctx, message); err != nil {
-		return fmt.Errorf("failed to create message: %w", err)
+	if isScheduled(req.SendAt) {
+		if err := s.scheduleOutboundMessage(ctx, message, *req.SendAt); err != nil {
+			return nil, err
+		}
+		return message, nil
+	}
+	if _, err := s.enqueueOutboundMessage(ctx, message, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to create message: %w", err)
 	}
 
-	return nil
+	return message, nil
 }
 
-func (s *messagingService) SendEmail(ctx context.Context, req *domain.SendEmailRequest) error {
+// EnqueueEmail persists an email for background delivery and returns without calling the provider
+func (s *messagingService) EnqueueEmail(ctx context.Context, req *domain.SendEmailRequest) (*domain.Message, error) {
 	// Validate request
-	if err := s.validateEmailRequest(req); err != nil {
-		return fmt.Errorf("invalid email request: %w", err)
+	from, to, err := s.validateEmailRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("invalid email request: %w", err)
 	}
 
-	// Send email through provider with retry logic
-	if err := s.sendEmailMessageWithRetry(ctx, req); err != nil {
-		return fmt.Errorf("failed to send email through provider: %w", err)
+	message := s.buildOutboundMessage(from.Address, to.Address, domain.MessageTypeEmail, req.Body, req.Attachments, req.Timestamp)
+	setDisplayNames(message, from, to)
+	if isScheduled(req.SendAt) {
+		if err := s.scheduleOutboundMessage(ctx, message, *req.SendAt); err != nil {
+			return nil, err
+		}
+		return message, nil
+	}
+	if _, err := s.enqueueOutboundMessage(ctx, message, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to create message: %w", err)
 	}
 
-	// Create message record
-	message := s.buildOutboundMessage(req.From, req.To, domain.MessageTypeEmail, req.Body, req.Attachments, req.Timestamp)
-	if err := s.createMessageRecord(ctx, message); err != nil {
+	return message, nil
+}
+
+func (s *messagingService) HandleSMSStatus(ctx context.Context, webhook *domain.MessageStatusWebhook) error {
+	// Validate webhook
+	if err := s.validateStatusWebhook(webhook.MessagingProviderID, webhook.Status); err != nil {
+		return fmt.Errorf("invalid SMS status webhook: %w", err)
+	}
+
+	return s.applyDeliveryStatus(ctx, webhook.MessagingProviderID, webhook.Status, webhook.ErrorCode, webhook.ErrorMessage)
+}
+
+func (s *messagingService) HandleEmailStatus(ctx context.Context, webhook *domain.EmailStatusWebhook) error {
+	// Validate webhook
+	if err := s.validateStatusWebhook(webhook.XillioID, webhook.Status); err != nil {
+		return fmt.Errorf("invalid email status webhook: %w", err)
+	}
+
+	return s.applyDeliveryStatus(ctx, webhook.XillioID, webhook.Status, webhook.ErrorCode, webhook.ErrorMessage)
+}
+
+// maxStatusUpdateAttempts bounds how often a receipt is re-applied after losing a race with a concurrent update
+const maxStatusUpdateAttempts = 3
+
+// applyDeliveryStatus moves the message identified by its provider ID to the reported status.
+// Receipts that would move a message backwards (for example delivered -> sent) are ignored.
+func (s *messagingService) applyDeliveryStatus(ctx context.Context, providerMessageID, status, errorCode, errorMessage string) error {
+	for attempt := 0; attempt < maxStatusUpdateAttempts; attempt++ {
+		message, err := s.messageRepo.GetByProviderMessageID(ctx, providerMessageID)
+		if err != nil {
+			return fmt.Errorf("failed to get message: %w", err)
+		}
+
+		// Receipts only concern messages the business sent
+		if message.Direction == domain.MessageDirectionInbound {
+			return nil
+		}
+
+		// Duplicate or out-of-order receipt
+		if !domain.CanTransitionStatus(message.Status, status) {
+			return nil
+		}
+
+		previousStatus := message.Status
+		message.Status = status
+		if errorCode != "" {
+			message.ErrorCode = &errorCode
+		}
+		if errorMessage != "" {
+			message.ErrorMessage = &errorMessage
+		}
+
+		// Only apply the receipt if no concurrent update changed the status in the meantime
+		updated, err := s.messageRepo.UpdateIfStatus(ctx, message, previousStatus)
+		if err != nil {
+			return fmt.Errorf("failed to update message status: %w", err)
+		}
+		if updated {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("failed to apply status %s to message with provider ID %s: status changed concurrently", status, providerMessageID)
+}
+
+// DeliverMessage sends a persisted outbound message through its provider and records
+// the outcome. The message is only modified once the outcome has been stored, so a
+// returned error with the message still pending means delivery should be retried.
+func (s *messagingService) DeliverMessage(ctx context.Context, message *domain.Message) error {
+	// Skip messages that an earlier attempt already resolved (at-least-once delivery)
+	if message.Status != domain.MessageStatusPending {
+		return nil
+	}
+
+	var result *domain.SendResult
+	sendErr := s.retryPolicy.Do(ctx, func() error {
+		var err error
+		result, err = s.sendMessage(ctx, message)
+		return err
+	}, func(attempt retry.Attempt) {
+		s.recordAttempt(ctx, message, attempt, result)
+	})
+
+	// Leave the message pending when interrupted or when the provider's circuit breaker
+	// is open so it is picked up again later
+	if errors.Is(sendErr, context.Canceled) || errors.Is(sendErr, context.DeadlineExceeded) ||
+		errors.Is(sendErr, domain.ErrCircuitOpen) {
+		return sendErr
+	}
+
+	updated := *message
+	if sendErr != nil {
+		errorCode, errorMessage := s.describeSendError(sendErr)
+		updated.Status = domain.MessageStatusFailed
+		updated.ErrorCode = &errorCode
+		updated.ErrorMessage = &errorMessage
+		updated.Provider = failedProvider(sendErr)
+	} else {
+		updated.Status = domain.MessageStatusSent
+		updated.ErrorCode = nil
+		updated.ErrorMessage = nil
+		applySendResult(&updated, result)
+	}
+	updated.UpdatedAt = time.Now()
+
+	if err := s.messageRepo.Update(ctx, &updated); err != nil {
+		return fmt.Errorf("failed to update message status: %w", err)
+	}
+	*message = updated
+
+	return sendErr
+}
+
+func (s *messagingService) GetMessage(ctx context.Context, id int) (*domain.Message, error) {
+	message, err := s.messageRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+
+	return message, nil
+}
+
+func (s *messagingService) GetMessageEvents(ctx context.Context, id int) ([]domain.MessageEvent, error) {
+	// Verify message exists
+	if _, err := s.GetMessage(ctx, id); err != nil {
+		return nil, err
+	}
+
+	events, err := s.messageRepo.GetEvents(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events for message %d: %w", id, err)
+	}
+
+	return events, nil
+}
+
+func (s *messagingService) GetMessageAttempts(ctx context.Context, id int) ([]domain.MessageAttempt, error) {
+	// Verify message exists
+	if _, err := s.GetMessage(ctx, id); err != nil {
+		return nil, err
+	}
+
+	attempts, err := s.messageRepo.GetAttempts(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attempts for message %d: %w", id, err)
+	}
+
+	return attempts, nil
+}
+
+func (s *messagingService) ListDeadLetters(ctx context.Context, query *domain.DeadLetterQuery) (*domain.GetDeadLettersResponse, error) {
+	// Set default values if not provided
+	if query.Limit <= 0 {
+		query.Limit = 50
+	}
+	if query.Offset < 0 {
+		query.Offset = 0
+	}
+
+	messages, total, err := s.messageRepo.ListFailed(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead letters: %w", err)
+	}
+
+	return &domain.GetDeadLettersResponse{
+		Messages: messages,
+		Total:    total,
+		Page:     (query.Offset / query.Limit) + 1,
+		PerPage:  query.Limit,
+		HasMore:  (query.Offset + query.Limit) < total,
+	}, nil
+}
+
+// ReplayMessage resets a failed message to pending and hands it to the dispatcher, so
+// the replay goes through the same providers, retries and breakers as a new send
+func (s *messagingService) ReplayMessage(ctx context.Context, id int) (*domain.Message, error) {
+	message, err := s.GetMessage(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if message.Status != domain.MessageStatusFailed {
+		return nil, fmt.Errorf("message %d has status %s: %w", id, message.Status, domain.ErrInvalidState)
+	}
+
+	// Requeue fails with ErrConflict when another replay or status update got there first
+	if _, err := s.outboxRepo.Requeue(ctx, message, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to requeue message %d: %w", id, err)
+	}
+
+	return message, nil
+}
+
+// CancelMessage cancels a scheduled message before the scheduler hands it to the outbox
+func (s *messagingService) CancelMessage(ctx context.Context, id int) (*domain.Message, error) {
+	message, err := s.GetMessage(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if message.Status != domain.MessageStatusScheduled {
+		return nil, fmt.Errorf("message %d has status %s: %w", id, message.Status, domain.ErrInvalidState)
+	}
+
+	updated := *message
+	updated.Status = domain.MessageStatusCancelled
+	updated.UpdatedAt = time.Now()
+
+	// The scheduler may have picked the message up since it was read
+	ok, err := s.messageRepo.UpdateIfStatus(ctx, &updated, domain.MessageStatusScheduled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel message %d: %w", id, err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("message %d is no longer scheduled: %w", id, domain.ErrInvalidState)
+	}
+
+	return &updated, nil
+}
+
+// RescheduleMessage changes when a scheduled message is sent
+func (s *messagingService) RescheduleMessage(ctx context.Context, id int, sendAt time.Time) (*domain.Message, error) {
+	if !isScheduled(&sendAt) {
+		return nil, fmt.Errorf("send time must be in the future")
+	}
+
+	message, err := s.GetMessage(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if message.Status != domain.MessageStatusScheduled {
+		return nil, fmt.Errorf("message %d has status %s: %w", id, message.Status, domain.ErrInvalidState)
+	}
+
+	sendAt = sendAt.UTC()
+	ok, err := s.messageRepo.Reschedule(ctx, id, sendAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reschedule message %d: %w", id, err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("message %d is no longer scheduled: %w", id, domain.ErrInvalidState)
+	}
+
+	message.SendAt = &sendAt
+	message.UpdatedAt = time.Now()
+
+	return message, nil
+}
+
+// sendOutboundMessage stores the message with an outbox entry and delivers it inline.
+// The entry is leased to this request, so the dispatcher only takes over if the
+// process dies before the outcome is recorded.
+func (s *messagingService) sendOutboundMessage(ctx context.Context, message *domain.Message) error {
+	entry, err := s.enqueueOutboundMessage(ctx, message, time.Now().Add(deliveryLease))
+	if err != nil {
 		return fmt.Errorf("failed to create message: %w", err)
 	}
 
+	deliverErr := s.DeliverMessage(ctx, message)
+
+	// Once the outcome is recorded the outbox entry is no longer needed. A failed
+	// delete is harmless: the dispatcher skips resolved messages and drops the entry.
+	if message.Status != domain.MessageStatusPending {
+		_ = s.outboxRepo.Delete(ctx, entry.ID)
+	}
+
+	if deliverErr != nil {
+		if message.Type == domain.MessageTypeEmail {
+			return fmt.Errorf("failed to send email through provider: %w", deliverErr)
+		}
+		return fmt.Errorf("failed to send message through provider: %w", deliverErr)
+	}
+
 	return nil
 }
 
@@ -126,13 +438,21 @@ func (s *messagingService) HandleInboundSMS(ctx context.Context, webhook *domain
 	}
 
 	// Check if message already exists (idempotency)
-	if existingMessage, err := s.messageRepo.GetByProviderMessageID(ctx, webhook.MessagingProviderID); err == nil && existingMessage != nil {
+	_, err := s.messageRepo.GetByProviderMessageID(ctx, webhook.MessagingProviderID)
+	if err == nil {
 		return nil // Message already processed
 	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return fmt.Errorf("failed to check for duplicate message: %w", err)
+	}
 
 	// Create message record
 	message := s.buildInboundMessage(webhook.From, webhook.To, webhook.Type, webhook.Body, webhook.Attachments, webhook.Timestamp, webhook.MessagingProviderID)
 	if err := s.createMessageRecord(ctx, message); err != nil {
+		// A concurrent delivery of the same webhook stored it first
+		if errors.Is(err, domain.ErrConflict) {
+			return nil
+		}
 		return fmt.Errorf("failed to create message: %w", err)
 	}
 
@@ -141,18 +461,28 @@ func (s *messagingService) HandleInboundSMS(ctx context.Context, webhook *domain
 
 func (s *messagingService) HandleInboundEmail(ctx context.Context, webhook *domain.InboundEmailWebhook) error {
 	// Validate webhook
-	if err := s.validateInboundEmailWebhook(webhook); err != nil {
+	from, to, err := s.validateInboundEmailWebhook(webhook)
+	if err != nil {
 		return fmt.Errorf("invalid inbound email webhook: %w", err)
 	}
 
 	// Check if message already exists (idempotency)
-	if existingMessage, err := s.messageRepo.GetByProviderMessageID(ctx, webhook.XillioID); err == nil && existingMessage != nil {
+	_, err = s.messageRepo.GetByProviderMessageID(ctx, webhook.XillioID)
+	if err == nil {
 		return nil // Message already processed
 	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return fmt.Errorf("failed to check for duplicate message: %w", err)
+	}
 
 	// Create message record
-	message := s.buildInboundMessage(webhook.From, webhook.To, domain.MessageTypeEmail, webhook.Body, webhook.Attachments, webhook.Timestamp, webhook.XillioID)
+	message := s.buildInboundMessage(from.Address, to.Address, domain.MessageTypeEmail, webhook.Body, webhook.Attachments, webhook.Timestamp, webhook.XillioID)
+	setDisplayNames(message, from, to)
 	if err := s.createMessageRecord(ctx, message); err != nil {
+		// A concurrent delivery of the same webhook stored it first
+		if errors.Is(err, domain.ErrConflict) {
+			return nil
+		}
 		return fmt.Errorf("failed to create message: %w", err)
 	}
 
@@ -168,9 +498,10 @@ func (s *messagingService) buildOutboundMessage(from, to, messageType, body stri
 		From:        from,
 		To:          to,
 		Type:        messageType,
+		Direction:   domain.MessageDirectionOutbound,
 		Body:        body,
 		Attachments: attachments,
-		Status:      "pending", // Outbound messages start as pending
+		Status:      domain.MessageStatusPending, // Outbound messages start as pending
 		Timestamp:   utcTimestamp,
 	}
 }
@@ -184,93 +515,187 @@ func (s *messagingService) buildInboundMessage(from, to, messageType, body strin
 		From:                from,
 		To:                  to,
 		Type:                messageType,
+		Direction:           domain.MessageDirectionInbound,
 		Body:                body,
 		Attachments:         attachments,
-		Status:              "delivered", // Inbound messages are considered delivered
+		Status:              domain.MessageStatusDelivered, // Inbound messages are considered delivered
 		Timestamp:           utcTimestamp,
 		MessagingProviderID: &providerMessageID,
 	}
 }
 
-// sendSMSMessage sends an SMS/MMS message through the provider
-func (s *messagingService) sendSMSMessage(ctx context.Context, req *domain.SendSMSRequest) error {
-	switch req.Type {
+// setDisplayNames stores the display names an email's addresses were given with on its message
+func setDisplayNames(message *domain.Message, from, to contact.EmailAddress) {
+	if from.Name != "" {
+		message.FromName = &from.Name
+	}
+	if to.Name != "" {
+		message.ToName = &to.Name
+	}
+}
+
+// sendMessage sends a message through the provider for its type
+func (s *messagingService) sendMessage(ctx context.Context, message *domain.Message) (*domain.SendResult, error) {
+	switch message.Type {
 	case domain.MessageTypeSMS:
-		return s.smsProvider.SendSMS(ctx, req.From, req.To, req.Body)
+		return s.smsProvider.SendSMS(ctx, message.From, message.To, message.Body)
 	case domain.MessageTypeMMS:
-		return s.smsProvider.SendMMS(ctx, req.From, req.To, req.Body, req.Attachments)
+		return s.smsProvider.SendMMS(ctx, message.From, message.To, message.Body, message.Attachments)
+	case domain.MessageTypeEmail:
+		return s.emailProvider.SendEmail(ctx, message.From, message.To, message.Body, message.Attachments)
 	default:
-		return fmt.Errorf("invalid message type: %s", req.Type)
+		return nil, fmt.Errorf("invalid message type: %s", message.Type)
 	}
 }
 
-// retryWithBackoff executes a function with retry logic and exponential backoff
-func (s *messagingService) retryWithBackoff(ctx context.Context, operation func() error) error {
-	for attempt := 0; attempt <= s.retryConfig.MaxRetries; attempt++ {
-		err := operation()
-		if err == nil {
-			return nil
+// applySendResult stores what the provider reported about an accepted message
+func applySendResult(message *domain.Message, result *domain.SendResult) {
+	if result == nil {
+		return
+	}
+	if result.ProviderMessageID != "" {
+		message.MessagingProviderID = &result.ProviderMessageID
+	}
+	if result.Provider != "" {
+		message.Provider = &result.Provider
+	}
+	if result.Segments > 0 {
+		message.SegmentCount = &result.Segments
+	}
+	if !result.AcceptedAt.IsZero() {
+		sentAt := result.AcceptedAt.UTC()
+		message.SentAt = &sentAt
+	}
+}
+
+// describeSendError extracts the error code and message stored on a failed message
+func (s *messagingService) describeSendError(err error) (string, string) {
+	var providerErr *domain.ProviderError
+	if errors.As(err, &providerErr) {
+		return fmt.Sprintf("%d", providerErr.Code), providerErr.Message
+	}
+	return "send_failed", err.Error()
+}
+
+// failedProvider returns the provider that reported a send error, when known
+func failedProvider(err error) *string {
+	var providerErr *domain.ProviderError
+	if errors.As(err, &providerErr) && providerErr.Provider != "" {
+		return &providerErr.Provider
+	}
+	return nil
+}
+
+// recordAttempt stores a delivery attempt against the message. The attempt history is
+// diagnostic, so a failed write does not affect delivery.
+func (s *messagingService) recordAttempt(ctx context.Context, message *domain.Message, attempt retry.Attempt, result *domain.SendResult) {
+	record := &domain.MessageAttempt{
+		MessageID:    message.ID,
+		Succeeded:    attempt.Err == nil,
+		DurationMs:   attempt.Duration.Milliseconds(),
+		RetryDelayMs: attempt.Delay.Milliseconds(),
+		StartedAt:    attempt.StartedAt.UTC(),
+	}
+	if attempt.Err == nil {
+		if result != nil && result.Provider != "" {
+			record.Provider = &result.Provider
 		}
+	} else {
+		errorClass := attempt.Class.String()
+		errorCode, errorMessage := s.describeSendError(attempt.Err)
+		record.Provider = failedProvider(attempt.Err)
+		record.ErrorClass = &errorClass
+		record.ErrorCode = &errorCode
+		record.ErrorMessage = &errorMessage
+	}
+
+	// Record attempts that were interrupted by cancellation too
+	_ = s.messageRepo.RecordAttempt(context.WithoutCancel(ctx), record)
+}
+
+// isScheduled reports whether a requested send time is still in the future
+func isScheduled(sendAt *time.Time) bool {
+	return sendAt != nil && sendAt.After(time.Now())
+}
 
-		// Check if error is retryable
-		if !domain.IsRetryableError(err) {
+// scheduleOutboundMessage stores a message that the scheduler queues for delivery at sendAt
+func (s *messagingService) scheduleOutboundMessage(ctx context.Context, message *domain.Message, sendAt time.Time) error {
+	sendAt = sendAt.UTC()
+	message.Status = domain.MessageStatusScheduled
+	message.SendAt = &sendAt
+
+	if err := s.createMessageRecord(ctx, message); err != nil {
+		return fmt.Errorf("failed to create message: %w", err)
+	}
+
+	return nil
+}
+
+// createMessageRecord creates a message record and records it on its conversation in one transaction
+func (s *messagingService) createMessageRecord(ctx context.Context, message *domain.Message) error {
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.assignConversation(ctx, message); err != nil {
 			return err
 		}
 
-		// If this is the last attempt, return the error
-		if attempt == s.retryConfig.MaxRetries {
+		// Create the message record
+		if err := s.messageRepo.Create(ctx, message); err != nil {
 			return err
 		}
 
-		// Calculate delay with exponential backoff
-		delay := s.retryConfig.BaseDelay * time.Duration(1<<attempt)
+		return s.recordConversationMessage(ctx, message)
+	})
+}
 
-		// Cap delay at maximum
-		if delay > s.retryConfig.MaxDelay {
-			delay = s.retryConfig.MaxDelay
+// enqueueOutboundMessage creates a message record together with its outbox entry
+func (s *messagingService) enqueueOutboundMessage(ctx context.Context, message *domain.Message, availableAt time.Time) (*domain.OutboxEntry, error) {
+	var entry *domain.OutboxEntry
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.assignConversation(ctx, message); err != nil {
+			return err
 		}
 
-		// For rate limit errors, use the RetryAfter value if available
-		if retryAfter := domain.GetRetryAfterSeconds(err); retryAfter > 0 {
-			retryDelay := time.Duration(retryAfter) * time.Second
-			// Use the smaller of retry delay or max delay
-			if retryDelay < s.retryConfig.MaxDelay {
-				delay = retryDelay
-			} else {
-				delay = s.retryConfig.MaxDelay
-			}
+		var err error
+		entry, err = s.outboxRepo.Enqueue(ctx, message, availableAt)
+		if err != nil {
+			return err
 		}
 
-		// Wait before retrying
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(delay):
-			continue
-		}
+		return s.recordConversationMessage(ctx, message)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return fmt.Errorf("max retries exceeded")
+	return entry, nil
 }
 
-// sendSMSMessageWithRetry sends SMS with retry logic for HTTP errors
-func (s *messagingService) sendSMSMessageWithRetry(ctx context.Context, req *domain.SendSMSRequest) error {
-	return s.retryWithBackoff(ctx, func() error {
-		return s.sendSMSMessage(ctx, req)
-	})
-}
+// maxPreviewLength is the number of characters of a message body kept as its conversation's preview
+const maxPreviewLength = 100
 
-// sendEmailMessageWithRetry sends email with retry logic for HTTP errors
-func (s *messagingService) sendEmailMessageWithRetry(ctx context.Context, req *domain.SendEmailRequest) error {
-	return s.retryWithBackoff(ctx, func() error {
-		return s.emailProvider.SendEmail(ctx, req.From, req.To, req.Body, req.Attachments)
-	})
+// recordConversationMessage updates the last activity time, preview and direction of the message's conversation
+func (s *messagingService) recordConversationMessage(ctx context.Context, message *domain.Message) error {
+	preview := []rune(message.Body)
+	if len(preview) > maxPreviewLength {
+		preview = preview[:maxPreviewLength]
+	}
+
+	if err := s.conversationRepo.RecordMessage(ctx, message.ConversationID, message.Timestamp, string(preview), message.Direction); err != nil {
+		return fmt.Errorf("failed to update conversation: %w", err)
+	}
+
+	return nil
 }
 
-// createMessageRecord creates a message record in the database
-func (s *messagingService) createMessageRecord(ctx context.Context, message *domain.Message) error {
-	// Normalize contacts for consistent conversation grouping
-	customerContact, businessContact := s.normalizeContacts(message.From, message.To)
+// assignConversation resolves the message's conversation and sets its record timestamps
+func (s *messagingService) assignConversation(ctx context.Context, message *domain.Message) error {
+	// The business sends outbound messages and receives inbound ones
+	customerContact, businessContact := message.To, message.From
+	if message.Direction == domain.MessageDirectionInbound {
+		customerContact, businessContact = message.From, message.To
+	}
+	customerContact = s.conversationKey(message.Type, customerContact)
+	businessContact = s.conversationKey(message.Type, businessContact)
 
 	// Get or create conversation
 	conversation, err := s.conversationRepo.GetOrCreate(ctx, customerContact, businessContact)
@@ -283,30 +708,23 @@ func (s *messagingService) createMessageRecord(ctx context.Context, message *dom
 	message.CreatedAt = time.Now()
 	message.UpdatedAt = time.Now()
 
-	// Create the message record
-	return s.messageRepo.Create(ctx, message)
+	return nil
 }
 
-// normalizeContacts ensures consistent ordering of contacts for conversation grouping
-func (s *messagingService) normalizeContacts(customerContact, businessContact string) (string, string) {
-	// For email addresses, sort alphabetically
-	if strings.Contains(customerContact, "@") && strings.Contains(businessContact, "@") {
-		contacts := []string{customerContact, businessContact}
-		sort.Strings(contacts)
-		return contacts[0], contacts[1]
+// conversationKey returns the canonical form of a message address that conversations are keyed
+// by. Email addresses are delivered to as given, but aliases of a mailbox share a conversation.
+func (s *messagingService) conversationKey(messageType, address string) string {
+	if messageType != domain.MessageTypeEmail {
+		return address
 	}
-
-	// For phone numbers, clean and sort
-	cleanCustomer := strings.ReplaceAll(customerContact, "-", "")
-	cleanBusiness := strings.ReplaceAll(businessContact, "-", "")
-
-	if cleanCustomer < cleanBusiness {
-		return customerContact, businessContact
+	parsed, err := s.contacts.ParseEmail(address)
+	if err != nil {
+		return address
 	}
-	return businessContact, customerContact
+	return parsed.Canonical
 }
 
-// validateSMSRequest validates an SMS request
+// validateSMSRequest validates an SMS request and converts its phone numbers to canonical form
 func (s *messagingService) validateSMSRequest(req *domain.SendSMSRequest) error {
 	if req == nil {
 		return fmt.Errorf("request cannot be nil")
@@ -317,6 +735,11 @@ func (s *messagingService) validateSMSRequest(req *domain.SendSMSRequest) error
 	if strings.TrimSpace(req.To) == "" {
 		return fmt.Errorf("to address cannot be empty")
 	}
+	from, to, err := s.normalizePhones(req.From, req.To)
+	if err != nil {
+		return err
+	}
+	req.From, req.To = from, to
 	if strings.TrimSpace(req.Body) == "" {
 		return fmt.Errorf("message body cannot be empty")
 	}
@@ -329,24 +752,55 @@ func (s *messagingService) validateSMSRequest(req *domain.SendSMSRequest) error
 	return nil
 }
 
-// validateEmailRequest validates an email request
-func (s *messagingService) validateEmailRequest(req *domain.SendEmailRequest) error {
+// normalizePhones returns the canonical forms of a message's phone numbers
+func (s *messagingService) normalizePhones(from, to string) (string, string, error) {
+	normalizedFrom, err := s.contacts.NormalizePhone(from)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid from address: %w", err)
+	}
+	normalizedTo, err := s.contacts.NormalizePhone(to)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid to address: %w", err)
+	}
+	return normalizedFrom, normalizedTo, nil
+}
+
+// parseEmails parses the addresses of an email into display names and addresses
+func (s *messagingService) parseEmails(from, to string) (contact.EmailAddress, contact.EmailAddress, error) {
+	parsedFrom, err := s.contacts.ParseEmail(from)
+	if err != nil {
+		return contact.EmailAddress{}, contact.EmailAddress{}, fmt.Errorf("invalid from address: %w", err)
+	}
+	parsedTo, err := s.contacts.ParseEmail(to)
+	if err != nil {
+		return contact.EmailAddress{}, contact.EmailAddress{}, fmt.Errorf("invalid to address: %w", err)
+	}
+	return parsedFrom, parsedTo, nil
+}
+
+// validateEmailRequest validates an email request and returns its parsed addresses
+func (s *messagingService) validateEmailRequest(req *domain.SendEmailRequest) (contact.EmailAddress, contact.EmailAddress, error) {
+	var from, to contact.EmailAddress
 	if req == nil {
-		return fmt.Errorf("request cannot be nil")
+		return from, to, fmt.Errorf("request cannot be nil")
 	}
 	if strings.TrimSpace(req.From) == "" {
-		return fmt.Errorf("from address cannot be empty")
+		return from, to, fmt.Errorf("from address cannot be empty")
 	}
 	if strings.TrimSpace(req.To) == "" {
-		return fmt.Errorf("to address cannot be empty")
+		return from, to, fmt.Errorf("to address cannot be empty")
+	}
+	from, to, err := s.parseEmails(req.From, req.To)
+	if err != nil {
+		return from, to, err
 	}
 	if strings.TrimSpace(req.Body) == "" {
-		return fmt.Errorf("message body cannot be empty")
+		return from, to, fmt.Errorf("message body cannot be empty")
 	}
 	if err := s.validateTimestamp(req.Timestamp); err != nil {
-		return fmt.Errorf("invalid timestamp: %w", err)
+		return from, to, fmt.Errorf("invalid timestamp: %w", err)
 	}
-	return nil
+	return from, to, nil
 }
 
 // validateTimestamp validates a timestamp for business logic
@@ -384,7 +838,7 @@ func (s *messagingService) validateTimestamp(timestamp time.Time) error {
 	return nil
 }
 
-// validateInboundSMSWebhook validates an inbound SMS webhook
+// validateInboundSMSWebhook validates an inbound SMS webhook and converts its phone numbers to canonical form
 func (s *messagingService) validateInboundSMSWebhook(webhook *domain.InboundSMSWebhook) error {
 	if webhook == nil {
 		return fmt.Errorf("webhook cannot be nil")
@@ -395,6 +849,11 @@ func (s *messagingService) validateInboundSMSWebhook(webhook *domain.InboundSMSW
 	if strings.TrimSpace(webhook.To) == "" {
 		return fmt.Errorf("to address cannot be empty")
 	}
+	from, to, err := s.normalizePhones(webhook.From, webhook.To)
+	if err != nil {
+		return err
+	}
+	webhook.From, webhook.To = from, to
 	if strings.TrimSpace(webhook.Body) == "" {
 		return fmt.Errorf("message body cannot be empty")
 	}
@@ -410,25 +869,43 @@ func (s *messagingService) validateInboundSMSWebhook(webhook *domain.InboundSMSW
 	return nil
 }
 
-// validateInboundEmailWebhook validates an inbound email webhook
-func (s *messagingService) validateInboundEmailWebhook(webhook *domain.InboundEmailWebhook) error {
+// validateStatusWebhook validates a delivery status webhook
+func (s *messagingService) validateStatusWebhook(providerMessageID, status string) error {
+	if strings.TrimSpace(providerMessageID) == "" {
+		return fmt.Errorf("provider message ID cannot be empty")
+	}
+	switch status {
+	case domain.MessageStatusSent, domain.MessageStatusDelivered, domain.MessageStatusFailed, domain.MessageStatusBounced:
+		return nil
+	default:
+		return fmt.Errorf("invalid status: %s", status)
+	}
+}
+
+// validateInboundEmailWebhook validates an inbound email webhook and returns its parsed addresses
+func (s *messagingService) validateInboundEmailWebhook(webhook *domain.InboundEmailWebhook) (contact.EmailAddress, contact.EmailAddress, error) {
+	var from, to contact.EmailAddress
 	if webhook == nil {
-		return fmt.Errorf("webhook cannot be nil")
+		return from, to, fmt.Errorf("webhook cannot be nil")
 	}
 	if strings.TrimSpace(webhook.From) == "" {
-		return fmt.Errorf("from address cannot be empty")
+		return from, to, fmt.Errorf("from address cannot be empty")
 	}
 	if strings.TrimSpace(webhook.To) == "" {
-		return fmt.Errorf("to address cannot be empty")
+		return from, to, fmt.Errorf("to address cannot be empty")
+	}
+	from, to, err := s.parseEmails(webhook.From, webhook.To)
+	if err != nil {
+		return from, to, err
 	}
 	if strings.TrimSpace(webhook.Body) == "" {
-		return fmt.Errorf("message body cannot be empty")
+		return from, to, fmt.Errorf("message body cannot be empty")
 	}
 	if strings.TrimSpace(webhook.XillioID) == "" {
-		return fmt.Errorf("xillio ID cannot be empty")
+		return from, to, fmt.Errorf("xillio ID cannot be empty")
 	}
 	if err := s.validateTimestamp(webhook.Timestamp); err != nil {
-		return fmt.Errorf("invalid timestamp: %w", err)
+		return from, to, fmt.Errorf("invalid timestamp: %w", err)
 	}
-	return nil
+	return from, to, nil
 }
@@ -2,14 +2,19 @@ package service
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"strings"
 	"testing"
 	"time"
 
+	"messaging-service/internal/contact"
 	"messaging-service/internal/domain"
 	"messaging-service/internal/provider"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // Mock repositories
@@ -54,6 +59,36 @@ func (m *MockConversationRepository) List(ctx context.Context, query *domain.Con
 	return args.Get(0).([]domain.Conversation), args.Get(1).(int), args.Error(2)
 }
 
+func (m *MockConversationRepository) RecordMessage(ctx context.Context, conversationID int, at time.Time, preview, direction string) error {
+	args := m.Called(ctx, conversationID, at, preview, direction)
+	return args.Error(0)
+}
+
+func (m *MockConversationRepository) SwapContacts(ctx context.Context, id int) (*domain.Conversation, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Conversation), args.Error(1)
+}
+
+func (m *MockConversationRepository) Delete(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockConversationRepository) ListByCustomerContacts(ctx context.Context, customerContacts []string, businessContact string) ([]domain.Conversation, error) {
+	args := m.Called(ctx, customerContacts, businessContact)
+	return args.Get(0).([]domain.Conversation), args.Error(1)
+}
+
+// noopTransactor runs operations directly, without a transaction
+type noopTransactor struct{}
+
+func (noopTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
 type MockMessageRepository struct {
 	mock.Mock
 }
@@ -76,6 +111,11 @@ func (m *MockMessageRepository) GetByConversationID(ctx context.Context, convers
 	return args.Get(0).([]domain.Message), args.Error(1)
 }
 
+func (m *MockMessageRepository) ListByConversationIDs(ctx context.Context, conversationIDs []int, limit, offset int) ([]domain.Message, int, error) {
+	args := m.Called(ctx, conversationIDs, limit, offset)
+	return args.Get(0).([]domain.Message), args.Int(1), args.Error(2)
+}
+
 func (m *MockMessageRepository) GetByProviderMessageID(ctx context.Context, providerMessageID string) (*domain.Message, error) {
 	args := m.Called(ctx, providerMessageID)
 	if args.Get(0) == nil {
@@ -89,25 +129,112 @@ func (m *MockMessageRepository) Update(ctx context.Context, message *domain.Mess
 	return args.Error(0)
 }
 
+func (m *MockMessageRepository) UpdateIfStatus(ctx context.Context, message *domain.Message, expectedStatus string) (bool, error) {
+	args := m.Called(ctx, message, expectedStatus)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMessageRepository) GetEvents(ctx context.Context, messageID int) ([]domain.MessageEvent, error) {
+	args := m.Called(ctx, messageID)
+	return args.Get(0).([]domain.MessageEvent), args.Error(1)
+}
+
+func (m *MockMessageRepository) RecordAttempt(ctx context.Context, attempt *domain.MessageAttempt) error {
+	args := m.Called(ctx, attempt)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) GetAttempts(ctx context.Context, messageID int) ([]domain.MessageAttempt, error) {
+	args := m.Called(ctx, messageID)
+	return args.Get(0).([]domain.MessageAttempt), args.Error(1)
+}
+
+func (m *MockMessageRepository) ListFailed(ctx context.Context, query *domain.DeadLetterQuery) ([]domain.Message, int, error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).([]domain.Message), args.Int(1), args.Error(2)
+}
+
+func (m *MockMessageRepository) Reschedule(ctx context.Context, id int, sendAt time.Time) (bool, error) {
+	args := m.Called(ctx, id, sendAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMessageRepository) MoveConversation(ctx context.Context, fromConversationID, toConversationID int) (int, error) {
+	args := m.Called(ctx, fromConversationID, toConversationID)
+	return args.Int(0), args.Error(1)
+}
+
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockOutboxRepository) Enqueue(ctx context.Context, message *domain.Message, availableAt time.Time) (*domain.OutboxEntry, error) {
+	args := m.Called(ctx, message, availableAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.OutboxEntry), args.Error(1)
+}
+
+func (m *MockOutboxRepository) ClaimDue(ctx context.Context, limit int, leaseDuration time.Duration) ([]domain.OutboxEntry, error) {
+	args := m.Called(ctx, limit, leaseDuration)
+	return args.Get(0).([]domain.OutboxEntry), args.Error(1)
+}
+
+func (m *MockOutboxRepository) Release(ctx context.Context, id int, availableAt time.Time, lastError string) error {
+	args := m.Called(ctx, id, availableAt, lastError)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) Delete(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) Requeue(ctx context.Context, message *domain.Message, availableAt time.Time) (*domain.OutboxEntry, error) {
+	args := m.Called(ctx, message, availableAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.OutboxEntry), args.Error(1)
+}
+
+func (m *MockOutboxRepository) EnqueueScheduled(ctx context.Context, limit int) (int, error) {
+	args := m.Called(ctx, limit)
+	return args.Int(0), args.Error(1)
+}
+
+// hasStatus matches a message argument with the given status
+func hasStatus(status string) interface{} {
+	return mock.MatchedBy(func(message *domain.Message) bool {
+		return message.Status == status
+	})
+}
+
 func TestMessagingService_SendSMS(t *testing.T) {
 	// Setup
 	conversationRepo := &MockConversationRepository{}
 	messageRepo := &MockMessageRepository{}
+	outboxRepo := &MockOutboxRepository{}
 	smsProvider := provider.NewMockSMSProvider()
 	emailProvider := provider.NewMockEmailProvider()
 
-	service := NewMessagingServiceWithConfig(conversationRepo, messageRepo, smsProvider, emailProvider, TestRetryConfig())
+	service := NewMessagingServiceWithConfig(conversationRepo, messageRepo, outboxRepo, noopTransactor{}, smsProvider, emailProvider, TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
 
 	// Mock expectations
-	conversationRepo.On("GetOrCreate", mock.Anything, "+12016661234", "+18045551234").Return(&domain.Conversation{
+	conversationRepo.On("RecordMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	conversationRepo.On("GetOrCreate", mock.Anything, "+18045551234", "+12016661234").Return(&domain.Conversation{
 		ID:              1,
-		CustomerContact: "+12016661234",
-		BusinessContact: "+18045551234",
+		CustomerContact: "+18045551234",
+		BusinessContact: "+12016661234",
 		CreatedAt:       time.Now().UTC(),
 		UpdatedAt:       time.Now().UTC(),
 	}, nil)
 
-	messageRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Message")).Return(nil)
+	outboxRepo.On("Enqueue", mock.Anything, mock.AnythingOfType("*domain.Message"), mock.AnythingOfType("time.Time")).Return(&domain.OutboxEntry{ID: 1, MessageID: 1}, nil)
+	messageRepo.On("RecordAttempt", mock.Anything, mock.AnythingOfType("*domain.MessageAttempt")).Return(nil)
+	messageRepo.On("Update", mock.Anything, hasStatus(domain.MessageStatusSent)).Return(nil)
+	outboxRepo.On("Delete", mock.Anything, 1).Return(nil)
 
 	// Test
 	req := &domain.SendSMSRequest{
@@ -118,33 +245,45 @@ func TestMessagingService_SendSMS(t *testing.T) {
 		Body:      "Hello! This is a test SMS message.",
 	}
 
-	err := service.SendSMS(context.Background(), req)
+	message, err := service.SendSMS(context.Background(), req)
 
 	// Assertions
 	assert.NoError(t, err)
+	sent := smsProvider.(*provider.MockSMSProvider).GetMessages()
+	assert.Len(t, sent, 1)
+	assert.Equal(t, sent[0].Result.ProviderMessageID, *message.MessagingProviderID)
+	assert.Equal(t, "mock", *message.Provider)
+	assert.Equal(t, 1, *message.SegmentCount)
+	assert.NotNil(t, message.SentAt)
 	conversationRepo.AssertExpectations(t)
 	messageRepo.AssertExpectations(t)
+	outboxRepo.AssertExpectations(t)
 }
 
 func TestMessagingService_SendMMS(t *testing.T) {
 	// Setup
 	conversationRepo := &MockConversationRepository{}
 	messageRepo := &MockMessageRepository{}
+	outboxRepo := &MockOutboxRepository{}
 	smsProvider := provider.NewMockSMSProvider()
 	emailProvider := provider.NewMockEmailProvider()
 
-	service := NewMessagingServiceWithConfig(conversationRepo, messageRepo, smsProvider, emailProvider, TestRetryConfig())
+	service := NewMessagingServiceWithConfig(conversationRepo, messageRepo, outboxRepo, noopTransactor{}, smsProvider, emailProvider, TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
 
 	// Mock expectations
-	conversationRepo.On("GetOrCreate", mock.Anything, "+12016661234", "+18045551234").Return(&domain.Conversation{
+	conversationRepo.On("RecordMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	conversationRepo.On("GetOrCreate", mock.Anything, "+18045551234", "+12016661234").Return(&domain.Conversation{
 		ID:              1,
-		CustomerContact: "+12016661234",
-		BusinessContact: "+18045551234",
+		CustomerContact: "+18045551234",
+		BusinessContact: "+12016661234",
 		CreatedAt:       time.Now().UTC(),
 		UpdatedAt:       time.Now().UTC(),
 	}, nil)
 
-	messageRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Message")).Return(nil)
+	outboxRepo.On("Enqueue", mock.Anything, mock.AnythingOfType("*domain.Message"), mock.AnythingOfType("time.Time")).Return(&domain.OutboxEntry{ID: 1, MessageID: 1}, nil)
+	messageRepo.On("RecordAttempt", mock.Anything, mock.AnythingOfType("*domain.MessageAttempt")).Return(nil)
+	messageRepo.On("Update", mock.Anything, hasStatus(domain.MessageStatusSent)).Return(nil)
+	outboxRepo.On("Delete", mock.Anything, 1).Return(nil)
 
 	// Test
 	req := &domain.SendSMSRequest{
@@ -156,24 +295,27 @@ func TestMessagingService_SendMMS(t *testing.T) {
 		Attachments: []string{"https://example.com/image.jpg"},
 	}
 
-	err := service.SendSMS(context.Background(), req)
+	_, err := service.SendSMS(context.Background(), req)
 
 	// Assertions
 	assert.NoError(t, err)
 	conversationRepo.AssertExpectations(t)
 	messageRepo.AssertExpectations(t)
+	outboxRepo.AssertExpectations(t)
 }
 
 func TestMessagingService_SendEmail(t *testing.T) {
 	// Setup
 	conversationRepo := &MockConversationRepository{}
 	messageRepo := &MockMessageRepository{}
+	outboxRepo := &MockOutboxRepository{}
 	smsProvider := provider.NewMockSMSProvider()
 	emailProvider := provider.NewMockEmailProvider()
 
-	service := NewMessagingServiceWithConfig(conversationRepo, messageRepo, smsProvider, emailProvider, TestRetryConfig())
+	service := NewMessagingServiceWithConfig(conversationRepo, messageRepo, outboxRepo, noopTransactor{}, smsProvider, emailProvider, TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
 
 	// Mock expectations
+	conversationRepo.On("RecordMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
 	conversationRepo.On("GetOrCreate", mock.Anything, "contact@gmail.com", "user@usehatchapp.com").Return(&domain.Conversation{
 		ID:              1,
 		CustomerContact: "contact@gmail.com",
@@ -182,7 +324,10 @@ func TestMessagingService_SendEmail(t *testing.T) {
 		UpdatedAt:       time.Now().UTC(),
 	}, nil)
 
-	messageRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Message")).Return(nil)
+	outboxRepo.On("Enqueue", mock.Anything, mock.AnythingOfType("*domain.Message"), mock.AnythingOfType("time.Time")).Return(&domain.OutboxEntry{ID: 1, MessageID: 1}, nil)
+	messageRepo.On("RecordAttempt", mock.Anything, mock.AnythingOfType("*domain.MessageAttempt")).Return(nil)
+	messageRepo.On("Update", mock.Anything, hasStatus(domain.MessageStatusSent)).Return(nil)
+	outboxRepo.On("Delete", mock.Anything, 1).Return(nil)
 
 	// Test
 	req := &domain.SendEmailRequest{
@@ -193,38 +338,323 @@ func TestMessagingService_SendEmail(t *testing.T) {
 		Attachments: []string{"https://example.com/document.pdf"},
 	}
 
-	err := service.SendEmail(context.Background(), req)
+	_, err := service.SendEmail(context.Background(), req)
+
+	// Assertions
+	assert.NoError(t, err)
+	conversationRepo.AssertExpectations(t)
+	messageRepo.AssertExpectations(t)
+	outboxRepo.AssertExpectations(t)
+}
+
+func TestMessagingService_EnqueueSMS(t *testing.T) {
+	// Setup
+	conversationRepo := &MockConversationRepository{}
+	messageRepo := &MockMessageRepository{}
+	outboxRepo := &MockOutboxRepository{}
+	smsProvider := provider.NewMockSMSProvider()
+	emailProvider := provider.NewMockEmailProvider()
+
+	service := NewMessagingServiceWithConfig(conversationRepo, messageRepo, outboxRepo, noopTransactor{}, smsProvider, emailProvider, TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
+
+	// Mock expectations - the entry must be immediately available to the dispatcher
+	conversationRepo.On("RecordMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	conversationRepo.On("GetOrCreate", mock.Anything, "+18045551234", "+12016661234").Return(&domain.Conversation{ID: 3}, nil)
+	outboxRepo.On("Enqueue", mock.Anything, mock.AnythingOfType("*domain.Message"), mock.MatchedBy(func(availableAt time.Time) bool {
+		return !availableAt.After(time.Now())
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.Message).ID = 11
+	}).Return(&domain.OutboxEntry{ID: 1, MessageID: 11}, nil)
+
+	// Test
+	req := &domain.SendSMSRequest{
+		Timestamp: time.Now().UTC(),
+		From:      "+12016661234",
+		To:        "+18045551234",
+		Type:      "sms",
+		Body:      "Queued SMS message",
+	}
+
+	message, err := service.EnqueueSMS(context.Background(), req)
 
 	// Assertions
 	assert.NoError(t, err)
+	assert.Equal(t, 11, message.ID)
+	assert.Equal(t, 3, message.ConversationID)
+	assert.Equal(t, domain.MessageStatusPending, message.Status)
+	assert.Equal(t, domain.MessageDirectionOutbound, message.Direction)
+	assert.Len(t, smsProvider.(*provider.MockSMSProvider).GetMessages(), 0) // Delivery is left to the dispatcher
+	conversationRepo.AssertExpectations(t)
+	outboxRepo.AssertExpectations(t)
+}
+
+func TestMessagingService_EnqueueSMS_CanonicalizesPhoneNumbers(t *testing.T) {
+	conversationRepo := &MockConversationRepository{}
+	outboxRepo := &MockOutboxRepository{}
+	service := NewMessagingServiceWithConfig(conversationRepo, &MockMessageRepository{}, outboxRepo, noopTransactor{}, provider.NewMockSMSProvider(), provider.NewMockEmailProvider(), TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
+
+	// Differently formatted numbers resolve to the same conversation
+	conversationRepo.On("RecordMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	conversationRepo.On("GetOrCreate", mock.Anything, "+12016661234", "+18045551234").Return(&domain.Conversation{ID: 3}, nil)
+	outboxRepo.On("Enqueue", mock.Anything, mock.AnythingOfType("*domain.Message"), mock.AnythingOfType("time.Time")).Return(&domain.OutboxEntry{ID: 1}, nil)
+
+	message, err := service.EnqueueSMS(context.Background(), &domain.SendSMSRequest{
+		Timestamp: time.Now().UTC(),
+		From:      "(804) 555-1234",
+		To:        "+1 201-666-1234",
+		Type:      "sms",
+		Body:      "Hello",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "+18045551234", message.From)
+	assert.Equal(t, "+12016661234", message.To)
 	conversationRepo.AssertExpectations(t)
+}
+
+func TestMessagingService_RejectsInvalidPhoneNumbers(t *testing.T) {
+	conversationRepo := &MockConversationRepository{}
+	messageRepo := &MockMessageRepository{}
+	service := NewMessagingServiceWithConfig(conversationRepo, messageRepo, &MockOutboxRepository{}, noopTransactor{}, provider.NewMockSMSProvider(), provider.NewMockEmailProvider(), TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
+
+	_, err := service.SendSMS(context.Background(), &domain.SendSMSRequest{
+		Timestamp: time.Now().UTC(),
+		From:      "+18045551234",
+		To:        "555-1234",
+		Type:      "sms",
+		Body:      "Hello",
+	})
+	assert.True(t, errors.Is(err, contact.ErrInvalidPhoneNumber))
+
+	err = service.HandleInboundSMS(context.Background(), &domain.InboundSMSWebhook{
+		Timestamp:           time.Now().UTC(),
+		From:                "not a number",
+		To:                  "+18045551234",
+		Type:                "sms",
+		MessagingProviderID: "message-1",
+		Body:                "Hello",
+	})
+	assert.True(t, errors.Is(err, contact.ErrInvalidPhoneNumber))
+
+	conversationRepo.AssertNotCalled(t, "GetOrCreate", mock.Anything, mock.Anything, mock.Anything)
+	messageRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestMessagingService_SendEmail_ParsesDisplayNames(t *testing.T) {
+	conversationRepo := &MockConversationRepository{}
+	messageRepo := &MockMessageRepository{}
+	outboxRepo := &MockOutboxRepository{}
+	emailProvider := provider.NewMockEmailProvider()
+	service := NewMessagingServiceWithConfig(conversationRepo, messageRepo, outboxRepo, noopTransactor{}, provider.NewMockSMSProvider(), emailProvider, TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
+
+	// The display name and domain casing do not split the conversation
+	conversationRepo.On("RecordMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	conversationRepo.On("GetOrCreate", mock.Anything, "contact@gmail.com", "user@usehatchapp.com").Return(&domain.Conversation{ID: 1}, nil)
+	outboxRepo.On("Enqueue", mock.Anything, mock.AnythingOfType("*domain.Message"), mock.AnythingOfType("time.Time")).Return(&domain.OutboxEntry{ID: 1, MessageID: 1}, nil)
+	messageRepo.On("RecordAttempt", mock.Anything, mock.AnythingOfType("*domain.MessageAttempt")).Return(nil)
+	messageRepo.On("Update", mock.Anything, hasStatus(domain.MessageStatusSent)).Return(nil)
+	outboxRepo.On("Delete", mock.Anything, 1).Return(nil)
+
+	message, err := service.SendEmail(context.Background(), &domain.SendEmailRequest{
+		Timestamp: time.Now().UTC(),
+		From:      "Hatch Support <user@UseHatchApp.com>",
+		To:        "contact@gmail.com",
+		Body:      "Hello",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "user@usehatchapp.com", message.From)
+	assert.Equal(t, "contact@gmail.com", message.To)
+	require.NotNil(t, message.FromName)
+	assert.Equal(t, "Hatch Support", *message.FromName)
+	assert.Nil(t, message.ToName)
+
+	// Providers receive the bare address
+	sent := emailProvider.(*provider.MockEmailProvider).GetMessages()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "user@usehatchapp.com", sent[0].From)
+	conversationRepo.AssertExpectations(t)
+}
+
+func TestMessagingService_SendEmail_DeliversToAddressAsGiven(t *testing.T) {
+	conversationRepo := &MockConversationRepository{}
+	messageRepo := &MockMessageRepository{}
+	outboxRepo := &MockOutboxRepository{}
+	emailProvider := provider.NewMockEmailProvider()
+	normalizer := contact.NewNormalizerWithConfig(contact.Config{DefaultRegion: contact.DefaultRegion, CanonicalizeEmail: true})
+	service := NewMessagingServiceWithConfig(conversationRepo, messageRepo, outboxRepo, noopTransactor{}, provider.NewMockSMSProvider(), emailProvider, TestRetryPolicy(), normalizer)
+
+	// Only the conversation is keyed by the canonical address
+	conversationRepo.On("RecordMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	conversationRepo.On("GetOrCreate", mock.Anything, "janedoe@gmail.com", "user@usehatchapp.com").Return(&domain.Conversation{ID: 1}, nil)
+	outboxRepo.On("Enqueue", mock.Anything, mock.AnythingOfType("*domain.Message"), mock.AnythingOfType("time.Time")).Return(&domain.OutboxEntry{ID: 1, MessageID: 1}, nil)
+	messageRepo.On("RecordAttempt", mock.Anything, mock.AnythingOfType("*domain.MessageAttempt")).Return(nil)
+	messageRepo.On("Update", mock.Anything, hasStatus(domain.MessageStatusSent)).Return(nil)
+	outboxRepo.On("Delete", mock.Anything, 1).Return(nil)
+
+	message, err := service.SendEmail(context.Background(), &domain.SendEmailRequest{
+		Timestamp: time.Now().UTC(),
+		From:      "user@usehatchapp.com",
+		To:        "Jane.Doe+Orders@Gmail.com",
+		Body:      "Hello",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Jane.Doe+Orders@gmail.com", message.To)
+	sent := emailProvider.(*provider.MockEmailProvider).GetMessages()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "Jane.Doe+Orders@gmail.com", sent[0].To)
+	conversationRepo.AssertExpectations(t)
+}
+
+func TestMessagingService_RejectsInvalidEmailAddresses(t *testing.T) {
+	conversationRepo := &MockConversationRepository{}
+	messageRepo := &MockMessageRepository{}
+	service := NewMessagingServiceWithConfig(conversationRepo, messageRepo, &MockOutboxRepository{}, noopTransactor{}, provider.NewMockSMSProvider(), provider.NewMockEmailProvider(), TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
+
+	_, err := service.EnqueueEmail(context.Background(), &domain.SendEmailRequest{
+		Timestamp: time.Now().UTC(),
+		From:      "user@usehatchapp.com",
+		To:        "not an email",
+		Body:      "Hello",
+	})
+	assert.True(t, errors.Is(err, contact.ErrInvalidEmailAddress))
+
+	err = service.HandleInboundEmail(context.Background(), &domain.InboundEmailWebhook{
+		Timestamp: time.Now().UTC(),
+		From:      "contact@localhost",
+		To:        "user@usehatchapp.com",
+		XillioID:  "message-1",
+		Body:      "Hello",
+	})
+	assert.True(t, errors.Is(err, contact.ErrInvalidEmailAddress))
+
+	conversationRepo.AssertNotCalled(t, "GetOrCreate", mock.Anything, mock.Anything, mock.Anything)
+	messageRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestMessagingService_SendSMS_Scheduled(t *testing.T) {
+	conversationRepo := &MockConversationRepository{}
+	messageRepo := &MockMessageRepository{}
+	outboxRepo := &MockOutboxRepository{}
+	smsProvider := provider.NewMockSMSProvider()
+
+	service := NewMessagingServiceWithConfig(conversationRepo, messageRepo, outboxRepo, noopTransactor{}, smsProvider, provider.NewMockEmailProvider(), TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
+
+	// Scheduled messages are stored without an outbox entry until the scheduler picks them up
+	sendAt := time.Now().Add(time.Hour)
+	conversationRepo.On("RecordMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	conversationRepo.On("GetOrCreate", mock.Anything, "+18045551234", "+12016661234").Return(&domain.Conversation{ID: 3}, nil)
+	messageRepo.On("Create", mock.Anything, hasStatus(domain.MessageStatusScheduled)).Return(nil)
+
+	req := &domain.SendSMSRequest{
+		Timestamp: time.Now().UTC(),
+		From:      "+12016661234",
+		To:        "+18045551234",
+		Type:      "sms",
+		Body:      "Your appointment is tomorrow",
+		SendAt:    &sendAt,
+	}
+
+	message, err := service.SendSMS(context.Background(), req)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.MessageStatusScheduled, message.Status)
+	assert.True(t, sendAt.Equal(*message.SendAt))
+	assert.Len(t, smsProvider.(*provider.MockSMSProvider).GetMessages(), 0)
 	messageRepo.AssertExpectations(t)
+	outboxRepo.AssertNotCalled(t, "Enqueue", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMessagingService_EnqueueSMS_PastSendAtQueuesImmediately(t *testing.T) {
+	conversationRepo := &MockConversationRepository{}
+	outboxRepo := &MockOutboxRepository{}
+	service := NewMessagingServiceWithConfig(conversationRepo, &MockMessageRepository{}, outboxRepo, noopTransactor{}, provider.NewMockSMSProvider(), provider.NewMockEmailProvider(), TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
+
+	sendAt := time.Now().Add(-time.Minute)
+	conversationRepo.On("RecordMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	conversationRepo.On("GetOrCreate", mock.Anything, "+18045551234", "+12016661234").Return(&domain.Conversation{ID: 3}, nil)
+	outboxRepo.On("Enqueue", mock.Anything, hasStatus(domain.MessageStatusPending), mock.AnythingOfType("time.Time")).
+		Return(&domain.OutboxEntry{ID: 1, MessageID: 11}, nil)
+
+	req := &domain.SendSMSRequest{
+		Timestamp: time.Now().UTC(),
+		From:      "+12016661234",
+		To:        "+18045551234",
+		Type:      "sms",
+		Body:      "Late reminder",
+		SendAt:    &sendAt,
+	}
+
+	message, err := service.EnqueueSMS(context.Background(), req)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.MessageStatusPending, message.Status)
+	outboxRepo.AssertExpectations(t)
+}
+
+func TestMessagingService_EnqueueEmail(t *testing.T) {
+	// Setup
+	conversationRepo := &MockConversationRepository{}
+	messageRepo := &MockMessageRepository{}
+	outboxRepo := &MockOutboxRepository{}
+	smsProvider := provider.NewMockSMSProvider()
+	emailProvider := provider.NewMockEmailProvider()
+
+	service := NewMessagingServiceWithConfig(conversationRepo, messageRepo, outboxRepo, noopTransactor{}, smsProvider, emailProvider, TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
+
+	// Mock expectations
+	conversationRepo.On("RecordMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	conversationRepo.On("GetOrCreate", mock.Anything, "contact@gmail.com", "user@usehatchapp.com").Return(&domain.Conversation{ID: 4}, nil)
+	outboxRepo.On("Enqueue", mock.Anything, mock.AnythingOfType("*domain.Message"), mock.AnythingOfType("time.Time")).Return(&domain.OutboxEntry{ID: 1}, nil)
+
+	// Test
+	req := &domain.SendEmailRequest{
+		Timestamp: time.Now().UTC(),
+		From:      "user@usehatchapp.com",
+		To:        "contact@gmail.com",
+		Body:      "Queued email message",
+	}
+
+	message, err := service.EnqueueEmail(context.Background(), req)
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.Equal(t, domain.MessageTypeEmail, message.Type)
+	assert.Equal(t, 4, message.ConversationID)
+	assert.Len(t, emailProvider.(*provider.MockEmailProvider).GetMessages(), 0)
+	outboxRepo.AssertExpectations(t)
 }
 
 func TestMessagingService_HandleInboundSMS(t *testing.T) {
 	// Setup
 	conversationRepo := &MockConversationRepository{}
 	messageRepo := &MockMessageRepository{}
+	outboxRepo := &MockOutboxRepository{}
 	smsProvider := provider.NewMockSMSProvider()
 	emailProvider := provider.NewMockEmailProvider()
 
-	service := NewMessagingServiceWithConfig(conversationRepo, messageRepo, smsProvider, emailProvider, TestRetryConfig())
+	service := NewMessagingServiceWithConfig(conversationRepo, messageRepo, outboxRepo, noopTransactor{}, smsProvider, emailProvider, TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
 
 	// Mock expectations - note the normalized order
-	conversationRepo.On("GetOrCreate", mock.Anything, "+12016661234", "+18045551234").Return(&domain.Conversation{
+	timestamp := time.Now().UTC()
+	conversationRepo.On("RecordMessage", mock.Anything, 1, timestamp, "This is an incoming SMS message", domain.MessageDirectionInbound).Return(nil)
+	conversationRepo.On("GetOrCreate", mock.Anything, "+18045551234", "+12016661234").Return(&domain.Conversation{
 		ID:              1,
-		CustomerContact: "+12016661234",
-		BusinessContact: "+18045551234",
+		CustomerContact: "+18045551234",
+		BusinessContact: "+12016661234",
 		CreatedAt:       time.Now().UTC(),
 		UpdatedAt:       time.Now().UTC(),
 	}, nil)
 
-	messageRepo.On("GetByProviderMessageID", mock.Anything, "message-1").Return(nil, nil)
-	messageRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Message")).Return(nil)
+	messageRepo.On("GetByProviderMessageID", mock.Anything, "message-1").Return(nil, domain.ErrNotFound)
+	messageRepo.On("Create", mock.Anything, mock.MatchedBy(func(message *domain.Message) bool {
+		return message.Direction == domain.MessageDirectionInbound
+	})).Return(nil)
 
 	// Test
 	webhook := &domain.InboundSMSWebhook{
-		Timestamp:           time.Now().UTC(),
+		Timestamp:           timestamp,
 		From:                "+18045551234",
 		To:                  "+12016661234",
 		Type:                "sms",
@@ -238,18 +668,45 @@ func TestMessagingService_HandleInboundSMS(t *testing.T) {
 	assert.NoError(t, err)
 	conversationRepo.AssertExpectations(t)
 	messageRepo.AssertExpectations(t)
+	outboxRepo.AssertExpectations(t)
+}
+
+func TestMessagingService_HandleInboundSMS_ConcurrentDuplicate(t *testing.T) {
+	conversationRepo := &MockConversationRepository{}
+	messageRepo := &MockMessageRepository{}
+	service := NewMessagingServiceWithConfig(conversationRepo, messageRepo, &MockOutboxRepository{}, noopTransactor{}, provider.NewMockSMSProvider(), provider.NewMockEmailProvider(), TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
+
+	// The duplicate check passes, but another delivery of the webhook stores the message first
+	conversationRepo.On("GetOrCreate", mock.Anything, "+18045551234", "+12016661234").Return(&domain.Conversation{ID: 1}, nil)
+	messageRepo.On("GetByProviderMessageID", mock.Anything, "message-1").Return(nil, domain.ErrNotFound)
+	messageRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Message")).Return(domain.ErrConflict)
+
+	err := service.HandleInboundSMS(context.Background(), &domain.InboundSMSWebhook{
+		Timestamp:           time.Now().UTC(),
+		From:                "+18045551234",
+		To:                  "+12016661234",
+		Type:                "sms",
+		MessagingProviderID: "message-1",
+		Body:                "Hello",
+	})
+
+	assert.NoError(t, err)
+	messageRepo.AssertExpectations(t)
+	conversationRepo.AssertNotCalled(t, "RecordMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 }
 
 func TestMessagingService_HandleInboundEmail(t *testing.T) {
 	// Setup
 	conversationRepo := &MockConversationRepository{}
 	messageRepo := &MockMessageRepository{}
+	outboxRepo := &MockOutboxRepository{}
 	smsProvider := provider.NewMockSMSProvider()
 	emailProvider := provider.NewMockEmailProvider()
 
-	service := NewMessagingServiceWithConfig(conversationRepo, messageRepo, smsProvider, emailProvider, TestRetryConfig())
+	service := NewMessagingServiceWithConfig(conversationRepo, messageRepo, outboxRepo, noopTransactor{}, smsProvider, emailProvider, TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
 
 	// Mock expectations
+	conversationRepo.On("RecordMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
 	conversationRepo.On("GetOrCreate", mock.Anything, "contact@gmail.com", "user@usehatchapp.com").Return(&domain.Conversation{
 		ID:              1,
 		CustomerContact: "contact@gmail.com",
@@ -258,7 +715,7 @@ func TestMessagingService_HandleInboundEmail(t *testing.T) {
 		UpdatedAt:       time.Now().UTC(),
 	}, nil)
 
-	messageRepo.On("GetByProviderMessageID", mock.Anything, "message-3").Return(nil, nil)
+	messageRepo.On("GetByProviderMessageID", mock.Anything, "message-3").Return(nil, domain.ErrNotFound)
 	messageRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Message")).Return(nil)
 
 	// Test
@@ -276,29 +733,101 @@ func TestMessagingService_HandleInboundEmail(t *testing.T) {
 	assert.NoError(t, err)
 	conversationRepo.AssertExpectations(t)
 	messageRepo.AssertExpectations(t)
+	outboxRepo.AssertExpectations(t)
+}
+
+// countingTransactor runs operations directly and records how many transactions were started and failed
+type countingTransactor struct {
+	started int
+	failed  int
+}
+
+func (t *countingTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	t.started++
+	err := fn(ctx)
+	if err != nil {
+		t.failed++
+	}
+	return err
+}
+
+func TestMessagingService_HandleInboundSMS_TruncatesConversationPreview(t *testing.T) {
+	conversationRepo := &MockConversationRepository{}
+	messageRepo := &MockMessageRepository{}
+	service := NewMessagingServiceWithConfig(conversationRepo, messageRepo, &MockOutboxRepository{}, noopTransactor{}, provider.NewMockSMSProvider(), provider.NewMockEmailProvider(), TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
+
+	body := strings.Repeat("é", maxPreviewLength+20)
+	conversationRepo.On("GetOrCreate", mock.Anything, "+18045551234", "+12016661234").Return(&domain.Conversation{ID: 1}, nil)
+	conversationRepo.On("RecordMessage", mock.Anything, 1, mock.AnythingOfType("time.Time"), strings.Repeat("é", maxPreviewLength), domain.MessageDirectionInbound).Return(nil)
+	messageRepo.On("GetByProviderMessageID", mock.Anything, "message-1").Return(nil, domain.ErrNotFound)
+	messageRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Message")).Return(nil)
+
+	err := service.HandleInboundSMS(context.Background(), &domain.InboundSMSWebhook{
+		Timestamp:           time.Now().UTC(),
+		From:                "+18045551234",
+		To:                  "+12016661234",
+		Type:                "sms",
+		MessagingProviderID: "message-1",
+		Body:                body,
+	})
+
+	assert.NoError(t, err)
+	conversationRepo.AssertExpectations(t)
+}
+
+func TestMessagingService_EnqueueSMS_RollsBackWhenConversationUpdateFails(t *testing.T) {
+	conversationRepo := &MockConversationRepository{}
+	outboxRepo := &MockOutboxRepository{}
+	transactor := &countingTransactor{}
+	service := NewMessagingServiceWithConfig(conversationRepo, &MockMessageRepository{}, outboxRepo, transactor, provider.NewMockSMSProvider(), provider.NewMockEmailProvider(), TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
+
+	conversationRepo.On("GetOrCreate", mock.Anything, "+18045551234", "+12016661234").Return(&domain.Conversation{ID: 1}, nil)
+	conversationRepo.On("RecordMessage", mock.Anything, 1, mock.AnythingOfType("time.Time"), "Hello", domain.MessageDirectionOutbound).Return(errors.New("connection lost"))
+	outboxRepo.On("Enqueue", mock.Anything, mock.AnythingOfType("*domain.Message"), mock.AnythingOfType("time.Time")).Return(&domain.OutboxEntry{ID: 1}, nil)
+
+	_, err := service.EnqueueSMS(context.Background(), &domain.SendSMSRequest{
+		Timestamp: time.Now().UTC(),
+		From:      "+12016661234",
+		To:        "+18045551234",
+		Type:      "sms",
+		Body:      "Hello",
+	})
+
+	// The whole unit of work fails, so the message and its outbox entry are rolled back
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to update conversation")
+	assert.Equal(t, 1, transactor.started)
+	assert.Equal(t, 1, transactor.failed)
+	conversationRepo.AssertExpectations(t)
+	outboxRepo.AssertExpectations(t)
 }
 
 func TestMessagingService_SendSMS_WithRetryableError(t *testing.T) {
 	// Create mocks
 	conversationRepo := &MockConversationRepository{}
 	messageRepo := &MockMessageRepository{}
+	outboxRepo := &MockOutboxRepository{}
 	smsProvider := provider.NewMockSMSProviderWithErrorCode(500) // Simulate 500 error
 	emailProvider := provider.NewMockEmailProvider()
 
-	service := NewMessagingServiceWithConfig(conversationRepo, messageRepo, smsProvider, emailProvider, TestRetryConfig())
+	service := NewMessagingServiceWithConfig(conversationRepo, messageRepo, outboxRepo, noopTransactor{}, smsProvider, emailProvider, TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
 
 	// Setup conversation mock
 	conversation := &domain.Conversation{
 		ID:              1,
-		CustomerContact: "+12016661234",
-		BusinessContact: "+18045551234",
+		CustomerContact: "+18045551234",
+		BusinessContact: "+12016661234",
 		CreatedAt:       time.Now().UTC(),
 		UpdatedAt:       time.Now().UTC(),
 	}
-	conversationRepo.On("GetOrCreate", mock.Anything, "+12016661234", "+18045551234").Return(conversation, nil)
+	conversationRepo.On("RecordMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	conversationRepo.On("GetOrCreate", mock.Anything, "+18045551234", "+12016661234").Return(conversation, nil)
 
 	// Setup message mock
-	messageRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Message")).Return(nil)
+	outboxRepo.On("Enqueue", mock.Anything, mock.AnythingOfType("*domain.Message"), mock.AnythingOfType("time.Time")).Return(&domain.OutboxEntry{ID: 1, MessageID: 1}, nil)
+	messageRepo.On("RecordAttempt", mock.Anything, mock.AnythingOfType("*domain.MessageAttempt")).Return(nil)
+	messageRepo.On("Update", mock.Anything, hasStatus(domain.MessageStatusFailed)).Return(nil)
+	outboxRepo.On("Delete", mock.Anything, 1).Return(nil)
 
 	// Create request
 	req := &domain.SendSMSRequest{
@@ -310,7 +839,7 @@ func TestMessagingService_SendSMS_WithRetryableError(t *testing.T) {
 	}
 
 	// This should fail because the provider returns a 500 error
-	err := service.SendSMS(context.Background(), req)
+	_, err := service.SendSMS(context.Background(), req)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to send message through provider")
 
@@ -318,29 +847,91 @@ func TestMessagingService_SendSMS_WithRetryableError(t *testing.T) {
 	mockProvider := smsProvider.(*provider.MockSMSProvider)
 	messages := mockProvider.GetMessages()
 	assert.Len(t, messages, 0) // No messages should be sent due to provider failure
+
+	// Every attempt is recorded against the message
+	messageRepo.AssertNumberOfCalls(t, "RecordAttempt", 4)
+	messageRepo.AssertCalled(t, "RecordAttempt", mock.Anything, mock.MatchedBy(func(attempt *domain.MessageAttempt) bool {
+		return !attempt.Succeeded && *attempt.ErrorClass == "transient" && *attempt.ErrorCode == "500"
+	}))
+
+	// The message is still persisted and marked as failed
+	messageRepo.AssertExpectations(t)
+	outboxRepo.AssertExpectations(t)
+}
+
+func TestMessagingService_SendSMS_WithOpenCircuit(t *testing.T) {
+	// Create mocks
+	conversationRepo := &MockConversationRepository{}
+	messageRepo := &MockMessageRepository{}
+	outboxRepo := &MockOutboxRepository{}
+	emailProvider := provider.NewMockEmailProvider()
+
+	// Trip the breaker so calls fail fast
+	breaker := provider.NewCircuitBreaker("sms:mock", provider.CircuitBreakerConfig{
+		Window:           time.Minute,
+		MinRequests:      1,
+		FailureRate:      0.5,
+		CoolDown:         time.Minute,
+		HalfOpenRequests: 1,
+	})
+	_ = breaker.Execute(context.Background(), func() error {
+		return &domain.ProviderError{Code: http.StatusServiceUnavailable, Message: "connection reset"}
+	})
+	mockProvider := provider.NewMockSMSProvider()
+	smsProvider := provider.NewCircuitBreakerSMSProvider(mockProvider, breaker)
+
+	service := NewMessagingServiceWithConfig(conversationRepo, messageRepo, outboxRepo, noopTransactor{}, smsProvider, emailProvider, TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
+
+	conversation := &domain.Conversation{ID: 1, CustomerContact: "+18045551234", BusinessContact: "+12016661234"}
+	conversationRepo.On("RecordMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	conversationRepo.On("GetOrCreate", mock.Anything, "+18045551234", "+12016661234").Return(conversation, nil)
+	outboxRepo.On("Enqueue", mock.Anything, mock.AnythingOfType("*domain.Message"), mock.AnythingOfType("time.Time")).Return(&domain.OutboxEntry{ID: 1, MessageID: 1}, nil)
+	messageRepo.On("RecordAttempt", mock.Anything, mock.AnythingOfType("*domain.MessageAttempt")).Return(nil)
+
+	req := &domain.SendSMSRequest{
+		Timestamp: time.Now().UTC(),
+		From:      "+12016661234",
+		To:        "+18045551234",
+		Type:      "sms",
+		Body:      "Test message",
+	}
+
+	message, err := service.SendSMS(context.Background(), req)
+
+	// The message stays pending with its outbox entry so the dispatcher retries it later
+	assert.ErrorIs(t, err, domain.ErrCircuitOpen)
+	assert.Equal(t, domain.MessageStatusPending, message.Status)
+	assert.Empty(t, mockProvider.(*provider.MockSMSProvider).GetMessages())
+	messageRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	outboxRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
 }
 
 func TestMessagingService_SendSMS_WithRateLimitError(t *testing.T) {
 	// Create mocks
 	conversationRepo := &MockConversationRepository{}
 	messageRepo := &MockMessageRepository{}
+	outboxRepo := &MockOutboxRepository{}
 	smsProvider := provider.NewMockSMSProviderWithErrorCode(429) // Simulate 429 error
 	emailProvider := provider.NewMockEmailProvider()
 
-	service := NewMessagingServiceWithConfig(conversationRepo, messageRepo, smsProvider, emailProvider, TestRetryConfig())
+	service := NewMessagingServiceWithConfig(conversationRepo, messageRepo, outboxRepo, noopTransactor{}, smsProvider, emailProvider, TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
 
 	// Setup conversation mock
 	conversation := &domain.Conversation{
 		ID:              1,
-		CustomerContact: "+12016661234",
-		BusinessContact: "+18045551234",
+		CustomerContact: "+18045551234",
+		BusinessContact: "+12016661234",
 		CreatedAt:       time.Now().UTC(),
 		UpdatedAt:       time.Now().UTC(),
 	}
-	conversationRepo.On("GetOrCreate", mock.Anything, "+12016661234", "+18045551234").Return(conversation, nil)
+	conversationRepo.On("RecordMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	conversationRepo.On("GetOrCreate", mock.Anything, "+18045551234", "+12016661234").Return(conversation, nil)
 
 	// Setup message mock
-	messageRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Message")).Return(nil)
+	outboxRepo.On("Enqueue", mock.Anything, mock.AnythingOfType("*domain.Message"), mock.AnythingOfType("time.Time")).Return(&domain.OutboxEntry{ID: 1, MessageID: 1}, nil)
+	messageRepo.On("RecordAttempt", mock.Anything, mock.AnythingOfType("*domain.MessageAttempt")).Return(nil)
+	messageRepo.On("Update", mock.Anything, hasStatus(domain.MessageStatusFailed)).Return(nil)
+	outboxRepo.On("Delete", mock.Anything, 1).Return(nil)
 
 	// Create request
 	req := &domain.SendSMSRequest{
@@ -352,7 +943,7 @@ func TestMessagingService_SendSMS_WithRateLimitError(t *testing.T) {
 	}
 
 	// This should fail because the provider returns a 429 error
-	err := service.SendSMS(context.Background(), req)
+	_, err := service.SendSMS(context.Background(), req)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to send message through provider")
 
@@ -360,29 +951,38 @@ func TestMessagingService_SendSMS_WithRateLimitError(t *testing.T) {
 	mockProvider := smsProvider.(*provider.MockSMSProvider)
 	messages := mockProvider.GetMessages()
 	assert.Len(t, messages, 0) // No messages should be sent due to provider failure
+
+	// The message is still persisted and marked as failed
+	messageRepo.AssertExpectations(t)
+	outboxRepo.AssertExpectations(t)
 }
 
 func TestMessagingService_SendEmail_WithRetryableError(t *testing.T) {
 	// Create mocks
 	conversationRepo := &MockConversationRepository{}
 	messageRepo := &MockMessageRepository{}
+	outboxRepo := &MockOutboxRepository{}
 	smsProvider := provider.NewMockSMSProvider()
 	emailProvider := provider.NewMockEmailProviderWithErrorCode(500) // Simulate 500 error
 
-	service := NewMessagingServiceWithConfig(conversationRepo, messageRepo, smsProvider, emailProvider, TestRetryConfig())
+	service := NewMessagingServiceWithConfig(conversationRepo, messageRepo, outboxRepo, noopTransactor{}, smsProvider, emailProvider, TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
 
 	// Setup conversation mock
 	conversation := &domain.Conversation{
 		ID:              1,
-		CustomerContact: "user@usehatchapp.com",
-		BusinessContact: "contact@gmail.com",
+		CustomerContact: "contact@gmail.com",
+		BusinessContact: "user@usehatchapp.com",
 		CreatedAt:       time.Now().UTC(),
 		UpdatedAt:       time.Now().UTC(),
 	}
-	conversationRepo.On("GetOrCreate", mock.Anything, "user@usehatchapp.com", "contact@gmail.com").Return(conversation, nil)
+	conversationRepo.On("RecordMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	conversationRepo.On("GetOrCreate", mock.Anything, "contact@gmail.com", "user@usehatchapp.com").Return(conversation, nil)
 
 	// Setup message mock
-	messageRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Message")).Return(nil)
+	outboxRepo.On("Enqueue", mock.Anything, mock.AnythingOfType("*domain.Message"), mock.AnythingOfType("time.Time")).Return(&domain.OutboxEntry{ID: 1, MessageID: 1}, nil)
+	messageRepo.On("RecordAttempt", mock.Anything, mock.AnythingOfType("*domain.MessageAttempt")).Return(nil)
+	messageRepo.On("Update", mock.Anything, hasStatus(domain.MessageStatusFailed)).Return(nil)
+	outboxRepo.On("Delete", mock.Anything, 1).Return(nil)
 
 	// Create request
 	req := &domain.SendEmailRequest{
@@ -394,7 +994,7 @@ func TestMessagingService_SendEmail_WithRetryableError(t *testing.T) {
 	}
 
 	// This should fail because the provider returns a 500 error
-	err := service.SendEmail(context.Background(), req)
+	_, err := service.SendEmail(context.Background(), req)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to send email through provider")
 
@@ -402,29 +1002,38 @@ func TestMessagingService_SendEmail_WithRetryableError(t *testing.T) {
 	mockProvider := emailProvider.(*provider.MockEmailProvider)
 	messages := mockProvider.GetMessages()
 	assert.Len(t, messages, 0) // No messages should be sent due to provider failure
+
+	// The message is still persisted and marked as failed
+	messageRepo.AssertExpectations(t)
+	outboxRepo.AssertExpectations(t)
 }
 
 func TestMessagingService_SendEmail_WithRateLimitError(t *testing.T) {
 	// Create mocks
 	conversationRepo := &MockConversationRepository{}
 	messageRepo := &MockMessageRepository{}
+	outboxRepo := &MockOutboxRepository{}
 	smsProvider := provider.NewMockSMSProvider()
 	emailProvider := provider.NewMockEmailProviderWithErrorCode(429) // Simulate 429 error
 
-	service := NewMessagingServiceWithConfig(conversationRepo, messageRepo, smsProvider, emailProvider, TestRetryConfig())
+	service := NewMessagingServiceWithConfig(conversationRepo, messageRepo, outboxRepo, noopTransactor{}, smsProvider, emailProvider, TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
 
 	// Setup conversation mock
 	conversation := &domain.Conversation{
 		ID:              1,
-		CustomerContact: "user@usehatchapp.com",
-		BusinessContact: "contact@gmail.com",
+		CustomerContact: "contact@gmail.com",
+		BusinessContact: "user@usehatchapp.com",
 		CreatedAt:       time.Now().UTC(),
 		UpdatedAt:       time.Now().UTC(),
 	}
-	conversationRepo.On("GetOrCreate", mock.Anything, "user@usehatchapp.com", "contact@gmail.com").Return(conversation, nil)
+	conversationRepo.On("RecordMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	conversationRepo.On("GetOrCreate", mock.Anything, "contact@gmail.com", "user@usehatchapp.com").Return(conversation, nil)
 
 	// Setup message mock
-	messageRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Message")).Return(nil)
+	outboxRepo.On("Enqueue", mock.Anything, mock.AnythingOfType("*domain.Message"), mock.AnythingOfType("time.Time")).Return(&domain.OutboxEntry{ID: 1, MessageID: 1}, nil)
+	messageRepo.On("RecordAttempt", mock.Anything, mock.AnythingOfType("*domain.MessageAttempt")).Return(nil)
+	messageRepo.On("Update", mock.Anything, hasStatus(domain.MessageStatusFailed)).Return(nil)
+	outboxRepo.On("Delete", mock.Anything, 1).Return(nil)
 
 	// Create request
 	req := &domain.SendEmailRequest{
@@ -436,7 +1045,7 @@ func TestMessagingService_SendEmail_WithRateLimitError(t *testing.T) {
 	}
 
 	// This should fail because the provider returns a 429 error
-	err := service.SendEmail(context.Background(), req)
+	_, err := service.SendEmail(context.Background(), req)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to send email through provider")
 
@@ -444,16 +1053,324 @@ func TestMessagingService_SendEmail_WithRateLimitError(t *testing.T) {
 	mockProvider := emailProvider.(*provider.MockEmailProvider)
 	messages := mockProvider.GetMessages()
 	assert.Len(t, messages, 0) // No messages should be sent due to provider failure
+
+	// The message is still persisted and marked as failed
+	messageRepo.AssertExpectations(t)
+	outboxRepo.AssertExpectations(t)
+}
+
+func TestMessagingService_GetMessage(t *testing.T) {
+	// Setup
+	messageRepo := &MockMessageRepository{}
+	service := NewMessagingServiceWithConfig(&MockConversationRepository{}, messageRepo, &MockOutboxRepository{}, noopTransactor{}, provider.NewMockSMSProvider(), provider.NewMockEmailProvider(), TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
+
+	errorCode := "500"
+	messageRepo.On("GetByID", mock.Anything, 5).Return(&domain.Message{ID: 5, Status: domain.MessageStatusFailed, ErrorCode: &errorCode}, nil)
+	messageRepo.On("GetByID", mock.Anything, 6).Return(nil, domain.ErrNotFound)
+
+	// Existing message
+	message, err := service.GetMessage(context.Background(), 5)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.MessageStatusFailed, message.Status)
+	assert.Equal(t, "500", *message.ErrorCode)
+
+	// Missing message
+	_, err = service.GetMessage(context.Background(), 6)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestMessagingService_GetMessageEvents(t *testing.T) {
+	// Setup
+	messageRepo := &MockMessageRepository{}
+	service := NewMessagingServiceWithConfig(&MockConversationRepository{}, messageRepo, &MockOutboxRepository{}, noopTransactor{}, provider.NewMockSMSProvider(), provider.NewMockEmailProvider(), TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
+
+	events := []domain.MessageEvent{
+		{ID: 1, MessageID: 5, Status: domain.MessageStatusPending},
+		{ID: 2, MessageID: 5, Status: domain.MessageStatusSent},
+	}
+	messageRepo.On("GetByID", mock.Anything, 5).Return(&domain.Message{ID: 5, Status: domain.MessageStatusSent}, nil)
+	messageRepo.On("GetEvents", mock.Anything, 5).Return(events, nil)
+	messageRepo.On("GetByID", mock.Anything, 6).Return(nil, domain.ErrNotFound)
+
+	// Existing message
+	result, err := service.GetMessageEvents(context.Background(), 5)
+	assert.NoError(t, err)
+	assert.Equal(t, events, result)
+
+	// Missing message
+	_, err = service.GetMessageEvents(context.Background(), 6)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	messageRepo.AssertNotCalled(t, "GetEvents", mock.Anything, 6)
+}
+
+func TestMessagingService_GetMessageAttempts(t *testing.T) {
+	messageRepo := &MockMessageRepository{}
+	service := NewMessagingServiceWithConfig(&MockConversationRepository{}, messageRepo, &MockOutboxRepository{}, noopTransactor{}, provider.NewMockSMSProvider(), provider.NewMockEmailProvider(), TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
+
+	errorClass := "transient"
+	attempts := []domain.MessageAttempt{
+		{ID: 1, MessageID: 5, AttemptNumber: 1, ErrorClass: &errorClass},
+		{ID: 2, MessageID: 5, AttemptNumber: 2, Succeeded: true},
+	}
+	messageRepo.On("GetByID", mock.Anything, 5).Return(&domain.Message{ID: 5}, nil)
+	messageRepo.On("GetAttempts", mock.Anything, 5).Return(attempts, nil)
+	messageRepo.On("GetByID", mock.Anything, 6).Return(nil, domain.ErrNotFound)
+
+	// Existing message
+	result, err := service.GetMessageAttempts(context.Background(), 5)
+	assert.NoError(t, err)
+	assert.Equal(t, attempts, result)
+
+	// Missing message
+	_, err = service.GetMessageAttempts(context.Background(), 6)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	messageRepo.AssertNotCalled(t, "GetAttempts", mock.Anything, 6)
+}
+
+func TestMessagingService_ListDeadLetters(t *testing.T) {
+	messageRepo := &MockMessageRepository{}
+	service := NewMessagingServiceWithConfig(&MockConversationRepository{}, messageRepo, &MockOutboxRepository{}, noopTransactor{}, provider.NewMockSMSProvider(), provider.NewMockEmailProvider(), TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
+
+	failed := []domain.Message{{ID: 5, Status: domain.MessageStatusFailed}}
+	query := &domain.DeadLetterQuery{Provider: "twilio", Limit: 1, Offset: -1}
+	messageRepo.On("ListFailed", mock.Anything, query).Return(failed, 3, nil)
+
+	response, err := service.ListDeadLetters(context.Background(), query)
+	require.NoError(t, err)
+	assert.Equal(t, failed, response.Messages)
+	assert.Equal(t, 3, response.Total)
+	assert.Equal(t, 1, response.Page)
+	assert.Equal(t, 1, response.PerPage)
+	assert.True(t, response.HasMore)
+	assert.Zero(t, query.Offset)
+}
+
+func TestMessagingService_ReplayMessage(t *testing.T) {
+	messageRepo := &MockMessageRepository{}
+	outboxRepo := &MockOutboxRepository{}
+	service := NewMessagingServiceWithConfig(&MockConversationRepository{}, messageRepo, outboxRepo, noopTransactor{}, provider.NewMockSMSProvider(), provider.NewMockEmailProvider(), TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
+
+	errorCode := "400"
+	messageRepo.On("GetByID", mock.Anything, 5).Return(&domain.Message{ID: 5, Status: domain.MessageStatusFailed, ErrorCode: &errorCode}, nil)
+	outboxRepo.On("Requeue", mock.Anything, mock.AnythingOfType("*domain.Message"), mock.AnythingOfType("time.Time")).
+		Run(func(args mock.Arguments) {
+			message := args.Get(1).(*domain.Message)
+			message.Status = domain.MessageStatusPending
+			message.ErrorCode = nil
+		}).
+		Return(&domain.OutboxEntry{ID: 1, MessageID: 5}, nil)
+
+	message, err := service.ReplayMessage(context.Background(), 5)
+	require.NoError(t, err)
+	assert.Equal(t, domain.MessageStatusPending, message.Status)
+	assert.Nil(t, message.ErrorCode)
+	outboxRepo.AssertExpectations(t)
+}
+
+func TestMessagingService_ReplayMessage_RejectsMessagesThatAreNotFailed(t *testing.T) {
+	messageRepo := &MockMessageRepository{}
+	outboxRepo := &MockOutboxRepository{}
+	service := NewMessagingServiceWithConfig(&MockConversationRepository{}, messageRepo, outboxRepo, noopTransactor{}, provider.NewMockSMSProvider(), provider.NewMockEmailProvider(), TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
+
+	messageRepo.On("GetByID", mock.Anything, 5).Return(&domain.Message{ID: 5, Status: domain.MessageStatusDelivered}, nil)
+	messageRepo.On("GetByID", mock.Anything, 6).Return(&domain.Message{ID: 6, Status: domain.MessageStatusFailed}, nil)
+	messageRepo.On("GetByID", mock.Anything, 7).Return(nil, domain.ErrNotFound)
+	// Message 6 is replayed concurrently, so the conditional requeue finds nothing to reset
+	outboxRepo.On("Requeue", mock.Anything, mock.AnythingOfType("*domain.Message"), mock.AnythingOfType("time.Time")).Return(nil, domain.ErrConflict)
+
+	_, err := service.ReplayMessage(context.Background(), 5)
+	assert.ErrorIs(t, err, domain.ErrInvalidState)
+
+	_, err = service.ReplayMessage(context.Background(), 6)
+	assert.ErrorIs(t, err, domain.ErrConflict)
+
+	_, err = service.ReplayMessage(context.Background(), 7)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	outboxRepo.AssertNumberOfCalls(t, "Requeue", 1)
+}
+
+func scheduledSMS(id int) *domain.Message {
+	sendAt := time.Now().Add(time.Hour).UTC()
+	return &domain.Message{ID: id, Type: domain.MessageTypeSMS, Status: domain.MessageStatusScheduled, SendAt: &sendAt}
+}
+
+func TestMessagingService_CancelMessage(t *testing.T) {
+	messageRepo := &MockMessageRepository{}
+	service := NewMessagingServiceWithConfig(&MockConversationRepository{}, messageRepo, &MockOutboxRepository{}, noopTransactor{}, provider.NewMockSMSProvider(), provider.NewMockEmailProvider(), TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
+
+	messageRepo.On("GetByID", mock.Anything, 5).Return(scheduledSMS(5), nil)
+	messageRepo.On("UpdateIfStatus", mock.Anything, hasStatus(domain.MessageStatusCancelled), domain.MessageStatusScheduled).Return(true, nil)
+
+	message, err := service.CancelMessage(context.Background(), 5)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.MessageStatusCancelled, message.Status)
+	messageRepo.AssertExpectations(t)
+}
+
+func TestMessagingService_CancelMessage_RejectsMessagesThatAreNotScheduled(t *testing.T) {
+	messageRepo := &MockMessageRepository{}
+	service := NewMessagingServiceWithConfig(&MockConversationRepository{}, messageRepo, &MockOutboxRepository{}, noopTransactor{}, provider.NewMockSMSProvider(), provider.NewMockEmailProvider(), TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
+
+	messageRepo.On("GetByID", mock.Anything, 5).Return(&domain.Message{ID: 5, Status: domain.MessageStatusSent}, nil)
+	messageRepo.On("GetByID", mock.Anything, 6).Return(scheduledSMS(6), nil)
+	// Message 6 is handed to the outbox between the read and the update
+	messageRepo.On("UpdateIfStatus", mock.Anything, mock.AnythingOfType("*domain.Message"), domain.MessageStatusScheduled).Return(false, nil)
+
+	_, err := service.CancelMessage(context.Background(), 5)
+	assert.ErrorIs(t, err, domain.ErrInvalidState)
+
+	_, err = service.CancelMessage(context.Background(), 6)
+	assert.ErrorIs(t, err, domain.ErrInvalidState)
+
+	messageRepo.AssertNumberOfCalls(t, "UpdateIfStatus", 1)
+}
+
+func TestMessagingService_RescheduleMessage(t *testing.T) {
+	messageRepo := &MockMessageRepository{}
+	service := NewMessagingServiceWithConfig(&MockConversationRepository{}, messageRepo, &MockOutboxRepository{}, noopTransactor{}, provider.NewMockSMSProvider(), provider.NewMockEmailProvider(), TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
+
+	sendAt := time.Now().Add(2 * time.Hour).UTC()
+	messageRepo.On("GetByID", mock.Anything, 5).Return(scheduledSMS(5), nil)
+	messageRepo.On("Reschedule", mock.Anything, 5, sendAt).Return(true, nil)
+
+	message, err := service.RescheduleMessage(context.Background(), 5, sendAt)
+	require.NoError(t, err)
+	assert.Equal(t, sendAt, *message.SendAt)
+
+	// Send times in the past are rejected
+	_, err = service.RescheduleMessage(context.Background(), 5, time.Now().Add(-time.Minute))
+	assert.Error(t, err)
+	messageRepo.AssertNumberOfCalls(t, "Reschedule", 1)
+}
+
+func TestMessagingService_HandleSMSStatus(t *testing.T) {
+	// Setup
+	messageRepo := &MockMessageRepository{}
+	service := NewMessagingServiceWithConfig(&MockConversationRepository{}, messageRepo, &MockOutboxRepository{}, noopTransactor{}, provider.NewMockSMSProvider(), provider.NewMockEmailProvider(), TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
+
+	messageRepo.On("GetByProviderMessageID", mock.Anything, "sms-1").Return(&domain.Message{ID: 1, Status: domain.MessageStatusSent}, nil)
+	messageRepo.On("UpdateIfStatus", mock.Anything, hasStatus(domain.MessageStatusDelivered), domain.MessageStatusSent).Return(true, nil)
+
+	// Execute
+	err := service.HandleSMSStatus(context.Background(), &domain.MessageStatusWebhook{
+		MessagingProviderID: "sms-1",
+		Status:              domain.MessageStatusDelivered,
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	messageRepo.AssertExpectations(t)
+}
+
+func TestMessagingService_HandleSMSStatus_IgnoresRegression(t *testing.T) {
+	// Setup
+	messageRepo := &MockMessageRepository{}
+	service := NewMessagingServiceWithConfig(&MockConversationRepository{}, messageRepo, &MockOutboxRepository{}, noopTransactor{}, provider.NewMockSMSProvider(), provider.NewMockEmailProvider(), TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
+
+	messageRepo.On("GetByProviderMessageID", mock.Anything, "sms-1").Return(&domain.Message{ID: 1, Status: domain.MessageStatusDelivered}, nil)
+
+	// Execute - a late "sent" receipt must not overwrite "delivered"
+	err := service.HandleSMSStatus(context.Background(), &domain.MessageStatusWebhook{
+		MessagingProviderID: "sms-1",
+		Status:              domain.MessageStatusSent,
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	messageRepo.AssertNotCalled(t, "UpdateIfStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMessagingService_HandleSMSStatus_IgnoresInboundMessages(t *testing.T) {
+	messageRepo := &MockMessageRepository{}
+	service := NewMessagingServiceWithConfig(&MockConversationRepository{}, messageRepo, &MockOutboxRepository{}, noopTransactor{}, provider.NewMockSMSProvider(), provider.NewMockEmailProvider(), TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
+
+	messageRepo.On("GetByProviderMessageID", mock.Anything, "sms-1").Return(&domain.Message{ID: 1, Direction: domain.MessageDirectionInbound, Status: domain.MessageStatusDelivered}, nil)
+
+	// A receipt carrying the provider ID of a received message must not change it
+	err := service.HandleSMSStatus(context.Background(), &domain.MessageStatusWebhook{
+		MessagingProviderID: "sms-1",
+		Status:              domain.MessageStatusFailed,
+	})
+
+	assert.NoError(t, err)
+	messageRepo.AssertNotCalled(t, "UpdateIfStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMessagingService_HandleSMSStatus_RetriesConcurrentUpdate(t *testing.T) {
+	// Setup
+	messageRepo := &MockMessageRepository{}
+	service := NewMessagingServiceWithConfig(&MockConversationRepository{}, messageRepo, &MockOutboxRepository{}, noopTransactor{}, provider.NewMockSMSProvider(), provider.NewMockEmailProvider(), TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
+
+	messageRepo.On("GetByProviderMessageID", mock.Anything, "sms-1").Return(&domain.Message{ID: 1, Status: domain.MessageStatusPending}, nil).Once()
+	messageRepo.On("UpdateIfStatus", mock.Anything, hasStatus(domain.MessageStatusFailed), domain.MessageStatusPending).Return(false, nil).Once()
+	messageRepo.On("GetByProviderMessageID", mock.Anything, "sms-1").Return(&domain.Message{ID: 1, Status: domain.MessageStatusSent}, nil).Once()
+	messageRepo.On("UpdateIfStatus", mock.Anything, hasStatus(domain.MessageStatusFailed), domain.MessageStatusSent).Return(true, nil).Once()
+
+	// Execute
+	err := service.HandleSMSStatus(context.Background(), &domain.MessageStatusWebhook{
+		MessagingProviderID: "sms-1",
+		Status:              domain.MessageStatusFailed,
+		ErrorCode:           "30003",
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	messageRepo.AssertExpectations(t)
+}
+
+func TestMessagingService_HandleEmailStatus_Bounce(t *testing.T) {
+	// Setup
+	messageRepo := &MockMessageRepository{}
+	service := NewMessagingServiceWithConfig(&MockConversationRepository{}, messageRepo, &MockOutboxRepository{}, noopTransactor{}, provider.NewMockSMSProvider(), provider.NewMockEmailProvider(), TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
+
+	messageRepo.On("GetByProviderMessageID", mock.Anything, "email-1").Return(&domain.Message{ID: 2, Status: domain.MessageStatusDelivered}, nil)
+	messageRepo.On("UpdateIfStatus", mock.Anything, mock.MatchedBy(func(message *domain.Message) bool {
+		return message.Status == domain.MessageStatusBounced &&
+			message.ErrorCode != nil && *message.ErrorCode == "550" &&
+			message.ErrorMessage != nil && *message.ErrorMessage == "mailbox unavailable"
+	}), domain.MessageStatusDelivered).Return(true, nil)
+
+	// Execute
+	err := service.HandleEmailStatus(context.Background(), &domain.EmailStatusWebhook{
+		XillioID:     "email-1",
+		Status:       domain.MessageStatusBounced,
+		ErrorCode:    "550",
+		ErrorMessage: "mailbox unavailable",
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	messageRepo.AssertExpectations(t)
+}
+
+func TestMessagingService_HandleEmailStatus_UnknownMessage(t *testing.T) {
+	// Setup
+	messageRepo := &MockMessageRepository{}
+	service := NewMessagingServiceWithConfig(&MockConversationRepository{}, messageRepo, &MockOutboxRepository{}, noopTransactor{}, provider.NewMockSMSProvider(), provider.NewMockEmailProvider(), TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
+
+	messageRepo.On("GetByProviderMessageID", mock.Anything, "unknown").Return(nil, domain.ErrNotFound)
+
+	// Execute
+	err := service.HandleEmailStatus(context.Background(), &domain.EmailStatusWebhook{
+		XillioID: "unknown",
+		Status:   domain.MessageStatusDelivered,
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrNotFound)
 }
 
 func TestMessagingService_ValidateTimestamp(t *testing.T) {
 	// Setup
 	conversationRepo := &MockConversationRepository{}
 	messageRepo := &MockMessageRepository{}
+	outboxRepo := &MockOutboxRepository{}
 	smsProvider := provider.NewMockSMSProvider()
 	emailProvider := provider.NewMockEmailProvider()
 
-	service := NewMessagingServiceWithConfig(conversationRepo, messageRepo, smsProvider, emailProvider, TestRetryConfig())
+	service := NewMessagingServiceWithConfig(conversationRepo, messageRepo, outboxRepo, noopTransactor{}, smsProvider, emailProvider, TestRetryPolicy(), contact.NewNormalizer(contact.DefaultRegion))
 
 	// Test cases
 	testCases := []struct {
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"messaging-service/internal/domain"
+
+	"go.uber.org/zap"
+)
+
+// OutboxDispatcherConfig holds outbox dispatcher configuration
+type OutboxDispatcherConfig struct {
+	PollInterval time.Duration `json:"poll_interval"`
+	BatchSize    int           `json:"batch_size"`
+	MaxAttempts  int           `json:"max_attempts"`
+	RetryDelay   time.Duration `json:"retry_delay"`
+}
+
+// DefaultOutboxDispatcherConfig returns default outbox dispatcher configuration
+func DefaultOutboxDispatcherConfig() OutboxDispatcherConfig {
+	return OutboxDispatcherConfig{
+		PollInterval: time.Second,
+		BatchSize:    10,
+		MaxAttempts:  5,
+		RetryDelay:   30 * time.Second,
+	}
+}
+
+// OutboxDispatcher drains the outbox in the background and delivers pending messages
+type OutboxDispatcher struct {
+	outboxRepo       domain.OutboxRepository
+	messageRepo      domain.MessageRepository
+	messagingService domain.MessagingService
+	config           OutboxDispatcherConfig
+	logger           *zap.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewOutboxDispatcher creates a new outbox dispatcher
+func NewOutboxDispatcher(
+	outboxRepo domain.OutboxRepository,
+	messageRepo domain.MessageRepository,
+	messagingService domain.MessagingService,
+	config OutboxDispatcherConfig,
+	logger *zap.Logger,
+) *OutboxDispatcher {
+	return &OutboxDispatcher{
+		outboxRepo:       outboxRepo,
+		messageRepo:      messageRepo,
+		messagingService: messagingService,
+		config:           config,
+		logger:           logger,
+	}
+}
+
+// Start begins polling the outbox until Stop is called or ctx is cancelled
+func (d *OutboxDispatcher) Start(ctx context.Context) {
+	ctx, d.cancel = context.WithCancel(ctx)
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+
+		ticker := time.NewTicker(d.config.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := d.DispatchDue(ctx); err != nil && ctx.Err() == nil {
+					d.logger.Error("Failed to dispatch outbox entries", zap.Error(err))
+				}
+			}
+		}
+	}()
+
+	d.logger.Info("Outbox dispatcher started", zap.Duration("poll_interval", d.config.PollInterval))
+}
+
+// Stop stops polling and waits for in-flight deliveries to finish
+func (d *OutboxDispatcher) Stop() {
+	if d.cancel == nil {
+		return
+	}
+	d.cancel()
+	d.wg.Wait()
+	d.logger.Info("Outbox dispatcher stopped")
+}
+
+// DispatchDue claims one batch of due outbox entries and delivers them, returning the number processed
+func (d *OutboxDispatcher) DispatchDue(ctx context.Context) (int, error) {
+	entries, err := d.outboxRepo.ClaimDue(ctx, d.config.BatchSize, deliveryLease)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim outbox entries: %w", err)
+	}
+
+	for i := range entries {
+		d.dispatchEntry(ctx, &entries[i])
+	}
+
+	return len(entries), nil
+}
+
+// dispatchEntry delivers the message behind a claimed entry and resolves the entry
+func (d *OutboxDispatcher) dispatchEntry(ctx context.Context, entry *domain.OutboxEntry) {
+	logger := d.logger.With(zap.Int("outbox_id", entry.ID), zap.Int("message_id", entry.MessageID))
+
+	message, err := d.messageRepo.GetByID(ctx, entry.MessageID)
+	if errors.Is(err, domain.ErrNotFound) {
+		// The message no longer exists, so there is nothing left to deliver
+		d.delete(ctx, entry)
+		return
+	}
+	if err != nil {
+		d.release(ctx, entry, err)
+		return
+	}
+
+	// Give up on messages that keep failing to reach a recorded outcome
+	if entry.Attempts > d.config.MaxAttempts && message.Status == domain.MessageStatusPending {
+		errorCode := "delivery_attempts_exhausted"
+		errorMessage := fmt.Sprintf("delivery not completed after %d attempts", d.config.MaxAttempts)
+		message.Status = domain.MessageStatusFailed
+		message.ErrorCode = &errorCode
+		message.ErrorMessage = &errorMessage
+		if err := d.messageRepo.Update(ctx, message); err != nil {
+			d.release(ctx, entry, err)
+			return
+		}
+		logger.Warn("Outbox entry exceeded max attempts", zap.Int("attempts", entry.Attempts))
+		d.delete(ctx, entry)
+		return
+	}
+
+	if err := d.messagingService.DeliverMessage(ctx, message); err != nil {
+		if message.Status == domain.MessageStatusPending {
+			d.release(ctx, entry, err)
+			return
+		}
+		logger.Warn("Outbound message failed", zap.Error(err))
+	}
+
+	d.delete(ctx, entry)
+}
+
+// release schedules the entry for another attempt after the retry delay
+func (d *OutboxDispatcher) release(ctx context.Context, entry *domain.OutboxEntry, cause error) {
+	// During shutdown the lease simply expires and the entry is retried after restart
+	if ctx.Err() != nil {
+		return
+	}
+
+	d.logger.Warn("Outbox delivery will be retried",
+		zap.Int("outbox_id", entry.ID),
+		zap.Int("message_id", entry.MessageID),
+		zap.Int("attempts", entry.Attempts),
+		zap.Error(cause),
+	)
+
+	// Entries that cannot be released stay leased and become due again when the lease expires
+	if err := d.outboxRepo.Release(ctx, entry.ID, time.Now().Add(d.config.RetryDelay), cause.Error()); err != nil {
+		d.logger.Error("Failed to release outbox entry", zap.Int("outbox_id", entry.ID), zap.Error(err))
+	}
+}
+
+// delete removes a resolved entry from the outbox
+func (d *OutboxDispatcher) delete(ctx context.Context, entry *domain.OutboxEntry) {
+	if err := d.outboxRepo.Delete(ctx, entry.ID); err != nil {
+		d.logger.Error("Failed to delete outbox entry", zap.Int("outbox_id", entry.ID), zap.Error(err))
+	}
+}
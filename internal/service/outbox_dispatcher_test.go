@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"messaging-service/internal/contact"
+	"messaging-service/internal/domain"
+	"messaging-service/internal/provider"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func newTestOutboxDispatcher(outboxRepo *MockOutboxRepository, messageRepo *MockMessageRepository, smsProvider domain.SMSProvider) *OutboxDispatcher {
+	messageRepo.On("RecordAttempt", mock.Anything, mock.AnythingOfType("*domain.MessageAttempt")).Return(nil).Maybe()
+
+	messagingService := NewMessagingServiceWithConfig(
+		&MockConversationRepository{},
+		messageRepo,
+		outboxRepo,
+		noopTransactor{},
+		smsProvider,
+		provider.NewMockEmailProvider(),
+		TestRetryPolicy(),
+		contact.NewNormalizer(contact.DefaultRegion),
+	)
+
+	return NewOutboxDispatcher(outboxRepo, messageRepo, messagingService, DefaultOutboxDispatcherConfig(), zap.NewNop())
+}
+
+func pendingSMS(id int) *domain.Message {
+	return &domain.Message{
+		ID:             id,
+		ConversationID: 1,
+		From:           "+12016661234",
+		To:             "+18045551234",
+		Type:           domain.MessageTypeSMS,
+		Body:           "Queued message",
+		Status:         domain.MessageStatusPending,
+		Timestamp:      time.Now().UTC(),
+	}
+}
+
+func TestOutboxDispatcher_DispatchDue_DeliversPendingMessage(t *testing.T) {
+	outboxRepo := &MockOutboxRepository{}
+	messageRepo := &MockMessageRepository{}
+	smsProvider := provider.NewMockSMSProvider()
+	dispatcher := newTestOutboxDispatcher(outboxRepo, messageRepo, smsProvider)
+
+	outboxRepo.On("ClaimDue", mock.Anything, 10, deliveryLease).Return([]domain.OutboxEntry{{ID: 7, MessageID: 42, Attempts: 1}}, nil)
+	messageRepo.On("GetByID", mock.Anything, 42).Return(pendingSMS(42), nil)
+	messageRepo.On("Update", mock.Anything, hasStatus(domain.MessageStatusSent)).Return(nil)
+	outboxRepo.On("Delete", mock.Anything, 7).Return(nil)
+
+	processed, err := dispatcher.DispatchDue(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	assert.Len(t, smsProvider.(*provider.MockSMSProvider).GetMessages(), 1)
+	outboxRepo.AssertExpectations(t)
+	messageRepo.AssertExpectations(t)
+}
+
+func TestOutboxDispatcher_DispatchDue_RecordsProviderFailure(t *testing.T) {
+	outboxRepo := &MockOutboxRepository{}
+	messageRepo := &MockMessageRepository{}
+	dispatcher := newTestOutboxDispatcher(outboxRepo, messageRepo, provider.NewMockSMSProviderWithErrorCode(500))
+
+	outboxRepo.On("ClaimDue", mock.Anything, 10, deliveryLease).Return([]domain.OutboxEntry{{ID: 7, MessageID: 42, Attempts: 1}}, nil)
+	messageRepo.On("GetByID", mock.Anything, 42).Return(pendingSMS(42), nil)
+	messageRepo.On("Update", mock.Anything, mock.MatchedBy(func(message *domain.Message) bool {
+		return message.Status == domain.MessageStatusFailed && message.ErrorCode != nil && *message.ErrorCode == "500"
+	})).Return(nil)
+	outboxRepo.On("Delete", mock.Anything, 7).Return(nil)
+
+	_, err := dispatcher.DispatchDue(context.Background())
+
+	assert.NoError(t, err)
+	outboxRepo.AssertExpectations(t)
+	messageRepo.AssertExpectations(t)
+}
+
+func TestOutboxDispatcher_DispatchDue_SkipsResolvedMessage(t *testing.T) {
+	outboxRepo := &MockOutboxRepository{}
+	messageRepo := &MockMessageRepository{}
+	smsProvider := provider.NewMockSMSProvider()
+	dispatcher := newTestOutboxDispatcher(outboxRepo, messageRepo, smsProvider)
+
+	sent := pendingSMS(42)
+	sent.Status = domain.MessageStatusSent
+
+	outboxRepo.On("ClaimDue", mock.Anything, 10, deliveryLease).Return([]domain.OutboxEntry{{ID: 7, MessageID: 42, Attempts: 2}}, nil)
+	messageRepo.On("GetByID", mock.Anything, 42).Return(sent, nil)
+	outboxRepo.On("Delete", mock.Anything, 7).Return(nil)
+
+	_, err := dispatcher.DispatchDue(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, smsProvider.(*provider.MockSMSProvider).GetMessages(), 0)
+	messageRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	outboxRepo.AssertExpectations(t)
+}
+
+func TestOutboxDispatcher_DispatchDue_FailsAfterMaxAttempts(t *testing.T) {
+	outboxRepo := &MockOutboxRepository{}
+	messageRepo := &MockMessageRepository{}
+	smsProvider := provider.NewMockSMSProvider()
+	dispatcher := newTestOutboxDispatcher(outboxRepo, messageRepo, smsProvider)
+
+	outboxRepo.On("ClaimDue", mock.Anything, 10, deliveryLease).Return([]domain.OutboxEntry{{ID: 7, MessageID: 42, Attempts: 6}}, nil)
+	messageRepo.On("GetByID", mock.Anything, 42).Return(pendingSMS(42), nil)
+	messageRepo.On("Update", mock.Anything, hasStatus(domain.MessageStatusFailed)).Return(nil)
+	outboxRepo.On("Delete", mock.Anything, 7).Return(nil)
+
+	_, err := dispatcher.DispatchDue(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, smsProvider.(*provider.MockSMSProvider).GetMessages(), 0)
+	outboxRepo.AssertExpectations(t)
+	messageRepo.AssertExpectations(t)
+}
+
+func TestOutboxDispatcher_DispatchDue_ReleasesEntryOnStorageError(t *testing.T) {
+	outboxRepo := &MockOutboxRepository{}
+	messageRepo := &MockMessageRepository{}
+	dispatcher := newTestOutboxDispatcher(outboxRepo, messageRepo, provider.NewMockSMSProvider())
+
+	outboxRepo.On("ClaimDue", mock.Anything, 10, deliveryLease).Return([]domain.OutboxEntry{{ID: 7, MessageID: 42, Attempts: 1}}, nil)
+	messageRepo.On("GetByID", mock.Anything, 42).Return(pendingSMS(42), nil)
+	messageRepo.On("Update", mock.Anything, hasStatus(domain.MessageStatusSent)).Return(errors.New("connection reset"))
+	outboxRepo.On("Release", mock.Anything, 7, mock.AnythingOfType("time.Time"), mock.AnythingOfType("string")).Return(nil)
+
+	_, err := dispatcher.DispatchDue(context.Background())
+
+	assert.NoError(t, err)
+	outboxRepo.AssertExpectations(t)
+	outboxRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"messaging-service/internal/domain"
+
+	"go.uber.org/zap"
+)
+
+// SchedulerConfig holds scheduler configuration
+type SchedulerConfig struct {
+	PollInterval time.Duration `json:"poll_interval"`
+	BatchSize    int           `json:"batch_size"`
+}
+
+// DefaultSchedulerConfig returns default scheduler configuration
+func DefaultSchedulerConfig() SchedulerConfig {
+	return SchedulerConfig{
+		PollInterval: time.Second,
+		BatchSize:    100,
+	}
+}
+
+// Scheduler hands scheduled messages to the outbox once their send time has passed.
+// The outbox dispatcher then delivers them like any other queued message.
+type Scheduler struct {
+	outboxRepo domain.OutboxRepository
+	config     SchedulerConfig
+	logger     *zap.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewScheduler creates a new scheduler
+func NewScheduler(outboxRepo domain.OutboxRepository, config SchedulerConfig, logger *zap.Logger) *Scheduler {
+	return &Scheduler{
+		outboxRepo: outboxRepo,
+		config:     config,
+		logger:     logger,
+	}
+}
+
+// Start begins polling for due messages until Stop is called or ctx is cancelled
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.config.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.EnqueueDue(ctx); err != nil && ctx.Err() == nil {
+					s.logger.Error("Failed to enqueue scheduled messages", zap.Error(err))
+				}
+			}
+		}
+	}()
+
+	s.logger.Info("Scheduler started", zap.Duration("poll_interval", s.config.PollInterval))
+}
+
+// Stop stops polling and waits for the current poll to finish
+func (s *Scheduler) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	s.wg.Wait()
+	s.logger.Info("Scheduler stopped")
+}
+
+// EnqueueDue moves due scheduled messages to the outbox in batches until none are left,
+// returning the number moved
+func (s *Scheduler) EnqueueDue(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		count, err := s.outboxRepo.EnqueueScheduled(ctx, s.config.BatchSize)
+		if err != nil {
+			return total, fmt.Errorf("failed to enqueue scheduled messages: %w", err)
+		}
+		total += count
+
+		if count < s.config.BatchSize || ctx.Err() != nil {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestScheduler_EnqueueDue_DrainsFullBatches(t *testing.T) {
+	outboxRepo := &MockOutboxRepository{}
+	scheduler := NewScheduler(outboxRepo, SchedulerConfig{BatchSize: 2}, zap.NewNop())
+
+	outboxRepo.On("EnqueueScheduled", mock.Anything, 2).Return(2, nil).Twice()
+	outboxRepo.On("EnqueueScheduled", mock.Anything, 2).Return(1, nil).Once()
+
+	count, err := scheduler.EnqueueDue(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5, count)
+	outboxRepo.AssertExpectations(t)
+}
+
+func TestScheduler_EnqueueDue_ReturnsStorageError(t *testing.T) {
+	outboxRepo := &MockOutboxRepository{}
+	scheduler := NewScheduler(outboxRepo, DefaultSchedulerConfig(), zap.NewNop())
+
+	outboxRepo.On("EnqueueScheduled", mock.Anything, 100).Return(0, errors.New("connection refused"))
+
+	_, err := scheduler.EnqueueDue(context.Background())
+
+	assert.Error(t, err)
+}
@@ -5,15 +5,20 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
+	"messaging-service/internal/contact"
 	"messaging-service/internal/domain"
 	"messaging-service/internal/handler"
 	"messaging-service/internal/logger"
 	"messaging-service/internal/middleware"
+	"messaging-service/internal/migrate"
 	"messaging-service/internal/provider"
 	"messaging-service/internal/repository/postgres"
 	"messaging-service/internal/service"
@@ -22,14 +27,18 @@ import (
 	_ "github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 )
 
 type IntegrationTestSuite struct {
 	db                  *sql.DB
 	conversationRepo    domain.ConversationRepository
 	messageRepo         domain.MessageRepository
+	outboxRepo          domain.OutboxRepository
+	contactRepo         domain.ContactRepository
 	messagingService    domain.MessagingService
 	conversationService domain.ConversationService
+	contactService      domain.ContactService
 	handler             *handler.MessagingHandler
 	router              *gin.Engine
 }
@@ -43,26 +52,43 @@ func setupIntegrationTest(t *testing.T) *IntegrationTestSuite {
 	err = db.Ping()
 	require.NoError(t, err)
 
+	// Bring the schema up to date
+	migrations, err := postgres.Migrations()
+	require.NoError(t, err)
+	_, err = migrate.NewMigrator(db, migrate.Postgres, migrations, zap.NewNop()).Up(context.Background())
+	require.NoError(t, err)
+
 	// Clear test data
 	_, err = db.Exec("DELETE FROM messages")
 	require.NoError(t, err)
 	_, err = db.Exec("DELETE FROM conversations")
 	require.NoError(t, err)
+	_, err = db.Exec("DELETE FROM idempotency_keys")
+	require.NoError(t, err)
+	_, err = db.Exec("DELETE FROM contacts")
+	require.NoError(t, err)
 
 	// Initialize repositories
 	conversationRepo := postgres.NewConversationRepository(db)
 	messageRepo := postgres.NewMessageRepository(db)
+	outboxRepo := postgres.NewOutboxRepository(db)
+	idempotencyRepo := postgres.NewIdempotencyRepository(db)
+	contactRepo := postgres.NewContactRepository(db)
 
 	// Initialize providers
 	smsProvider := provider.NewMockSMSProvider()
 	emailProvider := provider.NewMockEmailProvider()
 
 	// Initialize services
-	messagingService := service.NewMessagingService(conversationRepo, messageRepo, smsProvider, emailProvider)
+	messagingService := service.NewMessagingService(conversationRepo, messageRepo, outboxRepo, postgres.NewTransactor(db), smsProvider, emailProvider)
 	conversationService := service.NewConversationService(conversationRepo, messageRepo)
+	contactService := service.NewContactService(contactRepo, conversationRepo, messageRepo, postgres.NewTransactor(db), contact.NewNormalizer(contact.DefaultRegion))
 
-	// Initialize handler
+	// Initialize handlers
 	messagingHandler := handler.NewMessagingHandler(messagingService, conversationService)
+	contactHandler := handler.NewContactHandler(contactService)
+
+	idempotency := middleware.IdempotencyMiddleware(idempotencyRepo, time.Hour, time.Minute)
 
 	// Setup router
 	gin.SetMode(gin.TestMode)
@@ -78,14 +104,21 @@ func setupIntegrationTest(t *testing.T) *IntegrationTestSuite {
 	{
 		messages := api.Group("/messages")
 		{
-			messages.POST("/message", messagingHandler.SendSMS)
-			messages.POST("/email", messagingHandler.SendEmail)
+			messages.POST("/message", idempotency, messagingHandler.SendSMS)
+			messages.POST("/email", idempotency, messagingHandler.SendEmail)
+			messages.GET("/:id", messagingHandler.GetMessage)
+			messages.DELETE("/:id", messagingHandler.CancelMessage)
+			messages.PATCH("/:id", messagingHandler.RescheduleMessage)
+			messages.GET("/:id/events", messagingHandler.GetMessageEvents)
+			messages.GET("/:id/attempts", messagingHandler.GetMessageAttempts)
 		}
 
 		webhooks := api.Group("/webhooks")
 		{
 			webhooks.POST("/message", messagingHandler.HandleInboundSMS)
 			webhooks.POST("/email", messagingHandler.HandleInboundEmail)
+			webhooks.POST("/message/status", messagingHandler.HandleSMSStatus)
+			webhooks.POST("/email/status", messagingHandler.HandleEmailStatus)
 		}
 
 		conversations := api.Group("/conversations")
@@ -93,14 +126,33 @@ func setupIntegrationTest(t *testing.T) *IntegrationTestSuite {
 			conversations.GET("", messagingHandler.GetConversations)
 			conversations.GET("/:id/messages", messagingHandler.GetConversationMessages)
 		}
+
+		contacts := api.Group("/contacts")
+		{
+			contacts.POST("", contactHandler.CreateContact)
+			contacts.GET("", contactHandler.FindContact)
+			contacts.GET("/:id", contactHandler.GetContact)
+			contacts.POST("/:id/merge", contactHandler.MergeContacts)
+			contacts.POST("/:id/split", contactHandler.SplitContact)
+			contacts.GET("/:id/timeline", contactHandler.GetContactTimeline)
+		}
+
+		admin := api.Group("/admin")
+		{
+			admin.GET("/dead-letters", messagingHandler.ListDeadLetters)
+			admin.POST("/dead-letters/replay", messagingHandler.ReplayDeadLetters)
+		}
 	}
 
 	return &IntegrationTestSuite{
 		db:                  db,
 		conversationRepo:    conversationRepo,
 		messageRepo:         messageRepo,
+		outboxRepo:          outboxRepo,
+		contactRepo:         contactRepo,
 		messagingService:    messagingService,
 		conversationService: conversationService,
+		contactService:      contactService,
 		handler:             messagingHandler,
 		router:              router,
 	}
@@ -123,7 +175,7 @@ func TestIntegration_SendSMSAndRetrieveConversation(t *testing.T) {
 		Body:      "First message",
 	}
 
-	err := suite.messagingService.SendSMS(context.Background(), &smsRequest)
+	_, err := suite.messagingService.SendSMS(context.Background(), &smsRequest)
 	assert.NoError(t, err)
 
 	// Send another message to create a conversation
@@ -135,7 +187,7 @@ func TestIntegration_SendSMSAndRetrieveConversation(t *testing.T) {
 		Body:      "Second message",
 	}
 
-	err = suite.messagingService.SendSMS(context.Background(), &smsRequest2)
+	_, err = suite.messagingService.SendSMS(context.Background(), &smsRequest2)
 	assert.NoError(t, err)
 
 	// Get conversations
@@ -147,8 +199,8 @@ func TestIntegration_SendSMSAndRetrieveConversation(t *testing.T) {
 	assert.Len(t, conversations.Conversations, 1)
 
 	conversation := conversations.Conversations[0]
-	assert.Equal(t, "+12016661234", conversation.CustomerContact)
-	assert.Equal(t, "+18045551234", conversation.BusinessContact)
+	assert.Equal(t, "+18045551234", conversation.CustomerContact)
+	assert.Equal(t, "+12016661234", conversation.BusinessContact)
 	assert.Len(t, conversation.Messages, 2)
 
 	// Verify messages
@@ -176,7 +228,7 @@ func TestIntegration_SendEmailAndRetrieveConversation(t *testing.T) {
 		Attachments: []string{"https://example.com/document.pdf"},
 	}
 
-	err := suite.messagingService.SendEmail(context.Background(), &emailRequest)
+	_, err := suite.messagingService.SendEmail(context.Background(), &emailRequest)
 	assert.NoError(t, err)
 
 	// Get conversations
@@ -214,7 +266,7 @@ func TestIntegration_HandleInboundSMSWebhook(t *testing.T) {
 		Body:      "Hello! This is a test SMS message.",
 	}
 
-	err := suite.messagingService.SendSMS(context.Background(), &smsRequest)
+	_, err := suite.messagingService.SendSMS(context.Background(), &smsRequest)
 	assert.NoError(t, err)
 
 	// Handle inbound SMS webhook
@@ -270,7 +322,7 @@ func TestIntegration_HandleInboundEmailWebhook(t *testing.T) {
 		Attachments: []string{"https://example.com/document.pdf"},
 	}
 
-	err := suite.messagingService.SendEmail(context.Background(), &emailRequest)
+	_, err := suite.messagingService.SendEmail(context.Background(), &emailRequest)
 	assert.NoError(t, err)
 
 	// Handle inbound email webhook
@@ -366,6 +418,157 @@ func TestIntegration_HTTPEndpoints(t *testing.T) {
 	assert.Contains(t, response, "conversations")
 }
 
+func TestIntegration_ContactTimeline(t *testing.T) {
+	suite := setupIntegrationTest(t)
+	defer suite.cleanup()
+
+	ctx := context.Background()
+	base := time.Now().UTC().Add(-time.Hour)
+
+	// The customer texts first and follows up by email
+	require.NoError(t, suite.messagingService.HandleInboundSMS(ctx, &domain.InboundSMSWebhook{
+		Timestamp: base, From: "+18045551234", To: "+12016661234", Type: "sms", MessagingProviderID: "message-1", Body: "Texting you",
+	}))
+	require.NoError(t, suite.messagingService.HandleInboundEmail(ctx, &domain.InboundEmailWebhook{
+		Timestamp: base.Add(time.Minute), From: "contact@gmail.com", To: "user@usehatchapp.com", XillioID: "email-1", Body: "Emailing you",
+	}))
+
+	serve := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		var reader *bytes.Buffer
+		if body != nil {
+			jsonData, _ := json.Marshal(body)
+			reader = bytes.NewBuffer(jsonData)
+		} else {
+			reader = &bytes.Buffer{}
+		}
+		req, _ := http.NewRequest(method, path, reader)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("POST", "/api/contacts", domain.CreateContactRequest{Phones: []string{"(804) 555-1234"}, Emails: []string{"Contact <contact@gmail.com>"}})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created domain.Contact
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, []string{"+18045551234", "contact@gmail.com"}, created.Addresses())
+
+	// Both channels appear in one timeline in timestamp order
+	w = serve("GET", fmt.Sprintf("/api/contacts/%d/timeline", created.ID), nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var timeline domain.GetContactTimelineResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &timeline))
+	require.Len(t, timeline.Messages, 2)
+	assert.Equal(t, "Texting you", timeline.Messages[0].Body)
+	assert.Equal(t, "Emailing you", timeline.Messages[1].Body)
+
+	w = serve("GET", fmt.Sprintf("/api/contacts/%d/timeline?limit=1&offset=1", created.ID), nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &timeline))
+	require.Len(t, timeline.Messages, 1)
+	assert.Equal(t, "Emailing you", timeline.Messages[0].Body)
+	assert.Equal(t, 2, timeline.Total)
+
+	// Splitting off the email address moves its messages to the new contact's timeline
+	w = serve("POST", fmt.Sprintf("/api/contacts/%d/split", created.ID), domain.SplitContactRequest{Addresses: []string{"contact@gmail.com"}})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var split domain.Contact
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &split))
+
+	timelineResponse, err := suite.contactService.GetTimeline(ctx, created.ID, &domain.ContactTimelineQuery{Limit: 50})
+	require.NoError(t, err)
+	require.Len(t, timelineResponse.Messages, 1)
+	assert.Equal(t, domain.MessageTypeSMS, timelineResponse.Messages[0].Type)
+
+	// Merging brings them back together
+	w = serve("POST", fmt.Sprintf("/api/contacts/%d/merge", created.ID), domain.MergeContactsRequest{ContactID: split.ID})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	timelineResponse, err = suite.contactService.GetTimeline(ctx, created.ID, &domain.ContactTimelineQuery{Limit: 50})
+	require.NoError(t, err)
+	assert.Len(t, timelineResponse.Messages, 2)
+
+	w = serve("GET", fmt.Sprintf("/api/contacts/%d", split.ID), nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = serve("GET", "/api/contacts?address=contact@gmail.com", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestIntegration_AsyncSendAndDispatch(t *testing.T) {
+	suite := setupIntegrationTest(t)
+	defer suite.cleanup()
+
+	// Request asynchronous delivery
+	smsRequest := domain.SendSMSRequest{
+		Timestamp: time.Now().UTC(),
+		From:      "+12016661234",
+		To:        "+18045551234",
+		Type:      "sms",
+		Body:      "Async test SMS",
+	}
+
+	jsonData, _ := json.Marshal(smsRequest)
+	req, _ := http.NewRequest("POST", "/api/messages/message", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Prefer", "respond-async")
+
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusAccepted, w.Code)
+
+	var response domain.SendSMSResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.NotZero(t, response.MessageID)
+	assert.NotZero(t, response.ConversationID)
+	assert.Equal(t, domain.MessageStatusPending, response.Status)
+	assert.Equal(t, fmt.Sprintf("/api/messages/%d", response.MessageID), response.StatusURL)
+
+	// The dispatcher delivers the queued message
+	dispatcher := service.NewOutboxDispatcher(suite.outboxRepo, suite.messageRepo, suite.messagingService, service.DefaultOutboxDispatcherConfig(), logger.Get())
+	processed, err := dispatcher.DispatchDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+
+	message, err := suite.messageRepo.GetByID(context.Background(), response.MessageID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.MessageStatusSent, message.Status)
+	require.NotNil(t, message.MessagingProviderID)
+	assert.NotEmpty(t, *message.MessagingProviderID)
+	assert.NotNil(t, message.SentAt)
+
+	// The status URL exposes the delivered message
+	req, _ = http.NewRequest("GET", response.StatusURL, nil)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var polled domain.Message
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &polled))
+	assert.Equal(t, domain.MessageStatusSent, polled.Status)
+
+	// The event history records every status transition
+	req, _ = http.NewRequest("GET", response.StatusURL+"/events", nil)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var history domain.GetMessageEventsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+	require.Len(t, history.Events, 2)
+	assert.Equal(t, domain.MessageStatusPending, history.Events[0].Status)
+	assert.Equal(t, domain.MessageStatusSent, history.Events[1].Status)
+
+	// Unknown messages return 404
+	req, _ = http.NewRequest("GET", "/api/messages/999999", nil)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
 func TestIntegration_ConversationGrouping(t *testing.T) {
 	suite := setupIntegrationTest(t)
 	defer suite.cleanup()
@@ -382,7 +585,7 @@ func TestIntegration_ConversationGrouping(t *testing.T) {
 		Type:      "sms",
 		Body:      "First message",
 	}
-	err := suite.messagingService.SendSMS(context.Background(), &smsRequest)
+	_, err := suite.messagingService.SendSMS(context.Background(), &smsRequest)
 	assert.NoError(t, err)
 
 	// Send email
@@ -392,7 +595,7 @@ func TestIntegration_ConversationGrouping(t *testing.T) {
 		To:        participant1,
 		Body:      "Reply via email",
 	}
-	err = suite.messagingService.SendEmail(context.Background(), &emailRequest)
+	_, err = suite.messagingService.SendEmail(context.Background(), &emailRequest)
 	assert.NoError(t, err)
 
 	// Verify only one conversation exists with both messages
@@ -409,3 +612,215 @@ func TestIntegration_ConversationGrouping(t *testing.T) {
 	// Verify messages are in chronological order
 	assert.True(t, conversation.Messages[0].Timestamp.Before(conversation.Messages[1].Timestamp))
 }
+
+func TestIntegration_ConversationGroupingAcrossPhoneFormats(t *testing.T) {
+	suite := setupIntegrationTest(t)
+	defer suite.cleanup()
+
+	// The same numbers written differently belong to one conversation
+	for _, numbers := range [][2]string{
+		{"+12016661234", "+18045551234"},
+		{"(201) 666-1234", "804-555-1234"},
+		{"+1 201 666 1234", "18045551234"},
+	} {
+		_, err := suite.messagingService.SendSMS(context.Background(), &domain.SendSMSRequest{
+			Timestamp: time.Now().UTC(),
+			From:      numbers[0],
+			To:        numbers[1],
+			Type:      "sms",
+			Body:      "Hello",
+		})
+		require.NoError(t, err)
+	}
+
+	conversations, err := suite.conversationService.GetConversations(context.Background(), &domain.ConversationQuery{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, conversations.Conversations, 1)
+	assert.Equal(t, "+18045551234", conversations.Conversations[0].CustomerContact)
+	assert.Equal(t, "+12016661234", conversations.Conversations[0].BusinessContact)
+}
+
+func TestIntegration_EmailDisplayNames(t *testing.T) {
+	suite := setupIntegrationTest(t)
+	defer suite.cleanup()
+
+	// Display names and domain casing do not split the conversation
+	for _, from := range []string{"user@usehatchapp.com", "Hatch Support <user@UseHatchApp.com>"} {
+		_, err := suite.messagingService.SendEmail(context.Background(), &domain.SendEmailRequest{
+			Timestamp: time.Now().UTC(),
+			From:      from,
+			To:        "contact@gmail.com",
+			Body:      "Hello",
+		})
+		require.NoError(t, err)
+	}
+
+	conversations, err := suite.conversationService.GetConversations(context.Background(), &domain.ConversationQuery{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, conversations.Conversations, 1)
+
+	messages, err := suite.messageRepo.GetByConversationID(context.Background(), conversations.Conversations[0].ID)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Nil(t, messages[0].FromName)
+	require.NotNil(t, messages[1].FromName)
+	assert.Equal(t, "Hatch Support", *messages[1].FromName)
+	assert.Equal(t, "user@usehatchapp.com", messages[1].From)
+}
+
+func TestIntegration_ConversationGetOrCreateConcurrently(t *testing.T) {
+	suite := setupIntegrationTest(t)
+	defer suite.cleanup()
+
+	const workers = 50
+	ids := make(chan int, workers)
+	errs := make(chan error, workers)
+
+	// Every worker races to create the conversation for the same pair
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			conversation, err := suite.conversationRepo.GetOrCreate(context.Background(), "+12016661234", "+18045551234")
+			if err != nil {
+				errs <- err
+				return
+			}
+			ids <- conversation.ID
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(ids)
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("GetOrCreate failed: %v", err)
+	}
+
+	var first int
+	for id := range ids {
+		if first == 0 {
+			first = id
+		}
+		assert.Equal(t, first, id)
+	}
+
+	var count int
+	require.NoError(t, suite.db.QueryRow("SELECT COUNT(*) FROM conversations").Scan(&count))
+	assert.Equal(t, 1, count)
+}
+
+func TestIntegration_ConversationRepositoryTypedErrors(t *testing.T) {
+	suite := setupIntegrationTest(t)
+	defer suite.cleanup()
+
+	conversation, err := suite.conversationRepo.Create(context.Background(), "+12016661234", "+18045551234")
+	require.NoError(t, err)
+
+	_, err = suite.conversationRepo.Create(context.Background(), "+12016661234", "+18045551234")
+	assert.ErrorIs(t, err, domain.ErrConflict)
+
+	_, err = suite.conversationRepo.GetByID(context.Background(), conversation.ID+1)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	_, err = suite.messageRepo.GetByID(context.Background(), 1<<30)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestIntegration_ConversationRecordsLastMessage(t *testing.T) {
+	suite := setupIntegrationTest(t)
+	defer suite.cleanup()
+
+	older := time.Now().UTC().Add(-time.Hour)
+	newer := time.Now().UTC()
+
+	for _, webhook := range []domain.InboundSMSWebhook{
+		{Timestamp: newer, From: "+18045551234", To: "+12016661234", Type: "sms", MessagingProviderID: "message-new", Body: "Newest message"},
+		{Timestamp: older, From: "+18045551234", To: "+12016661234", Type: "sms", MessagingProviderID: "message-old", Body: "Late arrival"},
+	} {
+		require.NoError(t, suite.messagingService.HandleInboundSMS(context.Background(), &webhook))
+	}
+
+	conversation, err := suite.conversationRepo.GetByContacts(context.Background(), "+18045551234", "+12016661234")
+	require.NoError(t, err)
+
+	// An out-of-order message does not replace the preview of a later one
+	require.NotNil(t, conversation.LastMessageAt)
+	assert.WithinDuration(t, newer, *conversation.LastMessageAt, time.Millisecond)
+	assert.Equal(t, "Newest message", *conversation.LastMessagePreview)
+	assert.Equal(t, domain.MessageDirectionInbound, *conversation.LastMessageDirection)
+	assert.True(t, conversation.UpdatedAt.After(conversation.CreatedAt))
+
+	// The customer wrote last, so the conversation awaits a reply
+	awaiting, total, err := suite.conversationRepo.List(context.Background(), &domain.ConversationQuery{AwaitingReply: true, Limit: 10, SortBy: "id", SortOrder: "asc"})
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	assert.Equal(t, conversation.ID, awaiting[0].ID)
+}
+
+func TestIntegration_TransactorRollsBackAllRepositories(t *testing.T) {
+	suite := setupIntegrationTest(t)
+	defer suite.cleanup()
+
+	transactor := postgres.NewTransactor(suite.db)
+	errAbort := errors.New("abort")
+
+	err := transactor.WithinTransaction(context.Background(), func(ctx context.Context) error {
+		conversation, err := suite.conversationRepo.GetOrCreate(ctx, "+12016661234", "+18045551234")
+		require.NoError(t, err)
+
+		message := &domain.Message{
+			ConversationID: conversation.ID,
+			From:           "+18045551234",
+			To:             "+12016661234",
+			Type:           domain.MessageTypeSMS,
+			Body:           "Rolled back",
+			Status:         domain.MessageStatusDelivered,
+			Timestamp:      time.Now().UTC(),
+			CreatedAt:      time.Now(),
+			UpdatedAt:      time.Now(),
+		}
+		require.NoError(t, suite.messageRepo.Create(ctx, message))
+		return errAbort
+	})
+	assert.ErrorIs(t, err, errAbort)
+
+	// Neither the conversation nor the message survive the rollback
+	_, err = suite.conversationRepo.GetByContacts(context.Background(), "+12016661234", "+18045551234")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestIntegration_MigrationsRevertAndReapply(t *testing.T) {
+	suite := setupIntegrationTest(t)
+	defer suite.cleanup()
+
+	migrations, err := postgres.Migrations()
+	require.NoError(t, err)
+	migrator := migrate.NewMigrator(suite.db, migrate.Postgres, migrations, zap.NewNop())
+
+	// Every migration can be reverted
+	for _, migration := range migrations {
+		assert.NotEmpty(t, migration.Down, "migration %d_%s has no down migration", migration.Version, migration.Name)
+	}
+
+	reverted, err := migrator.Down(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, reverted)
+
+	statuses, err := migrator.Status(context.Background())
+	require.NoError(t, err)
+	assert.Nil(t, statuses[len(statuses)-1].AppliedAt)
+
+	applied, err := migrator.Up(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, applied)
+
+	// Running again is a no-op
+	applied, err = migrator.Up(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, applied)
+}
@@ -0,0 +1,21 @@
+package tests
+
+import (
+	"testing"
+
+	"messaging-service/internal/repository/repositorytest"
+)
+
+func TestIntegration_PostgresRepositoryConformance(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Repositories {
+		// Every test starts from empty tables
+		suite := setupIntegrationTest(t)
+		t.Cleanup(suite.cleanup)
+
+		return repositorytest.Repositories{
+			Conversations: suite.conversationRepo,
+			Messages:      suite.messageRepo,
+			Contacts:      suite.contactRepo,
+		}
+	})
+}